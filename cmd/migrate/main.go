@@ -14,7 +14,9 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	// Timestamps are stored in UTC without a time zone; the session zone
+	// makes column defaults and NOW() agree with the times the app writes
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable timezone=UTC",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // cohort timezones must load on images without zoneinfo
 
 	"blazperic/radionica/config"
 	"blazperic/radionica/internal/api"
+	"blazperic/radionica/internal/storage"
 	"blazperic/radionica/internal/utils"
 
 	_ "blazperic/radionica/docs"
@@ -16,6 +25,10 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish after
+// a shutdown signal
+const shutdownTimeout = 15 * time.Second
+
 // @title Radionica API
 // @version 1.0
 // @description This is the API for the Radionica application.
@@ -33,7 +46,9 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	// Timestamps are stored in UTC without a time zone; the session zone
+	// makes column defaults and NOW() agree with the times the app writes
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable timezone=UTC",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -51,14 +66,42 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
-	server := api.NewServer(db, cfg)
+	store, err := storage.NewFromConfig(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	server := api.NewServer(db, cfg, store)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	jobs := server.StartBackgroundJobs(jobsCtx)
+
 	router := api.SetupRouter(server, cfg.JWTSecret)
 
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	log.Println("Server starting on :8080...")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	httpServer := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		log.Println("Server starting on :8080...")
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown failed:", err)
 	}
+
+	// Background jobs stop once requests are done, so the view tracker's last
+	// flush includes every view counted
+	cancelJobs()
+	jobs.Wait()
 }
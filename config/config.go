@@ -3,20 +3,47 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBHost               string
-	DBPort               string
-	DBUser               string
-	DBPassword           string
-	DBName               string
-	JWTSecret            string
-	TokenDuration        time.Duration
-	RefreshTokenDuration time.Duration
+	DBHost                  string
+	DBPort                  string
+	DBUser                  string
+	DBPassword              string
+	DBName                  string
+	JWTSecret               string
+	TokenDuration           time.Duration
+	RefreshTokenDuration    time.Duration
+	NewsSchedulerInterval   time.Duration
+	PublicURL               string
+	StorageDriver           string
+	StorageLocalDir         string
+	S3Endpoint              string
+	S3Region                string
+	S3Bucket                string
+	S3AccessKey             string
+	S3SecretKey             string
+	S3UsePathStyle          bool
+	UploadMaxSize           int64
+	AttachmentMaxSize       int64
+	UploadSigningKey        string
+	UploadURLExpiry         time.Duration
+	ImageProcessingInterval time.Duration
+	FrontendURL             string
+	FeedTitle               string
+	NewsViewWindow          time.Duration
+	NewsViewFlushInterval   time.Duration
+	TrashRetention          time.Duration
+	TrashPurgeInterval      time.Duration
+	DefaultLocale           string
+	SupportedLocales        []string
+	ProgramWeeks            int
+	DefaultTimezone         string
 }
 
 func LoadConfig() *Config {
@@ -26,14 +53,39 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		DBHost:               getEnv("DB_HOST", "localhost"),
-		DBPort:               getEnv("DB_PORT", "5432"),
-		DBUser:               getEnv("DB_USER", "postgres"),
-		DBPassword:           getEnv("DB_PASSWORD", "yourpassword"),
-		DBName:               getEnv("DB_NAME", "mydb"),
-		JWTSecret:            getEnv("JWT_SECRET", "your-secret-key"),
-		TokenDuration:        getEnvDuration("TOKEN_DURATION", 15*time.Minute),
-		RefreshTokenDuration: getEnvDuration("REFRESH_TOKEN_DURATION", 7*24*time.Hour),
+		DBHost:                  getEnv("DB_HOST", "localhost"),
+		DBPort:                  getEnv("DB_PORT", "5432"),
+		DBUser:                  getEnv("DB_USER", "postgres"),
+		DBPassword:              getEnv("DB_PASSWORD", "yourpassword"),
+		DBName:                  getEnv("DB_NAME", "mydb"),
+		JWTSecret:               getEnv("JWT_SECRET", "your-secret-key"),
+		TokenDuration:           getEnvDuration("TOKEN_DURATION", 15*time.Minute),
+		RefreshTokenDuration:    getEnvDuration("REFRESH_TOKEN_DURATION", 7*24*time.Hour),
+		NewsSchedulerInterval:   getEnvDuration("NEWS_SCHEDULER_INTERVAL", time.Minute),
+		PublicURL:               getEnv("PUBLIC_URL", ""),
+		StorageDriver:           getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir:         getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		S3Endpoint:              getEnv("S3_ENDPOINT", ""),
+		S3Region:                getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:                getEnv("S3_BUCKET", ""),
+		S3AccessKey:             getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:             getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:          getEnvBool("S3_USE_PATH_STYLE", false),
+		UploadMaxSize:           getEnvInt64("UPLOAD_MAX_SIZE", 10<<20),
+		AttachmentMaxSize:       getEnvInt64("ATTACHMENT_MAX_SIZE", 50<<20),
+		UploadSigningKey:        getEnv("UPLOAD_SIGNING_KEY", getEnv("JWT_SECRET", "your-secret-key")),
+		UploadURLExpiry:         getEnvDuration("UPLOAD_URL_EXPIRY", time.Hour),
+		ImageProcessingInterval: getEnvDuration("IMAGE_PROCESSING_INTERVAL", 30*time.Second),
+		FrontendURL:             getEnv("FRONTEND_URL", "http://localhost:3000"),
+		FeedTitle:               getEnv("FEED_TITLE", "Radionica"),
+		NewsViewWindow:          getEnvDuration("NEWS_VIEW_WINDOW", 30*time.Minute),
+		NewsViewFlushInterval:   getEnvDuration("NEWS_VIEW_FLUSH_INTERVAL", time.Minute),
+		TrashRetention:          getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval:      getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		DefaultLocale:           getEnv("DEFAULT_LOCALE", "hr"),
+		SupportedLocales:        getEnvList("SUPPORTED_LOCALES", []string{"hr", "en"}),
+		ProgramWeeks:            int(getEnvInt64("PROGRAM_WEEKS", 12)),
+		DefaultTimezone:         getEnv("DEFAULT_TIMEZONE", "Europe/Zagreb"),
 	}
 }
 
@@ -53,3 +105,36 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	}
 	return fallback
 }
+
+func getEnvInt64(key string, fallback int64) int64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+		log.Printf("Invalid integer for %s: %s, using fallback", key, value)
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("Invalid boolean for %s: %s, using fallback", key, value)
+	}
+	return fallback
+}
+
+func getEnvList(key string, fallback []string) []string {
+	if value, exists := os.LookupEnv(key); exists {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return fallback
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAnalyticsDays is the length of the reporting period when none is given
+const defaultAnalyticsDays = 30
+
+// GetNewsViewsHandler reports the views of a news item over time
+// @Summary News views over time
+// @Description Returns the daily views of a news item. The period defaults to the last 30 days. Views are flushed from memory periodically, so the current day lags slightly. Admins only.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "News ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Security BearerAuth
+// @Success 200 {object} models.NewsViewStats "Daily views"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /analytics/news/{id} [get]
+func (s *Server) GetNewsViewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := s.analyticsService.NewsViews(id, from, to, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch views: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetTopNewsHandler reports the most viewed news items
+// @Summary Most viewed news
+// @Description Returns the most viewed news items of a period, which defaults to the last 30 days. Admins only.
+// @Tags analytics
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param limit query int false "Number of items (default 10, max 100)"
+// @Security BearerAuth
+// @Success 200 {object} models.TopNews "Top news"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /analytics/news/top [get]
+func (s *Server) GetTopNewsHandler(c *gin.Context) {
+	from, to, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+	limit, ok := parseIntQuery(c, "limit")
+	if !ok {
+		return
+	}
+
+	top, err := s.analyticsService.TopNews(from, to, limit, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch top news: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, top)
+}
+
+// recordView counts a view of a published news item
+func (s *Server) recordView(c *gin.Context, news *models.News) {
+	if news.Status != models.NewsStatusPublished {
+		return
+	}
+	s.viewTracker.Record(news.ID, visitorKey(c), c.Request.UserAgent())
+}
+
+// visitorKey identifies the viewer for view deduplication: the user when
+// signed in, otherwise a hash of the client address and user agent
+func visitorKey(c *gin.Context) string {
+	if actor := actorFromContext(c); actor != nil {
+		return "user:" + actor.UserID.String()
+	}
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return "anon:" + hex.EncodeToString(sum[:16])
+}
+
+// parsePeriod reads the from and to query parameters as days, defaulting to
+// the last 30 days, and responds with 400 when either is malformed
+func parsePeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid to"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if value := c.Query("from"); value != "" {
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid from"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	return from, to, true
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAssignmentsHandler lists the homework of a cohort
+// @Summary List cohort assignments
+// @Description Returns the assignments of a cohort in the order they are due. Students enrolled in the cohort see the assignments of weeks that have started; mentors see them all.
+// @Tags assignments
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param week query int false "Only list the assignments of this week"
+// @Security BearerAuth
+// @Success 200 {array} models.Assignment "Assignments"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member of the cohort"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/assignments [get]
+func (s *Server) ListAssignmentsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	week, ok := parseIntQuery(c, "week")
+	if !ok {
+		return
+	}
+
+	assignments, err := s.assignmentService.ListAssignments(id, week, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch assignments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignments)
+}
+
+// CreateAssignmentHandler sets homework for a week of a cohort
+// @Summary Create an assignment
+// @Description Adds an assignment to a week of a cohort (mentors and admins only). Students see it from the start of its week. The late policy defaults to reject; under the penalty policy late_penalty percent of the points is deducted per day late.
+// @Tags assignments
+// @Accept json
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param assignment body AssignmentRequest true "Assignment"
+// @Security BearerAuth
+// @Success 201 {object} models.Assignment "Assignment created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/assignments [post]
+func (s *Server) CreateAssignmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	assignment, err := s.assignmentService.CreateAssignment(id, service.AssignmentInput{
+		Title:        req.Title,
+		Description:  req.Description,
+		Week:         req.Week,
+		DueAt:        req.DueAt,
+		MaxPoints:    req.MaxPoints,
+		AllowedTypes: req.AllowedTypes,
+		AllowLinks:   req.AllowLinks,
+		LatePolicy:   req.LatePolicy,
+		LatePenalty:  req.LatePenalty,
+		Weight:       req.Weight,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create assignment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// GetAssignmentHandler returns a single assignment
+// @Summary Get an assignment
+// @Description Fetches an assignment by ID. Students must be enrolled in its cohort, and its week must have started.
+// @Tags assignments
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Assignment "Assignment"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member of the cohort"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id} [get]
+func (s *Server) GetAssignmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	assignment, err := s.assignmentService.GetAssignment(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch assignment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// UpdateAssignmentHandler replaces an assignment
+// @Summary Update an assignment
+// @Description Replaces the fields of an assignment (mentors and admins only). Moving the due date changes which submissions count as late. max_points cannot be lowered below points already given.
+// @Tags assignments
+// @Accept json
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Param assignment body AssignmentRequest true "Assignment"
+// @Security BearerAuth
+// @Success 200 {object} models.Assignment "Assignment updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Max points below points already given"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id} [put]
+func (s *Server) UpdateAssignmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	assignment, err := s.assignmentService.UpdateAssignment(id, service.AssignmentInput{
+		Title:        req.Title,
+		Description:  req.Description,
+		Week:         req.Week,
+		DueAt:        req.DueAt,
+		MaxPoints:    req.MaxPoints,
+		AllowedTypes: req.AllowedTypes,
+		AllowLinks:   req.AllowLinks,
+		LatePolicy:   req.LatePolicy,
+		LatePenalty:  req.LatePenalty,
+		Weight:       req.Weight,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update assignment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// DeleteAssignmentHandler removes an assignment
+// @Summary Delete an assignment
+// @Description Removes an assignment nobody has submitted to yet (mentors and admins only)
+// @Tags assignments
+// @Param id path string true "Assignment ID"
+// @Security BearerAuth
+// @Success 204 "Assignment deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "The assignment has submissions"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id} [delete]
+func (s *Server) DeleteAssignmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.assignmentService.DeleteAssignment(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete assignment: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SubmitLinkHandler submits a repository link to an assignment
+// @Summary Submit a link
+// @Description Submits a repository link as the current user's next attempt at an assignment of a cohort they are enrolled in. Earlier attempts are kept. After the due date, submissions are refused under the reject policy and flagged late otherwise.
+// @Tags assignments
+// @Accept json
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Param submission body LinkSubmissionRequest true "Link"
+// @Security BearerAuth
+// @Success 201 {object} models.Submission "Submission created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member, cohort archived or past the due date"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/submissions [post]
+func (s *Server) SubmitLinkHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req LinkSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	submission, err := s.assignmentService.SubmitLink(id, req.URL, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to submit: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, submission)
+}
+
+// SubmitFileHandler uploads a file as a submission to an assignment
+// @Summary Submit a file
+// @Description Uploads a file as the current user's next attempt at an assignment of a cohort they are enrolled in. The extension must be one the assignment allows and agree with the file content. Submitted files are private. After the due date, submissions are refused under the reject policy and flagged late otherwise.
+// @Tags assignments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Param file formData file true "File"
+// @Security BearerAuth
+// @Success 201 {object} models.Submission "Submission created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member, cohort archived or past the due date"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 415 {object} ErrorResponse "File type not accepted"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/submissions/files [post]
+func (s *Server) SubmitFileHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	fileHeader, ok := formFile(c, "file", s.fileMaxSize)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	submission, err := s.assignmentService.SubmitFile(c.Request.Context(), id, fileHeader.Filename, file, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to submit: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, submission)
+}
+
+// ListSubmissionsHandler lists the submissions to an assignment
+// @Summary List submissions
+// @Description Returns each student's latest attempt at an assignment with late flags, and the students of the cohort who have not submitted (mentors and admins only). With history=true every attempt is listed.
+// @Tags assignments
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Param history query bool false "List every attempt instead of the latest ones"
+// @Security BearerAuth
+// @Success 200 {object} models.SubmissionList "Submissions"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/submissions [get]
+func (s *Server) ListSubmissionsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	list, err := s.assignmentService.ListSubmissions(id, c.Query("history") == "true", actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch submissions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// ListMySubmissionsHandler lists the caller's attempts at an assignment
+// @Summary List my submissions
+// @Description Returns the current user's attempts at an assignment, oldest first. The last one is the one that counts.
+// @Tags assignments
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Submission "Submissions"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member of the cohort"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/submissions/mine [get]
+func (s *Server) ListMySubmissionsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	submissions, err := s.assignmentService.ListMySubmissions(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch submissions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// AssignmentRequest represents the request body for creating or replacing an
+// assignment. Description is Markdown.
+type AssignmentRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	Week        int        `json:"week" binding:"required,min=1"`
+	DueAt       *time.Time `json:"due_at" binding:"required"`
+	MaxPoints   int        `json:"max_points" binding:"required,min=1"`
+	// AllowedTypes lists the file extensions students may submit, such as
+	// pdf or zip
+	AllowedTypes []string `json:"allowed_types"`
+	// AllowLinks accepts repository links as submissions
+	AllowLinks  bool   `json:"allow_links"`
+	LatePolicy  string `json:"late_policy" binding:"omitempty,oneof=reject accept penalty"`
+	LatePenalty int    `json:"late_penalty" binding:"min=0,max=100"`
+	// Weight sets how much the assignment counts towards the gradebook
+	// total; defaults to 1, and 0 leaves it out
+	Weight *int `json:"weight" binding:"omitempty,min=0"`
+}
+
+// LinkSubmissionRequest represents a repository link submitted to an
+// assignment
+type LinkSubmissionRequest struct {
+	URL string `json:"url" binding:"required"`
+}
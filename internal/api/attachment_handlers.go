@@ -0,0 +1,258 @@
+package api
+
+import (
+	"net/http"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListAttachmentsHandler returns the learning materials of a cirriculum entry
+// @Summary List cirriculum attachments
+// @Description Returns the files, links and videos attached to a cirriculum entry in order. They are also listed inline with the entry.
+// @Tags attachments
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Attachment "Attachments"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments [get]
+func (s *Server) ListAttachmentsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := s.attachmentService.ListAttachments(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch attachments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// AddAttachmentHandler attaches a link or video to a cirriculum entry
+// @Summary Add a link or video
+// @Description Adds an external link or an embedded video after the existing attachments of a cirriculum entry (mentors only). Videos must be YouTube or Vimeo addresses and get an embed_url. Upload files through /cirriculum/{id}/attachments/files.
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param attachment body AttachmentRequest true "Attachment"
+// @Security BearerAuth
+// @Success 201 {object} models.Attachment "Attachment created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments [post]
+func (s *Server) AddAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	attachment, err := s.attachmentService.AddAttachment(id, service.AttachmentInput{
+		Type:  req.Type,
+		Title: req.Title,
+		URL:   req.URL,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to add attachment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// UploadAttachmentHandler uploads a file and attaches it to a cirriculum entry
+// @Summary Upload a file attachment
+// @Description Uploads slides, a PDF or a starter archive (pdf, zip, pptx, docx, xlsx, odp, txt or md) and adds it after the existing attachments of a cirriculum entry (mentors only). The extension must agree with the file content.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param file formData file true "File"
+// @Param title formData string false "Title; defaults to the file name"
+// @Security BearerAuth
+// @Success 201 {object} models.Attachment "Attachment created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 415 {object} ErrorResponse "Unsupported file type"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments/files [post]
+func (s *Server) UploadAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	fileHeader, ok := formFile(c, "file", s.fileMaxSize)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := s.attachmentService.UploadAttachment(c.Request.Context(), id, c.PostForm("title"), fileHeader.Filename, file, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to upload attachment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// UpdateAttachmentHandler changes an attachment of a cirriculum entry
+// @Summary Update an attachment
+// @Description Changes the title of an attachment, or the url of a link or video (mentors only). Absent fields are left unchanged.
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param attachment body PatchAttachmentRequest true "Fields to change"
+// @Security BearerAuth
+// @Success 200 {object} models.Attachment "Attachment updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments/{attachmentId} [patch]
+func (s *Server) UpdateAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	var req PatchAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	attachment, err := s.attachmentService.UpdateAttachment(id, attachmentID, service.AttachmentPatch{
+		Title: req.Title,
+		URL:   req.URL,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update attachment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// ReorderAttachmentsHandler sets the order of the attachments of an entry
+// @Summary Reorder attachments
+// @Description Sets the order of the attachments of a cirriculum entry (mentors only). Attachments that are not listed keep their relative order after the listed ones.
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param order body ReorderAttachmentsRequest true "Attachment IDs in their new order"
+// @Security BearerAuth
+// @Success 200 {array} models.Attachment "Attachments in their new order"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments/order [put]
+func (s *Server) ReorderAttachmentsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReorderAttachmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	attachments, err := s.attachmentService.ReorderAttachments(id, req.IDs, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to reorder attachments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DeleteAttachmentHandler removes an attachment from a cirriculum entry
+// @Summary Delete an attachment
+// @Description Removes an attachment from a cirriculum entry (mentors only). Uploaded files are kept, since cloned entries may still list them.
+// @Tags attachments
+// @Param id path string true "Cirriculum ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Security BearerAuth
+// @Success 204 "Attachment deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/attachments/{attachmentId} [delete]
+func (s *Server) DeleteAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	if err := s.attachmentService.DeleteAttachment(id, attachmentID, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete attachment: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AttachmentRequest represents a link or video attached to a cirriculum entry
+type AttachmentRequest struct {
+	Type  string `json:"type" binding:"required,oneof=link video"`
+	Title string `json:"title" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+}
+
+// PatchAttachmentRequest represents a partial update of an attachment.
+// Absent fields are left unchanged.
+type PatchAttachmentRequest struct {
+	Title *string `json:"title" binding:"omitempty,min=1"`
+	URL   *string `json:"url" binding:"omitempty,min=1"`
+}
+
+// ReorderAttachmentsRequest lists the attachments of an entry in their new
+// order
+type ReorderAttachmentsRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"required,min=1"`
+}
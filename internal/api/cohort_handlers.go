@@ -0,0 +1,361 @@
+package api
+
+import (
+	"net/http"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListCohortsHandler lists the editions of the workshop
+// @Summary List cohorts
+// @Description Returns the cohorts, latest first, optionally only those with a given status
+// @Tags cohorts
+// @Produce json
+// @Param status query string false "Only list cohorts with this status" Enums(planned, active, archived)
+// @Success 200 {array} models.Cohort "Cohorts"
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts [get]
+func (s *Server) ListCohortsHandler(c *gin.Context) {
+	cohorts, err := s.cohortService.ListCohorts(c.Query("status"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cohorts: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cohorts)
+}
+
+// ListMyCohortsHandler lists the cohorts the user is enrolled in
+// @Summary List my cohorts
+// @Description Returns the cohorts the authenticated user is enrolled in, latest first
+// @Tags cohorts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Cohort "Cohorts"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/mine [get]
+func (s *Server) ListMyCohortsHandler(c *gin.Context) {
+	cohorts, err := s.cohortService.ListUserCohorts(actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cohorts: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cohorts)
+}
+
+// GetCohortHandler returns a single cohort
+// @Summary Get a cohort
+// @Description Fetches a cohort by ID
+// @Tags cohorts
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Success 200 {object} models.Cohort "Cohort"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id} [get]
+func (s *Server) GetCohortHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	cohort, err := s.cohortService.GetCohort(id)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cohort: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// CreateCohortHandler adds an edition of the workshop
+// @Summary Create a cohort
+// @Description Adds a cohort. Status defaults to planned. Admins only.
+// @Tags cohorts
+// @Accept json
+// @Produce json
+// @Param cohort body CohortRequest true "Cohort details"
+// @Security BearerAuth
+// @Success 201 {object} models.Cohort "Cohort created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Name already used"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts [post]
+func (s *Server) CreateCohortHandler(c *gin.Context) {
+	var req CohortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cohort, err := s.cohortService.CreateCohort(service.CohortInput{
+		Name:      req.Name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Timezone:  req.Timezone,
+		Status:    req.Status,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create cohort: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cohort)
+}
+
+// UpdateCohortHandler replaces the details of a cohort
+// @Summary Update a cohort
+// @Description Replaces the name, dates and status of a cohort. Archived cohorts are read-only until their status is changed. Admins only.
+// @Tags cohorts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param cohort body CohortRequest true "Cohort details"
+// @Security BearerAuth
+// @Success 200 {object} models.Cohort "Cohort"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Name already used"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id} [put]
+func (s *Server) UpdateCohortHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CohortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cohort, err := s.cohortService.UpdateCohort(id, service.CohortInput{
+		Name:      req.Name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Timezone:  req.Timezone,
+		Status:    req.Status,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update cohort: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// SetCohortStatusHandler changes the status of a cohort
+// @Summary Change cohort status
+// @Description Moves a cohort between planned, active and archived. Archiving makes the cohort, its news and its cirriculum read-only. Admins only.
+// @Tags cohorts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param status body CohortStatusRequest true "New status"
+// @Security BearerAuth
+// @Success 200 {object} models.Cohort "Cohort"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/status [patch]
+func (s *Server) SetCohortStatusHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CohortStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cohort, err := s.cohortService.SetStatus(id, req.Status, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to change cohort status: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// ListCohortMembersHandler lists the users enrolled in a cohort
+// @Summary List cohort members
+// @Description Returns the users enrolled in a cohort by username. Mentors and admins only.
+// @Tags cohorts
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Security BearerAuth
+// @Success 200 {array} models.CohortMember "Members"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/members [get]
+func (s *Server) ListCohortMembersHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	members, err := s.cohortService.ListMembers(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch members: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// EnrollCohortMemberHandler enrolls a user in a cohort
+// @Summary Enroll a user
+// @Description Adds a user to a cohort that is not archived; enrolling a member again is a no-op. Mentors and admins only.
+// @Tags cohorts
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Param userId path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {array} models.CohortMember "Members"
+// @Failure 400 {object} ErrorResponse "Invalid ID or unknown user"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/members/{userId} [put]
+func (s *Server) EnrollCohortMemberHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	members, err := s.cohortService.Enroll(id, userID, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to enroll user: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UnenrollCohortMemberHandler removes a user from a cohort
+// @Summary Unenroll a user
+// @Description Removes a user from a cohort that is not archived. Mentors and admins only.
+// @Tags cohorts
+// @Param id path string true "Cohort ID"
+// @Param userId path string true "User ID"
+// @Security BearerAuth
+// @Success 204 "User unenrolled"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or archived"
+// @Failure 404 {object} ErrorResponse "Not found or not enrolled"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/members/{userId} [delete]
+func (s *Server) UnenrollCohortMemberHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	if err := s.cohortService.Unenroll(id, userID, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to unenroll user: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CloneCirriculumHandler copies a cirriculum into a cohort
+// @Summary Clone cirriculum into a cohort
+// @Description Copies every entry of another cohort, or of the shared cirriculum when source_cohort_id is empty, into the cohort together with translations, image references and week titles. Weeks shift by week_offset. Copies go after the cohort's existing entries, and week titles only fill weeks that have none. Runs in one transaction; with dry_run nothing is stored and the response shows what would be created. Admins only.
+// @Tags cohorts
+// @Accept json
+// @Produce json
+// @Param id path string true "Target cohort ID"
+// @Param clone body CloneCirriculumRequest true "Source and options"
+// @Security BearerAuth
+// @Success 200 {object} models.CirriculumClone "Dry run result"
+// @Success 201 {object} models.CirriculumClone "Cirriculum cloned"
+// @Failure 400 {object} ErrorResponse "Invalid request or week out of range"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Cohort not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/cirriculum/clone [post]
+func (s *Server) CloneCirriculumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CloneCirriculumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	clone, err := s.cirriculumService.CloneCirriculum(id, service.CirriculumCloneInput{
+		SourceCohortID: req.SourceCohortID,
+		WeekOffset:     req.WeekOffset,
+		DryRun:         req.DryRun,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to clone cirriculum: " + err.Error()})
+		return
+	}
+
+	if clone.DryRun {
+		c.JSON(http.StatusOK, clone)
+		return
+	}
+	c.JSON(http.StatusCreated, clone)
+}
+
+// CohortRequest represents the request body for creating or replacing a
+// cohort. Dates are formatted as YYYY-MM-DD.
+type CohortRequest struct {
+	Name      string `json:"name" binding:"required"`
+	StartDate string `json:"start_date" binding:"required" example:"2026-03-02"`
+	EndDate   string `json:"end_date" binding:"required" example:"2026-05-22"`
+	// Timezone is an IANA zone name; defaults to the server's zone on create
+	// and is kept on update when empty
+	Timezone string `json:"timezone" example:"Europe/Zagreb"`
+	// Status defaults to planned on create and is kept on update when empty
+	Status string `json:"status" binding:"omitempty,oneof=planned active archived"`
+}
+
+// CohortStatusRequest represents the request body for changing cohort status
+type CohortStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=planned active archived"`
+}
+
+// CloneCirriculumRequest represents the request body for cloning a cirriculum
+type CloneCirriculumRequest struct {
+	// SourceCohortID is the cohort to copy from; leave empty for the shared cirriculum
+	SourceCohortID *uuid.UUID `json:"source_cohort_id"`
+	WeekOffset     int        `json:"week_offset"`
+	DryRun         bool       `json:"dry_run"`
+}
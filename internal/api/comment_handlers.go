@@ -0,0 +1,252 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetCommentsHandler lists the comments on a news item
+// @Summary List comments on a news item
+// @Description Returns a page of top-level comments, oldest first, each with all of its replies. Hidden comments are only listed for mentors and their author.
+// @Tags comments
+// @Produce json
+// @Param id path string true "News ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Top-level comments per page (default 20, max 100)"
+// @Security BearerAuth
+// @Success 200 {object} models.CommentPage "Comments"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/comments [get]
+func (s *Server) GetCommentsHandler(c *gin.Context) {
+	newsID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, ok := parseIntQuery(c, "page")
+	if !ok {
+		return
+	}
+	pageSize, ok := parseIntQuery(c, "page_size")
+	if !ok {
+		return
+	}
+
+	comments, err := s.commentService.ListComments(newsID, page, pageSize, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch comments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateCommentHandler adds a comment to a news item
+// @Summary Comment on a news item
+// @Description Adds a comment, or a reply when parent_id names a top-level comment of the same news item. Content is Markdown.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param comment body CreateCommentRequest true "Comment"
+// @Security BearerAuth
+// @Success 201 {object} models.Comment "Comment created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Comments are locked or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/comments [post]
+func (s *Server) CreateCommentHandler(c *gin.Context) {
+	newsID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	comment, err := s.commentService.CreateComment(newsID, req.ParentID, req.Content, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create comment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// LockCommentsHandler closes or reopens the comments of a news item
+// @Summary Lock or unlock comments
+// @Description Locks the comments of a news item so only mentors can comment, or unlocks them. Mentors and admins only.
+// @Tags comments
+// @Accept json
+// @Param id path string true "News ID"
+// @Param lock body LockCommentsRequest true "Lock state"
+// @Security BearerAuth
+// @Success 204 "Lock state changed"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/comments/lock [put]
+func (s *Server) LockCommentsHandler(c *gin.Context) {
+	newsID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req LockCommentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	if err := s.commentService.LockComments(newsID, *req.Locked, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to lock comments: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateCommentHandler edits a comment
+// @Summary Edit a comment
+// @Description Changes the text of a comment. Only its author can edit it, and not while the comments are locked.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param comment body UpdateCommentRequest true "Comment"
+// @Security BearerAuth
+// @Success 200 {object} models.Comment "Comment updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /comments/{id} [put]
+func (s *Server) UpdateCommentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	comment, err := s.commentService.UpdateComment(id, req.Content, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update comment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteCommentHandler deletes a comment
+// @Summary Delete a comment
+// @Description Deletes a comment. Authors can delete their own comments, mentors and admins any comment. Deleted comments with replies remain as empty placeholders.
+// @Tags comments
+// @Param id path string true "Comment ID"
+// @Security BearerAuth
+// @Success 204 "Comment deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /comments/{id} [delete]
+func (s *Server) DeleteCommentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.commentService.DeleteComment(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete comment: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ModerateCommentHandler hides or restores a comment
+// @Summary Hide or restore a comment
+// @Description Hides a comment from students or makes it visible again. Mentors and admins only.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param status body CommentStatusRequest true "New status"
+// @Security BearerAuth
+// @Success 200 {object} models.Comment "Comment updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /comments/{id}/status [patch]
+func (s *Server) ModerateCommentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req CommentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	comment, err := s.commentService.ModerateComment(id, req.Status, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to moderate comment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// parseIntQuery reads an optional integer query parameter, responding with
+// 400 when it is malformed. A missing parameter reads as 0.
+func parseIntQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name})
+		return 0, false
+	}
+	return n, true
+}
+
+// CreateCommentRequest represents the request body for commenting. Content is Markdown.
+type CreateCommentRequest struct {
+	Content  string     `json:"content" binding:"required,max=5000"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// UpdateCommentRequest represents the request body for editing a comment. Content is Markdown.
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// CommentStatusRequest represents the request body for moderating a comment
+type CommentStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=visible hidden"`
+}
+
+// LockCommentsRequest represents the request body for locking comments
+type LockCommentsRequest struct {
+	Locked *bool `json:"locked" binding:"required"`
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/feed"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewsRSSHandler serves published news as RSS
+// @Summary News RSS feed
+// @Description RSS 2.0 feed of the latest published news, optionally for a single category. Supports conditional requests via ETag and Last-Modified.
+// @Tags feeds
+// @Produce xml
+// @Param category path string false "Category"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Success 200 {string} string "RSS document"
+// @Success 304 "Not modified"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /feeds/news.rss [get]
+// @Router /feeds/categories/{category}/news.rss [get]
+func (s *Server) NewsRSSHandler(c *gin.Context) {
+	s.serveNewsFeed(c, "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// NewsAtomHandler serves published news as Atom
+// @Summary News Atom feed
+// @Description Atom 1.0 feed of the latest published news, optionally for a single category. Supports conditional requests via ETag and Last-Modified.
+// @Tags feeds
+// @Produce xml
+// @Param category path string false "Category"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Success 200 {string} string "Atom document"
+// @Success 304 "Not modified"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /feeds/news.atom [get]
+// @Router /feeds/categories/{category}/news.atom [get]
+func (s *Server) NewsAtomHandler(c *gin.Context) {
+	s.serveNewsFeed(c, "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+func (s *Server) serveNewsFeed(c *gin.Context, contentType string, render func(*feed.Feed) ([]byte, error)) {
+	selfLink := withQuery(s.baseURL(c)+c.Request.URL.Path, c)
+	f, err := s.feedService.NewsFeed(c.Param("category"), selfLink, s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to build feed: " + err.Error()})
+		return
+	}
+
+	body, err := render(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render feed: " + err.Error()})
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !f.Updated.IsZero() {
+		c.Header("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c, etag, f.Updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified evaluates If-None-Match and, when absent, If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// baseURL is the configured public URL of the API, or the one the request
+// came in on when none is configured
+func (s *Server) baseURL(c *gin.Context) string {
+	if s.publicURL != "" {
+		return strings.TrimRight(s.publicURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetRubricHandler replaces the rubric of an assignment
+// @Summary Set an assignment rubric
+// @Description Replaces the criteria an assignment is graded by (mentors and admins only). Each criterion is worth between min_points and max_points, and the max_points of all criteria must add up to the assignment's max_points. The rubric cannot change once submissions were graded. Send an empty list to remove it.
+// @Tags grades
+// @Accept json
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Param rubric body RubricRequest true "Criteria in order"
+// @Security BearerAuth
+// @Success 200 {object} models.Assignment "Assignment with its rubric"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Submissions were already graded"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/rubric [put]
+func (s *Server) SetRubricHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	criteria := make([]service.CriterionInput, len(req.Criteria))
+	for i, criterion := range req.Criteria {
+		criteria[i] = service.CriterionInput{
+			Title:       criterion.Title,
+			Description: criterion.Description,
+			MinPoints:   criterion.MinPoints,
+			MaxPoints:   criterion.MaxPoints,
+		}
+	}
+
+	assignment, err := s.assignmentService.SetRubric(id, criteria, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update rubric: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// GradeSubmissionHandler grades a submission
+// @Summary Grade a submission
+// @Description Gives a submission points and written feedback, replacing an earlier grade (mentors and admins only). Assignments with a rubric are graded with a score for every criterion, within its range, and the points are their sum. Under the penalty policy the late penalty is deducted from the points to give the final grade.
+// @Tags grades
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param grade body GradeRequest true "Grade"
+// @Security BearerAuth
+// @Success 200 {object} models.Submission "Graded submission"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /submissions/{id}/grade [put]
+func (s *Server) GradeSubmissionHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req GradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	submission, err := s.assignmentService.GradeSubmission(id, service.GradeInput{
+		Points:   req.Points,
+		Feedback: req.Feedback,
+		Scores:   req.Scores,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to grade submission: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, submission)
+}
+
+// ReleaseGradesHandler shows students their grades on an assignment
+// @Summary Release grades
+// @Description Lets students see their grades and feedback on an assignment, and counts them in their gradebook total (mentors and admins only). Grades given later are shown as soon as they are saved.
+// @Tags grades
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Assignment "Assignment"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/grades/release [put]
+func (s *Server) ReleaseGradesHandler(c *gin.Context) {
+	s.setGradesReleased(c, true)
+}
+
+// WithdrawGradesHandler hides the grades of an assignment from students again
+// @Summary Withdraw grades
+// @Description Hides the grades and feedback of an assignment from students again (mentors and admins only)
+// @Tags grades
+// @Produce json
+// @Param id path string true "Assignment ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Assignment "Assignment"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /assignments/{id}/grades/release [delete]
+func (s *Server) WithdrawGradesHandler(c *gin.Context) {
+	s.setGradesReleased(c, false)
+}
+
+func (s *Server) setGradesReleased(c *gin.Context, released bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	assignment, err := s.assignmentService.SetGradesReleased(id, released, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update grade release: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// GetGradebookHandler returns the gradebook of a cohort
+// @Summary Get the cohort gradebook
+// @Description Returns the final points of every student of a cohort on every assignment, released or not, with a weighted total (mentors and admins only). Cells line up with the assignments. The total is the weighted average of graded assignments and missing ones, which count as zero, as a percentage of their max points. With format=csv the gradebook is downloaded as a spreadsheet.
+// @Tags grades
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "Cohort ID"
+// @Param format query string false "Output format" Enums(json, csv)
+// @Security BearerAuth
+// @Success 200 {object} models.Gradebook "Gradebook"
+// @Failure 400 {object} ErrorResponse "Invalid ID or format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/gradebook [get]
+func (s *Server) GetGradebookHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid format"})
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	gradebook, err := s.assignmentService.GetGradebook(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch gradebook: " + err.Error()})
+		return
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := service.WriteGradebookCSV(&buf, gradebook); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export gradebook: " + err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "gradebook-" + id.String() + ".csv"}))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, gradebook)
+}
+
+// GetMyGradesHandler returns the caller's grades in a cohort
+// @Summary Get my grades
+// @Description Returns the current user's row of a cohort's gradebook. Only grades that were released show and count towards the total; assignments of weeks that have not started are left out.
+// @Tags grades
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Gradebook "Gradebook with the user's row"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a member of the cohort"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/gradebook/mine [get]
+func (s *Server) GetMyGradesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	gradebook, err := s.assignmentService.GetMyGrades(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch grades: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gradebook)
+}
+
+// RubricRequest lists the criteria of an assignment's rubric in order
+type RubricRequest struct {
+	Criteria []CriterionRequest `json:"criteria" binding:"dive"`
+}
+
+// CriterionRequest represents a rubric criterion worth between min_points and
+// max_points
+type CriterionRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	MinPoints   int    `json:"min_points" binding:"min=0"`
+	MaxPoints   int    `json:"max_points" binding:"required,min=1"`
+}
+
+// GradeRequest represents a grade of a submission. Give points, or scores for
+// every rubric criterion when the assignment has a rubric.
+type GradeRequest struct {
+	Points   *float64                `json:"points" binding:"omitempty,min=0"`
+	Feedback string                  `json:"feedback" binding:"max=10000"`
+	Scores   []models.CriterionScore `json:"scores"`
+}
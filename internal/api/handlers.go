@@ -1,15 +1,24 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"blazperic/radionica/config"
+	"blazperic/radionica/internal/feed"
+	"blazperic/radionica/internal/locale"
 	"blazperic/radionica/internal/models"
 	"blazperic/radionica/internal/repository"
 	"blazperic/radionica/internal/service"
+	"blazperic/radionica/internal/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -21,6 +30,23 @@ type Server struct {
 	authService       AuthService
 	newsService       NewsService
 	cirriculumService CirriculumService
+	attachmentService AttachmentService
+	progressService   ProgressService
+	profileService    ProfileService
+	assignmentService AssignmentService
+	uploadService     UploadService
+	commentService    CommentService
+	pollService       PollService
+	cohortService     CohortService
+	analyticsService  AnalyticsService
+	trashService      TrashService
+	viewTracker       ViewTracker
+	feedService       FeedService
+	locales           *locale.Set
+	publicURL         string
+	uploadMaxSize     int64
+	fileMaxSize       int64
+	jobs              []func(ctx context.Context)
 }
 
 // AuthService defines authentication operations
@@ -32,31 +58,236 @@ type AuthService interface {
 
 // NewsService defines news-related operations
 type NewsService interface {
-	GetAllNews() ([]*models.News, error)
-	CreateNews(title, content, ImagePath, category string, userID uuid.UUID) (*models.News, error)
+	GetAllNews(actor *service.Actor, cohortID *uuid.UUID, locale string) ([]*models.News, error)
+	GetFeaturedNews(actor *service.Actor, locale string) ([]*models.News, error)
+	GetNews(id uuid.UUID, actor *service.Actor, locale string) (*models.News, error)
+	GetNewsBySlug(slug string, actor *service.Actor, locale string) (*models.News, bool, error)
+	GetUnpublishedNews(actor *service.Actor, locale string) ([]*models.News, error)
+	CreateNews(input service.NewsInput, userID uuid.UUID) (*models.News, error)
+	UpdateNews(id uuid.UUID, input service.NewsInput, actor *service.Actor) (*models.News, error)
+	ChangeStatus(id uuid.UUID, status string, publishAt *time.Time, actor *service.Actor) (*models.News, error)
+	React(id uuid.UUID, reaction string, remove bool, actor *service.Actor) (*models.News, error)
+	Pin(id uuid.UUID, until *time.Time, actor *service.Actor) (*models.News, error)
+	Unpin(id uuid.UUID, actor *service.Actor) (*models.News, error)
+	SetFeatured(id uuid.UUID, featured bool, actor *service.Actor) (*models.News, error)
+	DeleteNews(id uuid.UUID, actor *service.Actor) error
+	ListRevisions(id uuid.UUID, actor *service.Actor) ([]*models.Revision, error)
+	GetRevision(id uuid.UUID, number int, actor *service.Actor) (*models.Revision, error)
+	DiffRevisions(id uuid.UUID, from, to int, actor *service.Actor) (*models.RevisionDiff, error)
+	RevertNews(id uuid.UUID, number int, actor *service.Actor) (*models.News, error)
+	SaveTranslation(id uuid.UUID, locale, title, content string, actor *service.Actor) (*models.Translation, error)
+	DeleteTranslation(id uuid.UUID, locale string, actor *service.Actor) error
 }
 
 // CirriculumService defines cirriculum-related operations
 type CirriculumService interface {
-	GetAllCirriculum() ([]*models.Cirriculum, error)
-	CreateCirriculum(title, content string, week int, userID uuid.UUID) (*models.Cirriculum, error)
+	GetAllCirriculum(cohortID *uuid.UUID, actor *service.Actor, locale string) ([]*models.Cirriculum, error)
+	GetCirriculumBySlug(slug string, actor *service.Actor, locale string) (*models.Cirriculum, bool, error)
+	GetCirriculum(id uuid.UUID, actor *service.Actor, locale string) (*models.Cirriculum, error)
+	CreateCirriculum(input service.CirriculumInput, userID uuid.UUID) (*models.Cirriculum, error)
+	UpdateCirriculum(id uuid.UUID, input service.CirriculumInput, actor *service.Actor) (*models.Cirriculum, error)
+	PatchCirriculum(id uuid.UUID, patch service.CirriculumPatch, actor *service.Actor) (*models.Cirriculum, error)
+	ReorderCirriculum(cohortID *uuid.UUID, weeks []models.CirriculumWeekOrder, actor *service.Actor) ([]*models.Cirriculum, error)
+	GetWeeks(cohortID *uuid.UUID, actor *service.Actor, locale string) ([]*models.CirriculumWeek, error)
+	GetWeek(cohortID *uuid.UUID, n int, actor *service.Actor, locale string) (*models.CirriculumWeek, error)
+	SaveWeek(cohortID *uuid.UUID, n int, title, theme string, actor *service.Actor) (*models.CirriculumWeek, error)
+	CloneCirriculum(cohortID uuid.UUID, input service.CirriculumCloneInput, actor *service.Actor) (*models.CirriculumClone, error)
+	SetPrerequisites(id uuid.UUID, prerequisiteIDs []uuid.UUID, actor *service.Actor) (*models.Cirriculum, error)
+	GetGraph(cohortID *uuid.UUID, actor *service.Actor, locale string) (*models.CirriculumGraph, error)
+	DeleteCirriculum(id uuid.UUID, actor *service.Actor) error
+	ListRevisions(id uuid.UUID, actor *service.Actor) ([]*models.Revision, error)
+	GetRevision(id uuid.UUID, number int, actor *service.Actor) (*models.Revision, error)
+	DiffRevisions(id uuid.UUID, from, to int, actor *service.Actor) (*models.RevisionDiff, error)
+	RevertCirriculum(id uuid.UUID, number int, actor *service.Actor) (*models.Cirriculum, error)
+	SaveTranslation(id uuid.UUID, locale, title, content string, actor *service.Actor) (*models.Translation, error)
+	DeleteTranslation(id uuid.UUID, locale string, actor *service.Actor) error
+}
+
+// AttachmentService defines operations on the learning materials of
+// cirriculum entries
+type AttachmentService interface {
+	ListAttachments(cirriculumID uuid.UUID, actor *service.Actor) ([]*models.Attachment, error)
+	AddAttachment(cirriculumID uuid.UUID, input service.AttachmentInput, actor *service.Actor) (*models.Attachment, error)
+	UploadAttachment(ctx context.Context, cirriculumID uuid.UUID, title, filename string, r io.Reader, actor *service.Actor) (*models.Attachment, error)
+	UpdateAttachment(cirriculumID, id uuid.UUID, patch service.AttachmentPatch, actor *service.Actor) (*models.Attachment, error)
+	ReorderAttachments(cirriculumID uuid.UUID, ids []uuid.UUID, actor *service.Actor) ([]*models.Attachment, error)
+	DeleteAttachment(cirriculumID, id uuid.UUID, actor *service.Actor) error
+}
+
+// ProgressService defines progress tracking operations
+type ProgressService interface {
+	SetProgress(cirriculumID uuid.UUID, status string, actor *service.Actor) (*models.Progress, error)
+	ClearProgress(cirriculumID uuid.UUID, actor *service.Actor) error
+	GetMyProgress(cohortID *uuid.UUID, actor *service.Actor) (*models.ProgressSummary, error)
+	GetCohortProgress(cohortID uuid.UUID, actor *service.Actor) (*models.CohortProgress, error)
+}
+
+// ProfileService defines operations on the caller's public profile
+type ProfileService interface {
+	UpdateProfile(patch service.ProfilePatch, actor *service.Actor) (*models.Author, error)
+}
+
+// AssignmentService defines assignment and submission operations
+type AssignmentService interface {
+	ListAssignments(cohortID uuid.UUID, week int, actor *service.Actor) ([]*models.Assignment, error)
+	GetAssignment(id uuid.UUID, actor *service.Actor) (*models.Assignment, error)
+	CreateAssignment(cohortID uuid.UUID, input service.AssignmentInput, actor *service.Actor) (*models.Assignment, error)
+	UpdateAssignment(id uuid.UUID, input service.AssignmentInput, actor *service.Actor) (*models.Assignment, error)
+	DeleteAssignment(id uuid.UUID, actor *service.Actor) error
+	SubmitLink(id uuid.UUID, link string, actor *service.Actor) (*models.Submission, error)
+	SubmitFile(ctx context.Context, id uuid.UUID, filename string, r io.Reader, actor *service.Actor) (*models.Submission, error)
+	ListSubmissions(id uuid.UUID, history bool, actor *service.Actor) (*models.SubmissionList, error)
+	ListMySubmissions(id uuid.UUID, actor *service.Actor) ([]*models.Submission, error)
+	SetRubric(id uuid.UUID, criteria []service.CriterionInput, actor *service.Actor) (*models.Assignment, error)
+	GradeSubmission(submissionID uuid.UUID, input service.GradeInput, actor *service.Actor) (*models.Submission, error)
+	SetGradesReleased(id uuid.UUID, released bool, actor *service.Actor) (*models.Assignment, error)
+	GetGradebook(cohortID uuid.UUID, actor *service.Actor) (*models.Gradebook, error)
+	GetMyGrades(cohortID uuid.UUID, actor *service.Actor) (*models.Gradebook, error)
+}
+
+// CommentService defines comment and moderation operations
+type CommentService interface {
+	ListComments(newsID uuid.UUID, page, pageSize int, actor *service.Actor) (*models.CommentPage, error)
+	CreateComment(newsID uuid.UUID, parentID *uuid.UUID, content string, actor *service.Actor) (*models.Comment, error)
+	UpdateComment(id uuid.UUID, content string, actor *service.Actor) (*models.Comment, error)
+	DeleteComment(id uuid.UUID, actor *service.Actor) error
+	ModerateComment(id uuid.UUID, status string, actor *service.Actor) (*models.Comment, error)
+	LockComments(newsID uuid.UUID, locked bool, actor *service.Actor) error
+}
+
+// PollService defines news poll operations
+type PollService interface {
+	GetPoll(newsID uuid.UUID, actor *service.Actor) (*models.Poll, error)
+	GetResults(newsID uuid.UUID, actor *service.Actor) (*models.PollResults, error)
+	CreatePoll(newsID uuid.UUID, input service.PollInput, actor *service.Actor) (*models.Poll, error)
+	DeletePoll(newsID uuid.UUID, actor *service.Actor) error
+	ClosePoll(newsID uuid.UUID, actor *service.Actor) (*models.Poll, error)
+	Vote(newsID uuid.UUID, optionIDs []uuid.UUID, actor *service.Actor) (*models.Poll, error)
+}
+
+// CohortService defines operations on workshop editions and enrollment
+type CohortService interface {
+	ListCohorts(status string) ([]*models.Cohort, error)
+	ListUserCohorts(actor *service.Actor) ([]*models.Cohort, error)
+	GetCohort(id uuid.UUID) (*models.Cohort, error)
+	CreateCohort(input service.CohortInput, actor *service.Actor) (*models.Cohort, error)
+	UpdateCohort(id uuid.UUID, input service.CohortInput, actor *service.Actor) (*models.Cohort, error)
+	SetStatus(id uuid.UUID, status string, actor *service.Actor) (*models.Cohort, error)
+	ListMembers(id uuid.UUID, actor *service.Actor) ([]*models.CohortMember, error)
+	Enroll(id, userID uuid.UUID, actor *service.Actor) ([]*models.CohortMember, error)
+	Unenroll(id, userID uuid.UUID, actor *service.Actor) error
+}
+
+// AnalyticsService defines content analytics operations
+type AnalyticsService interface {
+	NewsViews(newsID uuid.UUID, from, to time.Time, actor *service.Actor) (*models.NewsViewStats, error)
+	TopNews(from, to time.Time, limit int, actor *service.Actor) (*models.TopNews, error)
+}
+
+// TrashService defines operations on deleted content
+type TrashService interface {
+	ListTrash(itemType string, actor *service.Actor) ([]*models.TrashItem, error)
+	Restore(itemType string, id uuid.UUID, actor *service.Actor) error
+}
+
+// ViewTracker counts news views
+type ViewTracker interface {
+	Record(newsID uuid.UUID, visitor, userAgent string)
+}
+
+// FeedService defines syndication feed operations
+type FeedService interface {
+	NewsFeed(category, selfLink, locale string) (*feed.Feed, error)
+}
+
+// UploadService defines file upload operations
+type UploadService interface {
+	Upload(ctx context.Context, filename string, r io.Reader, visibility string, userID uuid.UUID) (*models.Asset, error)
+	GetAsset(id uuid.UUID, actor *service.Actor) (*models.Asset, error)
+	DeleteAsset(id uuid.UUID, actor *service.Actor) error
+	Open(ctx context.Context, id uuid.UUID, expires, signature string) (*models.Asset, io.ReadCloser, error)
+	OpenVariant(ctx context.Context, id uuid.UUID, variantName, expires, signature string) (*models.AssetVariant, io.ReadCloser, error)
 }
 
 // NewServer initializes a Server with injected dependencies
-func NewServer(db *sql.DB, cfg *config.Config) *Server {
+func NewServer(db *sql.DB, cfg *config.Config, store storage.Storage) *Server {
 	userRepo := repository.NewUserRepository(db)
 	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret, cfg.TokenDuration, cfg.RefreshTokenDuration)
+	assetRepo := repository.NewAssetRepository(db)
+	imageProcessor := service.NewImageProcessor(assetRepo, store)
+	uploadSvc := service.NewUploadService(assetRepo, store, imageProcessor, cfg.UploadMaxSize, cfg.AttachmentMaxSize, cfg.UploadSigningKey, cfg.UploadURLExpiry, cfg.PublicURL)
+	locales := locale.NewSet(cfg.DefaultLocale, cfg.SupportedLocales)
+	slugRepo := repository.NewSlugRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	translationRepo := repository.NewTranslationRepository(db)
+	cohortRepo := repository.NewCohortRepository(db)
+	cohortSvc := service.NewCohortService(cohortRepo, userRepo, cfg.DefaultTimezone)
 	newsRepo := repository.NewNewsRepository(db)
-	newsSvc := service.NewNewsService(newsRepo)
+	newsSvc := service.NewNewsService(newsRepo, cohortRepo, slugRepo, revisionRepo, translationRepo, uploadSvc, locales)
 	cirriculumRepo := repository.NewCirriculumRepository(db)
-	cirriculumSvc := service.NewCirriculumService(cirriculumRepo)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	cirriculumSvc := service.NewCirriculumService(cirriculumRepo, cohortRepo, slugRepo, revisionRepo, translationRepo, attachmentRepo, uploadSvc, locales, cfg.ProgramWeeks)
+	attachmentSvc := service.NewAttachmentService(attachmentRepo, cirriculumSvc, uploadSvc)
+	commentRepo := repository.NewCommentRepository(db)
+	commentSvc := service.NewCommentService(commentRepo, newsSvc)
+	assignmentRepo := repository.NewAssignmentRepository(db)
+	gradeRepo := repository.NewGradeRepository(db)
+	assignmentSvc := service.NewAssignmentService(assignmentRepo, gradeRepo, cohortRepo, uploadSvc, cfg.ProgramWeeks)
+	progressRepo := repository.NewProgressRepository(db)
+	progressSvc := service.NewProgressService(progressRepo, cohortRepo, cirriculumSvc)
+	profileSvc := service.NewProfileService(userRepo, uploadSvc)
+	pollRepo := repository.NewPollRepository(db)
+	pollSvc := service.NewPollService(pollRepo, newsSvc)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	analyticsSvc := service.NewAnalyticsService(analyticsRepo, newsSvc)
+	viewTracker := service.NewViewTracker(analyticsRepo, cfg.NewsViewWindow)
+	trashRepo := repository.NewTrashRepository(db)
+	trashSvc := service.NewTrashService(trashRepo, cohortRepo, store, cfg.TrashRetention)
+	feedSvc := service.NewFeedService(newsSvc, cfg.FeedTitle, cfg.FrontendURL)
 	return &Server{
 		authService:       authSvc,
 		newsService:       newsSvc,
 		cirriculumService: cirriculumSvc,
+		attachmentService: attachmentSvc,
+		progressService:   progressSvc,
+		profileService:    profileSvc,
+		assignmentService: assignmentSvc,
+		uploadService:     uploadSvc,
+		commentService:    commentSvc,
+		pollService:       pollSvc,
+		cohortService:     cohortSvc,
+		analyticsService:  analyticsSvc,
+		trashService:      trashSvc,
+		viewTracker:       viewTracker,
+		feedService:       feedSvc,
+		locales:           locales,
+		publicURL:         cfg.PublicURL,
+		uploadMaxSize:     cfg.UploadMaxSize,
+		fileMaxSize:       cfg.AttachmentMaxSize,
+		jobs: []func(ctx context.Context){
+			func(ctx context.Context) { newsSvc.RunScheduler(ctx, cfg.NewsSchedulerInterval) },
+			func(ctx context.Context) { imageProcessor.Run(ctx, cfg.ImageProcessingInterval) },
+			func(ctx context.Context) { viewTracker.Run(ctx, cfg.NewsViewFlushInterval) },
+			func(ctx context.Context) { trashSvc.Run(ctx, cfg.TrashPurgeInterval) },
+		},
 	}
 }
 
+// StartBackgroundJobs runs the server's periodic jobs until ctx is cancelled.
+// Wait on the returned WaitGroup after cancelling for the jobs to finish,
+// including the view tracker's final flush.
+func (s *Server) StartBackgroundJobs(ctx context.Context) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
+	return &wg
+}
+
 // RegisterHandler handles user registration
 // @Summary Register a new user
 // @Description Creates a new user with username and password
@@ -138,27 +369,147 @@ func (s *Server) RefreshTokenHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, tokens)
 }
 
-// GetNewsHandler retrieves all news items
+// GetNewsHandler retrieves all published news items
 // @Summary Get all news
-// @Description Fetches a list of all news items
+// @Description Fetches a list of all published news items, pinned items first and the rest ordered by publish date. Authenticated requests also get the reactions the user gave.
 // @Tags news
 // @Produce json
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Param cohort query string false "Cohort ID; limits the list to that cohort's news and news shared by every cohort"
+// @Security BearerAuth
 // @Success 200 {array} models.News "News list"
+// @Failure 400 {object} ErrorResponse "Invalid cohort"
+// @Failure 404 {object} ErrorResponse "Cohort not found"
 // @Failure 500 {object} ErrorResponse "Server error"
 // @Router /news [get]
 func (s *Server) GetNewsHandler(c *gin.Context) {
-	news, err := s.newsService.GetAllNews()
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.GetAllNews(actorFromContext(c), cohortID, s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news...)
+	c.JSON(http.StatusOK, news)
+}
+
+// GetFeaturedNewsHandler retrieves the featured news items
+// @Summary Get featured news
+// @Description Fetches the published news items flagged as featured, for the homepage hero section. Pinned items come first.
+// @Tags news
+// @Produce json
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {array} models.News "Featured news"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/featured [get]
+func (s *Server) GetFeaturedNewsHandler(c *gin.Context) {
+	news, err := s.newsService.GetFeaturedNews(actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news...)
+	c.JSON(http.StatusOK, news)
+}
+
+// GetNewsByIDHandler retrieves a single news item
+// @Summary Get a news item
+// @Description Fetches a news item by ID. Unpublished items are only visible to their author and admins. Views of published items are counted for analytics.
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {object} models.News "News item"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id} [get]
+func (s *Server) GetNewsByIDHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.GetNews(id, actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
+		return
+	}
+	s.recordView(c, news)
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// GetNewsBySlugHandler retrieves a single news item by slug
+// @Summary Get a news item by slug
+// @Description Fetches a news item by its slug. Slugs the item had before being renamed answer with a 301 redirect to the current one. Views of published items are counted for analytics.
+// @Tags news
+// @Produce json
+// @Param slug path string true "News slug"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {object} models.News "News item"
+// @Success 301 {object} models.News "Moved to the current slug"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/by-slug/{slug} [get]
+func (s *Server) GetNewsBySlugHandler(c *gin.Context) {
+	news, redirected, err := s.newsService.GetNewsBySlug(c.Param("slug"), actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
+		return
+	}
+
+	if redirected {
+		c.Header("Location", withQuery("/api/v1/news/by-slug/"+news.Slug, c))
+		includeNewsFields(c, news)
+		c.JSON(http.StatusMovedPermanently, news)
+		return
+	}
+	s.recordView(c, news)
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// GetUnpublishedNewsHandler retrieves drafts, scheduled and archived news
+// @Summary Get unpublished news
+// @Description Fetches the caller's draft, scheduled and archived news items (all authors for admins)
+// @Tags news
+// @Produce json
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {array} models.News "News list"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/drafts [get]
+func (s *Server) GetUnpublishedNewsHandler(c *gin.Context) {
+	news, err := s.newsService.GetUnpublishedNews(actorFromContext(c), s.requestLocale(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch news: " + err.Error()})
 		return
 	}
 
+	includeNewsFields(c, news...)
 	c.JSON(http.StatusOK, news)
 }
 
 // CreateNewsHandler creates a new news item
 // @Summary Create a news item
-// @Description Adds a new news item (requires authentication)
+// @Description Adds a new news item (requires authentication). Status defaults to published; scheduled items need a future published_at. News is shared by every cohort unless cohort_id is set; archived cohorts take no new news.
 // @Tags news
 // @Accept json
 // @Produce json
@@ -167,6 +518,7 @@ func (s *Server) GetNewsHandler(c *gin.Context) {
 // @Success 201 {object} models.News "News created"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Cohort archived"
 // @Failure 500 {object} ErrorResponse "Server error"
 // @Router /news [post]
 func (s *Server) CreateNewsHandler(c *gin.Context) {
@@ -182,36 +534,404 @@ func (s *Server) CreateNewsHandler(c *gin.Context) {
 		return
 	}
 
-	news, err := s.newsService.CreateNews(req.Title, req.Content, req.ImagePath, req.Category, userID.(uuid.UUID))
+	news, err := s.newsService.CreateNews(service.NewsInput{
+		Title:     req.Title,
+		Content:   req.Content,
+		ImagePath: req.ImagePath,
+		ImageID:   req.ImageID,
+		Category:  req.Category,
+		Status:    req.Status,
+		PublishAt: req.PublishedAt,
+		CohortID:  req.CohortID,
+	}, userID.(uuid.UUID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create news: " + err.Error()})
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create news: " + err.Error()})
 		return
 	}
 
+	includeNewsFields(c, news)
 	c.JSON(http.StatusCreated, news)
 }
 
+// UpdateNewsHandler edits a news item
+// @Summary Update a news item
+// @Description Replaces the content of a news item (author or admin only)
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param news body UpdateNewsRequest true "News details"
+// @Security BearerAuth
+// @Success 200 {object} models.News "News updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id} [put]
+func (s *Server) UpdateNewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdateNewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	news, err := s.newsService.UpdateNews(id, service.NewsInput{
+		Title:     req.Title,
+		Content:   req.Content,
+		ImagePath: req.ImagePath,
+		ImageID:   req.ImageID,
+		Category:  req.Category,
+		Slug:      req.Slug,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// ChangeNewsStatusHandler moves a news item through the publishing workflow
+// @Summary Change news status
+// @Description Publishes, schedules, archives or returns a news item to draft (author or admin only)
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param status body NewsStatusRequest true "New status"
+// @Security BearerAuth
+// @Success 200 {object} models.News "News updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/status [patch]
+func (s *Server) ChangeNewsStatusHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req NewsStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	news, err := s.newsService.ChangeStatus(id, req.Status, req.PublishedAt, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to change news status: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// DeleteNewsHandler moves a news item to the trash
+// @Summary Delete a news item
+// @Description Moves a news item to the trash (author or admin only). Admins can restore it until it is purged.
+// @Tags news
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 204 "News deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id} [delete]
+func (s *Server) DeleteNewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.newsService.DeleteNews(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete news: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddReactionHandler reacts to a news item
+// @Summary React to a news item
+// @Description Adds the user's reaction to a news item. Each reaction can be given once per user; repeating it has no effect. Reactions: like, love, laugh, wow, clap, celebrate.
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Param reaction path string true "Reaction" Enums(like, love, laugh, wow, clap, celebrate)
+// @Security BearerAuth
+// @Success 200 {object} models.News "News item with updated reactions"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/reactions/{reaction} [put]
+func (s *Server) AddReactionHandler(c *gin.Context) {
+	s.react(c, false)
+}
+
+// RemoveReactionHandler takes back a reaction to a news item
+// @Summary Remove a reaction from a news item
+// @Description Removes the user's reaction from a news item
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Param reaction path string true "Reaction" Enums(like, love, laugh, wow, clap, celebrate)
+// @Security BearerAuth
+// @Success 200 {object} models.News "News item with updated reactions"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/reactions/{reaction} [delete]
+func (s *Server) RemoveReactionHandler(c *gin.Context) {
+	s.react(c, true)
+}
+
+func (s *Server) react(c *gin.Context, remove bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.React(id, c.Param("reaction"), remove, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update reaction: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// PinNewsHandler pins a news item
+// @Summary Pin a news item
+// @Description Keeps a news item at the top of the news list, until pinned_until when given. Admins only.
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param pin body PinNewsRequest false "Pin expiry"
+// @Security BearerAuth
+// @Success 200 {object} models.News "Pinned news item"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/pin [put]
+func (s *Server) PinNewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req PinNewsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+			return
+		}
+	}
+
+	news, err := s.newsService.Pin(id, req.PinnedUntil, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to pin news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// UnpinNewsHandler unpins a news item
+// @Summary Unpin a news item
+// @Description Returns a pinned news item to its place by publish date. Admins only.
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.News "Unpinned news item"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/pin [delete]
+func (s *Server) UnpinNewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.Unpin(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to unpin news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// FeatureNewsHandler adds a news item to the featured list
+// @Summary Feature a news item
+// @Description Flags a news item as featured so it shows on the homepage. Admins only.
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.News "Featured news item"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/featured [put]
+func (s *Server) FeatureNewsHandler(c *gin.Context) {
+	s.setFeatured(c, true)
+}
+
+// UnfeatureNewsHandler removes a news item from the featured list
+// @Summary Unfeature a news item
+// @Description Removes the featured flag from a news item. Admins only.
+// @Tags news
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.News "News item"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/featured [delete]
+func (s *Server) UnfeatureNewsHandler(c *gin.Context) {
+	s.setFeatured(c, false)
+}
+
+func (s *Server) setFeatured(c *gin.Context, featured bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.SetFeatured(id, featured, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
 // GetAllCirriculumHandler retrieves all cirriculum items
 // @Summary Get all cirriculum
-// @Description Fetches a list of all cirriculum items
+// @Description Fetches the cirriculum of a cohort, or the cirriculum shared by every cohort when no cohort is given. Cohort entries are released at the start of their week in the cohort's timezone, or at their available_from time when set; until then only mentors and the author see them, flagged with released false.
 // @Tags cirriculum
 // @Produce json
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Param cohort query string false "Cohort ID"
+// @Security BearerAuth
 // @Success 200 {array} models.Cirriculum "Cirriculum list"
+// @Failure 400 {object} ErrorResponse "Invalid cohort"
+// @Failure 404 {object} ErrorResponse "Cohort not found"
 // @Failure 500 {object} ErrorResponse "Server error"
 // @Router /cirriculum [get]
 func (s *Server) GetAllCirriculumHandler(c *gin.Context) {
-	cirriculum, err := s.cirriculumService.GetAllCirriculum()
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	cirriculum, err := s.cirriculumService.GetAllCirriculum(cohortID, actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirriculum...)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+// GetCirriculumBySlugHandler retrieves a single cirriculum entry by slug
+// @Summary Get a cirriculum entry by slug
+// @Description Fetches a cirriculum entry by its slug. Slugs the entry had before being renamed answer with a 301 redirect to the current one. Entries that are not released yet are only found by mentors and their author.
+// @Tags cirriculum
+// @Produce json
+// @Param slug path string true "Cirriculum slug"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Cirriculum entry"
+// @Success 301 {object} models.Cirriculum "Moved to the current slug"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/by-slug/{slug} [get]
+func (s *Server) GetCirriculumBySlugHandler(c *gin.Context) {
+	cirriculum, redirected, err := s.cirriculumService.GetCirriculumBySlug(c.Param("slug"), actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
+		return
+	}
+
+	if redirected {
+		c.Header("Location", withQuery("/api/v1/cirriculum/by-slug/"+cirriculum.Slug, c))
+		includeCirriculumFields(c, cirriculum)
+		c.JSON(http.StatusMovedPermanently, cirriculum)
+		return
+	}
+	includeCirriculumFields(c, cirriculum)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+// GetCirriculumByIDHandler retrieves a single cirriculum entry
+// @Summary Get a cirriculum entry
+// @Description Fetches a cirriculum entry by ID. Entries that are not released yet are only found by mentors and their author.
+// @Tags cirriculum
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Cirriculum entry"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id} [get]
+func (s *Server) GetCirriculumByIDHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	cirriculum, err := s.cirriculumService.GetCirriculum(id, actorFromContext(c), s.requestLocale(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
 		return
 	}
 
+	includeCirriculumFields(c, cirriculum)
 	c.JSON(http.StatusOK, cirriculum)
 }
 
 // CreateCirriculumHandler creates a new cirriculum entry
 // @Summary Create a cirriculum
-// @Description Adds a new cirriculum (requires authentication)
+// @Description Adds a new cirriculum (requires authentication), shared by every cohort unless cohort_id is set. Archived cohorts take no new entries. Set available_from to release the entry at a set time instead of at the start of its week.
 // @Tags cirriculum
 // @Accept json
 // @Produce json
@@ -220,6 +940,7 @@ func (s *Server) GetAllCirriculumHandler(c *gin.Context) {
 // @Success 201 {object} models.Cirriculum "Cirriculum created"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Cohort archived"
 // @Failure 500 {object} ErrorResponse "Server error"
 // @Router /cirriculum [post]
 func (s *Server) CreateCirriculumHandler(c *gin.Context) {
@@ -237,15 +958,192 @@ func (s *Server) CreateCirriculumHandler(c *gin.Context) {
 		return
 	}
 
-	cirriculum, err := s.cirriculumService.CreateCirriculum(req.Title, req.Description, int(req.Week), userID.(uuid.UUID))
+	cirriculum, err := s.cirriculumService.CreateCirriculum(service.CirriculumInput{
+		Title:         req.Title,
+		Description:   req.Description,
+		Week:          req.Week,
+		ImageID:       req.ImageID,
+		AvailableFrom: req.AvailableFrom,
+		CohortID:      req.CohortID,
+	}, userID.(uuid.UUID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create cirriculum: " + err.Error()})
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create cirriculum: " + err.Error()})
 		return
 	}
 
+	includeCirriculumFields(c, cirriculum)
 	c.JSON(http.StatusCreated, cirriculum)
 }
 
+// UpdateCirriculumHandler replaces a cirriculum entry
+// @Summary Update a cirriculum entry
+// @Description Replaces the content of a cirriculum entry (author or mentors only). An entry moved to another week goes to the end of that week. Moves that would put it before one of its prerequisites, or after an entry requiring it, are rejected.
+// @Tags cirriculum
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param cirriculum body UpdateCirriculumRequest true "Cirriculum details"
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Cirriculum updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id} [put]
+func (s *Server) UpdateCirriculumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdateCirriculumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cirriculum, err := s.cirriculumService.UpdateCirriculum(id, service.CirriculumInput{
+		Title:         req.Title,
+		Description:   req.Description,
+		Week:          req.Week,
+		ImageID:       req.ImageID,
+		Slug:          req.Slug,
+		AvailableFrom: req.AvailableFrom,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirriculum)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+// PatchCirriculumHandler changes some fields of a cirriculum entry
+// @Summary Patch a cirriculum entry
+// @Description Changes only the fields present in the body (author or mentors only). Send image_id as null to remove the image, and available_from as null to release the entry with its week again.
+// @Tags cirriculum
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param cirriculum body PatchCirriculumRequest true "Fields to change"
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Cirriculum updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id} [patch]
+func (s *Server) PatchCirriculumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PatchCirriculumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	patch := service.CirriculumPatch{
+		Title:       req.Title,
+		Description: req.Description,
+		Week:        req.Week,
+		Slug:        req.Slug,
+	}
+	if len(req.ImageID) > 0 {
+		patch.SetImage = true
+		if string(req.ImageID) != "null" {
+			var imageID uuid.UUID
+			if err := json.Unmarshal(req.ImageID, &imageID); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid image_id"})
+				return
+			}
+			patch.ImageID = &imageID
+		}
+	}
+	if len(req.AvailableFrom) > 0 {
+		patch.SetAvailableFrom = true
+		if string(req.AvailableFrom) != "null" {
+			var availableFrom time.Time
+			if err := json.Unmarshal(req.AvailableFrom, &availableFrom); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid available_from"})
+				return
+			}
+			patch.AvailableFrom = &availableFrom
+		}
+	}
+
+	cirriculum, err := s.cirriculumService.PatchCirriculum(id, patch, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirriculum)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+// ReorderCirriculumHandler moves cirriculum entries between and within weeks
+// @Summary Reorder cirriculum
+// @Description Sets the order of the entries in each listed week, moving entries from other weeks as needed, in one atomic update. Entries of an affected week that are not listed keep their relative order after the listed ones. Moves that would schedule a prerequisite after an entry requiring it are rejected. Applies to the shared cirriculum, or to the cirriculum of cohort_id when set. Mentors and admins only.
+// @Tags cirriculum
+// @Accept json
+// @Produce json
+// @Param order body ReorderCirriculumRequest true "New order per week"
+// @Security BearerAuth
+// @Success 200 {array} models.Cirriculum "Cirriculum in its new order"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/order [put]
+func (s *Server) ReorderCirriculumHandler(c *gin.Context) {
+	var req ReorderCirriculumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cirricula, err := s.cirriculumService.ReorderCirriculum(req.CohortID, req.Weeks, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to reorder cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirricula...)
+	c.JSON(http.StatusOK, cirricula)
+}
+
+// DeleteCirriculumHandler moves a cirriculum entry to the trash
+// @Summary Delete a cirriculum entry
+// @Description Moves a cirriculum entry to the trash (author or mentors only). Admins can restore it until it is purged.
+// @Tags cirriculum
+// @Param id path string true "Cirriculum ID"
+// @Security BearerAuth
+// @Success 204 "Cirriculum deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id} [delete]
+func (s *Server) DeleteCirriculumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.cirriculumService.DeleteCirriculum(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete cirriculum: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // SetupRouter configures the Gin router with grouped endpoints
 func SetupRouter(server *Server, jwtSecret string) *gin.Engine {
 	r := gin.Default()
@@ -258,7 +1156,7 @@ func SetupRouter(server *Server, jwtSecret string) *gin.Engine {
 			"https://test-radionica.vercel.app",
 			"https://radionica-switch-front-rkmd.vercel.app/",
 		},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type", "Authorization", "ngrok-skip-browser-warning"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -282,15 +1180,157 @@ func SetupRouter(server *Server, jwtSecret string) *gin.Engine {
 		// News routes
 		news := apiV1.Group("/news")
 		{
-			news.GET("", server.GetNewsHandler)
+			news.GET("", OptionalJWTAuth(jwtSecret), server.GetNewsHandler)
+			news.GET("/drafts", JWTAuth(jwtSecret), server.GetUnpublishedNewsHandler)
+			news.GET("/featured", OptionalJWTAuth(jwtSecret), server.GetFeaturedNewsHandler)
+			news.GET("/by-slug/:slug", OptionalJWTAuth(jwtSecret), server.GetNewsBySlugHandler)
+			news.GET("/:id", OptionalJWTAuth(jwtSecret), server.GetNewsByIDHandler)
 			news.POST("", JWTAuth(jwtSecret), server.CreateNewsHandler)
+			news.PUT("/:id", JWTAuth(jwtSecret), server.UpdateNewsHandler)
+			news.DELETE("/:id", JWTAuth(jwtSecret), server.DeleteNewsHandler)
+			news.PATCH("/:id/status", JWTAuth(jwtSecret), server.ChangeNewsStatusHandler)
+			news.GET("/:id/comments", JWTAuth(jwtSecret), server.GetCommentsHandler)
+			news.POST("/:id/comments", JWTAuth(jwtSecret), server.CreateCommentHandler)
+			news.GET("/:id/poll", OptionalJWTAuth(jwtSecret), server.GetPollHandler)
+			news.POST("/:id/poll", JWTAuth(jwtSecret), server.CreatePollHandler)
+			news.DELETE("/:id/poll", JWTAuth(jwtSecret), server.DeletePollHandler)
+			news.POST("/:id/poll/close", JWTAuth(jwtSecret), server.ClosePollHandler)
+			news.GET("/:id/poll/results", OptionalJWTAuth(jwtSecret), server.GetPollResultsHandler)
+			news.POST("/:id/poll/votes", JWTAuth(jwtSecret), server.VotePollHandler)
+			news.PUT("/:id/reactions/:reaction", JWTAuth(jwtSecret), server.AddReactionHandler)
+			news.DELETE("/:id/reactions/:reaction", JWTAuth(jwtSecret), server.RemoveReactionHandler)
+			news.GET("/:id/revisions", JWTAuth(jwtSecret), server.ListNewsRevisionsHandler)
+			news.GET("/:id/revisions/diff", JWTAuth(jwtSecret), server.DiffNewsRevisionsHandler)
+			news.GET("/:id/revisions/:number", JWTAuth(jwtSecret), server.GetNewsRevisionHandler)
+			news.POST("/:id/revisions/:number/revert", JWTAuth(jwtSecret), server.RevertNewsHandler)
+			news.PUT("/:id/pin", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.PinNewsHandler)
+			news.DELETE("/:id/pin", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.UnpinNewsHandler)
+			news.PUT("/:id/featured", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.FeatureNewsHandler)
+			news.DELETE("/:id/featured", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.UnfeatureNewsHandler)
+			news.PUT("/:id/translations/:locale", JWTAuth(jwtSecret), server.SaveNewsTranslationHandler)
+			news.DELETE("/:id/translations/:locale", JWTAuth(jwtSecret), server.DeleteNewsTranslationHandler)
+			news.PUT("/:id/comments/lock", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.LockCommentsHandler)
+		}
+
+		// Comment routes
+		comments := apiV1.Group("/comments", JWTAuth(jwtSecret))
+		{
+			comments.PUT("/:id", server.UpdateCommentHandler)
+			comments.DELETE("/:id", server.DeleteCommentHandler)
+			comments.PATCH("/:id/status", RequireRole(models.RoleMentor, models.RoleAdmin), server.ModerateCommentHandler)
 		}
 
 		// Cirriculum routes
 		cirriculum := apiV1.Group("/cirriculum")
 		{
-			cirriculum.GET("", server.GetAllCirriculumHandler)
+			cirriculum.GET("", OptionalJWTAuth(jwtSecret), server.GetAllCirriculumHandler)
+			cirriculum.GET("/by-slug/:slug", OptionalJWTAuth(jwtSecret), server.GetCirriculumBySlugHandler)
+			cirriculum.GET("/weeks", OptionalJWTAuth(jwtSecret), server.GetCirriculumWeeksHandler)
+			cirriculum.GET("/weeks/:week", OptionalJWTAuth(jwtSecret), server.GetCirriculumWeekHandler)
+			cirriculum.PUT("/weeks/:week", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.SaveCirriculumWeekHandler)
+			cirriculum.GET("/graph", OptionalJWTAuth(jwtSecret), server.GetCirriculumGraphHandler)
+			cirriculum.GET("/:id", OptionalJWTAuth(jwtSecret), server.GetCirriculumByIDHandler)
 			cirriculum.POST("", JWTAuth(jwtSecret), server.CreateCirriculumHandler)
+			cirriculum.PUT("/order", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.ReorderCirriculumHandler)
+			cirriculum.PUT("/:id", JWTAuth(jwtSecret), server.UpdateCirriculumHandler)
+			cirriculum.PATCH("/:id", JWTAuth(jwtSecret), server.PatchCirriculumHandler)
+			cirriculum.DELETE("/:id", JWTAuth(jwtSecret), server.DeleteCirriculumHandler)
+			cirriculum.PUT("/:id/translations/:locale", JWTAuth(jwtSecret), server.SaveCirriculumTranslationHandler)
+			cirriculum.DELETE("/:id/translations/:locale", JWTAuth(jwtSecret), server.DeleteCirriculumTranslationHandler)
+			cirriculum.GET("/:id/revisions", JWTAuth(jwtSecret), server.ListCirriculumRevisionsHandler)
+			cirriculum.GET("/:id/revisions/diff", JWTAuth(jwtSecret), server.DiffCirriculumRevisionsHandler)
+			cirriculum.GET("/:id/revisions/:number", JWTAuth(jwtSecret), server.GetCirriculumRevisionHandler)
+			cirriculum.POST("/:id/revisions/:number/revert", JWTAuth(jwtSecret), server.RevertCirriculumHandler)
+			cirriculum.PUT("/:id/prerequisites", JWTAuth(jwtSecret), server.SetPrerequisitesHandler)
+			cirriculum.PUT("/:id/progress", JWTAuth(jwtSecret), server.SetProgressHandler)
+			cirriculum.DELETE("/:id/progress", JWTAuth(jwtSecret), server.ClearProgressHandler)
+			cirriculum.GET("/:id/attachments", OptionalJWTAuth(jwtSecret), server.ListAttachmentsHandler)
+			cirriculum.POST("/:id/attachments", JWTAuth(jwtSecret), server.AddAttachmentHandler)
+			cirriculum.POST("/:id/attachments/files", JWTAuth(jwtSecret), server.UploadAttachmentHandler)
+			cirriculum.PUT("/:id/attachments/order", JWTAuth(jwtSecret), server.ReorderAttachmentsHandler)
+			cirriculum.PATCH("/:id/attachments/:attachmentId", JWTAuth(jwtSecret), server.UpdateAttachmentHandler)
+			cirriculum.DELETE("/:id/attachments/:attachmentId", JWTAuth(jwtSecret), server.DeleteAttachmentHandler)
+		}
+
+		// Cohort routes
+		cohorts := apiV1.Group("/cohorts")
+		{
+			cohorts.GET("", server.ListCohortsHandler)
+			cohorts.GET("/mine", JWTAuth(jwtSecret), server.ListMyCohortsHandler)
+			cohorts.GET("/:id", server.GetCohortHandler)
+			cohorts.POST("", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.CreateCohortHandler)
+			cohorts.PUT("/:id", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.UpdateCohortHandler)
+			cohorts.PATCH("/:id/status", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.SetCohortStatusHandler)
+			cohorts.GET("/:id/members", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.ListCohortMembersHandler)
+			cohorts.PUT("/:id/members/:userId", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.EnrollCohortMemberHandler)
+			cohorts.DELETE("/:id/members/:userId", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.UnenrollCohortMemberHandler)
+			cohorts.POST("/:id/cirriculum/clone", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin), server.CloneCirriculumHandler)
+			cohorts.GET("/:id/progress", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.GetCohortProgressHandler)
+			cohorts.GET("/:id/assignments", JWTAuth(jwtSecret), server.ListAssignmentsHandler)
+			cohorts.POST("/:id/assignments", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.CreateAssignmentHandler)
+			cohorts.GET("/:id/gradebook", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin), server.GetGradebookHandler)
+			cohorts.GET("/:id/gradebook/mine", JWTAuth(jwtSecret), server.GetMyGradesHandler)
+		}
+
+		// Assignment routes
+		assignments := apiV1.Group("/assignments", JWTAuth(jwtSecret))
+		{
+			assignments.GET("/:id", server.GetAssignmentHandler)
+			assignments.PUT("/:id", RequireRole(models.RoleMentor, models.RoleAdmin), server.UpdateAssignmentHandler)
+			assignments.DELETE("/:id", RequireRole(models.RoleMentor, models.RoleAdmin), server.DeleteAssignmentHandler)
+			assignments.GET("/:id/submissions", RequireRole(models.RoleMentor, models.RoleAdmin), server.ListSubmissionsHandler)
+			assignments.GET("/:id/submissions/mine", server.ListMySubmissionsHandler)
+			assignments.POST("/:id/submissions", server.SubmitLinkHandler)
+			assignments.POST("/:id/submissions/files", server.SubmitFileHandler)
+			assignments.PUT("/:id/rubric", RequireRole(models.RoleMentor, models.RoleAdmin), server.SetRubricHandler)
+			assignments.PUT("/:id/grades/release", RequireRole(models.RoleMentor, models.RoleAdmin), server.ReleaseGradesHandler)
+			assignments.DELETE("/:id/grades/release", RequireRole(models.RoleMentor, models.RoleAdmin), server.WithdrawGradesHandler)
+		}
+
+		// Submission routes
+		submissions := apiV1.Group("/submissions", JWTAuth(jwtSecret), RequireRole(models.RoleMentor, models.RoleAdmin))
+		{
+			submissions.PUT("/:id/grade", server.GradeSubmissionHandler)
+		}
+
+		// Routes for the signed-in user
+		me := apiV1.Group("/me", JWTAuth(jwtSecret))
+		{
+			me.PATCH("", server.UpdateProfileHandler)
+			me.GET("/progress", server.GetMyProgressHandler)
+		}
+
+		// Upload routes
+		uploads := apiV1.Group("/uploads")
+		{
+			uploads.POST("", JWTAuth(jwtSecret), server.UploadFileHandler)
+			uploads.GET("/:id", server.ServeFileHandler)
+			uploads.GET("/:id/info", OptionalJWTAuth(jwtSecret), server.GetAssetHandler)
+			uploads.DELETE("/:id", JWTAuth(jwtSecret), server.DeleteAssetHandler)
+			uploads.GET("/:id/variants/:variant", server.ServeVariantHandler)
+		}
+
+		// Analytics routes
+		analytics := apiV1.Group("/analytics", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin))
+		{
+			analytics.GET("/news/top", server.GetTopNewsHandler)
+			analytics.GET("/news/:id", server.GetNewsViewsHandler)
+		}
+
+		// Trash routes
+		trash := apiV1.Group("/trash", JWTAuth(jwtSecret), RequireRole(models.RoleAdmin))
+		{
+			trash.GET("", server.ListTrashHandler)
+			trash.POST("/:type/:id/restore", server.RestoreTrashHandler)
+		}
+
+		// Feed routes
+		feeds := apiV1.Group("/feeds")
+		{
+			feeds.GET("/news.rss", server.NewsRSSHandler)
+			feeds.GET("/news.atom", server.NewsAtomHandler)
+			feeds.GET("/categories/:category/news.rss", server.NewsRSSHandler)
+			feeds.GET("/categories/:category/news.atom", server.NewsAtomHandler)
 		}
 	}
 
@@ -324,16 +1364,162 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-// CreateNewsRequest represents the request body for creating news
+// statusForError maps service errors to HTTP status codes
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupported):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseIDParam reads a UUID path parameter, responding with 400 when it is malformed
+func parseIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseCohortQuery reads the optional cohort query parameter, responding
+// with 400 when it is malformed
+func parseCohortQuery(c *gin.Context) (*uuid.UUID, bool) {
+	raw := c.Query("cohort")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cohort"})
+		return nil, false
+	}
+	return &id, true
+}
+
+// includeNewsFields drops the optional fields of news items that the client
+// did not ask for in the include query parameter
+func includeNewsFields(c *gin.Context, newsList ...*models.News) {
+	if included(c, "author") {
+		return
+	}
+	for _, news := range newsList {
+		news.Author = nil
+	}
+}
+
+// includeCirriculumFields is includeNewsFields for cirriculum entries
+func includeCirriculumFields(c *gin.Context, cirricula ...*models.Cirriculum) {
+	if included(c, "author") {
+		return
+	}
+	for _, cirriculum := range cirricula {
+		cirriculum.Author = nil
+	}
+}
+
+// included reports whether field is listed in the comma-separated include
+// query parameter, e.g. ?include=author
+func included(c *gin.Context, field string) bool {
+	for _, f := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(f) == field {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateNewsRequest represents the request body for creating news. Content is Markdown.
 type CreateNewsRequest struct {
-	Title     string `json:"title" binding:"required"`
-	Content   string `json:"content" binding:"required"`
-	ImagePath string `json:"image_path" binding:"required"`
-	Category  string `json:"category" binding:"required"`
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required"`
+	ImagePath   string     `json:"image_path" binding:"required_without=ImageID"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	Category    string     `json:"category" binding:"required"`
+	Status      string     `json:"status" binding:"omitempty,oneof=draft scheduled published"`
+	PublishedAt *time.Time `json:"published_at"`
+	// CohortID scopes the item to a cohort; leave empty to share it with every cohort
+	CohortID *uuid.UUID `json:"cohort_id"`
 }
 
+// UpdateNewsRequest represents the request body for editing news. Content is Markdown.
+type UpdateNewsRequest struct {
+	Title     string     `json:"title" binding:"required"`
+	Content   string     `json:"content" binding:"required"`
+	ImagePath string     `json:"image_path" binding:"required_without=ImageID"`
+	ImageID   *uuid.UUID `json:"image_id"`
+	Category  string     `json:"category" binding:"required"`
+	// Slug renames the item; leave empty to keep the current slug
+	Slug string `json:"slug"`
+}
+
+// PinNewsRequest represents the request body for pinning news. Without
+// pinned_until the pin lasts until removed.
+type PinNewsRequest struct {
+	PinnedUntil *time.Time `json:"pinned_until"`
+}
+
+// NewsStatusRequest represents the request body for changing news status
+type NewsStatusRequest struct {
+	Status      string     `json:"status" binding:"required,oneof=draft scheduled published archived"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// CreateCirriculumRequest represents the request body for creating a cirriculum entry. Description is Markdown.
 type CreateCirriculumRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Week        int    `json:"week" binding:"required,numeric"`
-	Description string `json:"description" binding:"required"`
+	Title       string     `json:"title" binding:"required"`
+	Week        int        `json:"week" binding:"required,min=1"`
+	Description string     `json:"description" binding:"required"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	// CohortID scopes the entry to a cohort; leave empty to share it with every cohort
+	CohortID *uuid.UUID `json:"cohort_id"`
+	// AvailableFrom releases the entry at a set time; leave empty to release
+	// it at the start of its week
+	AvailableFrom *time.Time `json:"available_from"`
+}
+
+// UpdateCirriculumRequest represents the request body for replacing a cirriculum entry. Description is Markdown.
+type UpdateCirriculumRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Week        int        `json:"week" binding:"required,min=1"`
+	Description string     `json:"description" binding:"required"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	// Slug renames the entry; the old slug keeps redirecting
+	Slug string `json:"slug"`
+	// AvailableFrom releases the entry at a set time; leave empty to release
+	// it at the start of its week
+	AvailableFrom *time.Time `json:"available_from"`
+}
+
+// PatchCirriculumRequest represents a partial update of a cirriculum entry.
+// Absent fields are left unchanged.
+type PatchCirriculumRequest struct {
+	Title       *string `json:"title" binding:"omitempty,min=1"`
+	Week        *int    `json:"week" binding:"omitempty,min=1"`
+	Description *string `json:"description" binding:"omitempty,min=1"`
+	// ImageID is a UUID, or null to remove the image
+	ImageID json.RawMessage `json:"image_id" swaggertype:"string"`
+	Slug    *string         `json:"slug"`
+	// AvailableFrom is an RFC 3339 time, or null to release the entry at the
+	// start of its week
+	AvailableFrom json.RawMessage `json:"available_from" swaggertype:"string"`
+}
+
+// ReorderCirriculumRequest lists the new order of the entries in each week
+type ReorderCirriculumRequest struct {
+	// CohortID selects the cirriculum of a cohort; leave empty for the shared one
+	CohortID *uuid.UUID                   `json:"cohort_id"`
+	Weeks    []models.CirriculumWeekOrder `json:"weeks" binding:"required,min=1"`
 }
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/service"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -23,49 +27,101 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+		userID, role, err := parseAuthHeader(authHeader, secret)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		tokenStr := parts[1]
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
-			return
+// OptionalJWTAuth sets the user on the context when a valid token is sent,
+// and lets anonymous requests through otherwise
+func OptionalJWTAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if userID, role, err := parseAuthHeader(authHeader, secret); err == nil {
+				c.Set("user_id", userID)
+				c.Set("role", role)
+			}
 		}
+		c.Next()
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
-			c.Abort()
+// RequireRole rejects authenticated users that have none of the given roles.
+// It must run after JWTAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == "OPTIONS" {
+			c.Next()
 			return
 		}
 
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id not found in token"})
-			c.Abort()
-			return
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
 		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.Abort()
+	}
+}
 
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id in token"})
-			c.Abort()
-			return
+// actorFromContext returns the authenticated user, or nil for anonymous requests
+func actorFromContext(c *gin.Context) *service.Actor {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+	return &service.Actor{UserID: userID.(uuid.UUID), Role: c.GetString("role")}
+}
+
+func parseAuthHeader(authHeader, secret string) (uuid.UUID, string, error) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return uuid.Nil, "", errors.New("invalid authorization header format")
+	}
+
+	tokenStr := parts[1]
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return []byte(secret), nil
+	})
 
-		c.Set("user_id", userID)
-		c.Next()
+	if err != nil || !token.Valid {
+		return uuid.Nil, "", errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return uuid.Nil, "", errors.New("invalid token claims")
+	}
+
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, "", errors.New("user_id not found in token")
 	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, "", errors.New("invalid user_id in token")
+	}
+
+	// Tokens issued before roles existed carry no role claim
+	role, _ := claims["role"].(string)
+	if role == "" {
+		role = models.RoleStudent
+	}
+
+	return userID, role, nil
 }
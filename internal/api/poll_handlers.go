@@ -0,0 +1,222 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetPollHandler returns the poll of a news item
+// @Summary Get a news poll
+// @Description Returns the poll attached to a news item with the caller's vote. Results are included once the poll settings allow the caller to see them: always, after voting, or after the poll closes. The news author and admins always see them.
+// @Tags polls
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Poll "Poll"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll [get]
+func (s *Server) GetPollHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	poll, err := s.pollService.GetPoll(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch poll: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// GetPollResultsHandler returns the current results of a news poll
+// @Summary Get poll results
+// @Description Returns the live vote counts of the poll on a news item. Voters are listed for polls that are not anonymous. Answers 403 while the poll settings hide the results from the caller.
+// @Tags polls
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.PollResults "Results"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 403 {object} ErrorResponse "Results hidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll/results [get]
+func (s *Server) GetPollResultsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	results, err := s.pollService.GetResults(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch poll results: " + err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, results)
+}
+
+// CreatePollHandler attaches a poll to a news item
+// @Summary Create a news poll
+// @Description Adds a poll to a news item (author or admin only). A news item has at most one poll. Polls are anonymous and show results after voting unless configured otherwise.
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param poll body CreatePollRequest true "Poll"
+// @Security BearerAuth
+// @Success 201 {object} models.Poll "Poll created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "The news item already has a poll"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll [post]
+func (s *Server) CreatePollHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	poll, err := s.pollService.CreatePoll(id, service.PollInput{
+		Question:          req.Question,
+		Options:           req.Options,
+		Multiple:          req.Multiple,
+		Anonymous:         req.Anonymous,
+		ResultsVisibility: req.ResultsVisibility,
+		ClosesAt:          req.ClosesAt,
+	}, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to create poll: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, poll)
+}
+
+// DeletePollHandler removes the poll of a news item
+// @Summary Delete a news poll
+// @Description Removes the poll of a news item and all of its votes (author or admin only)
+// @Tags polls
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 204 "Poll deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll [delete]
+func (s *Server) DeletePollHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.pollService.DeletePoll(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete poll: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClosePollHandler closes the poll of a news item
+// @Summary Close a news poll
+// @Description Stops a poll from taking further votes before its closing time (author or admin only)
+// @Tags polls
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Poll "Closed poll"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll/close [post]
+func (s *Server) ClosePollHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	poll, err := s.pollService.ClosePoll(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to close poll: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// VotePollHandler casts a vote in a news poll
+// @Summary Vote in a news poll
+// @Description Casts the user's ballot in the poll of a published news item. Single choice polls take exactly one option. Each user votes once and cannot change their ballot.
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param vote body PollVoteRequest true "Chosen options"
+// @Security BearerAuth
+// @Success 200 {object} models.Poll "Poll with the user's vote"
+// @Failure 400 {object} ErrorResponse "Invalid request or poll closed"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Cohort archived"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Already voted"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/poll/votes [post]
+func (s *Server) VotePollHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PollVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	poll, err := s.pollService.Vote(id, req.OptionIDs, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to vote: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// CreatePollRequest represents the request body for a new poll
+type CreatePollRequest struct {
+	Question string   `json:"question" binding:"required"`
+	Options  []string `json:"options" binding:"required,min=2"`
+	Multiple bool     `json:"multiple"`
+	// Anonymous defaults to true
+	Anonymous         *bool      `json:"anonymous"`
+	ResultsVisibility string     `json:"results_visibility" binding:"omitempty,oneof=always after_vote after_close"`
+	ClosesAt          *time.Time `json:"closes_at"`
+}
+
+// PollVoteRequest represents the options chosen on a ballot
+type PollVoteRequest struct {
+	OptionIDs []uuid.UUID `json:"option_ids" binding:"required,min=1"`
+}
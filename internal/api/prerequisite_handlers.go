@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetPrerequisitesHandler replaces the prerequisites of a cirriculum entry
+// @Summary Set cirriculum prerequisites
+// @Description Replaces the entries to cover before a cirriculum entry (author or mentors only). Prerequisites must belong to the same cirriculum, be scheduled no later than the entry's week and not form a cycle. Send an empty list to clear them.
+// @Tags cirriculum
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param prerequisites body PrerequisitesRequest true "Prerequisite entry IDs"
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Cirriculum updated"
+// @Failure 400 {object} ErrorResponse "Invalid request, later prerequisite or cycle"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/prerequisites [put]
+func (s *Server) SetPrerequisitesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PrerequisitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	cirriculum, err := s.cirriculumService.SetPrerequisites(id, req.IDs, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update prerequisites: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirriculum)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+// GetCirriculumGraphHandler returns the prerequisite graph of the cirriculum
+// @Summary Get the cirriculum dependency graph
+// @Description Returns the cirriculum entries as nodes with an edge from each prerequisite to the entry requiring it, as JSON or, with format=dot, as a Graphviz DOT document grouped by week. Entries that are not released yet are only included for mentors and their author.
+// @Tags cirriculum
+// @Produce json
+// @Produce plain
+// @Param cohort query string false "Cohort ID; defaults to the cirriculum shared by every cohort"
+// @Param format query string false "Output format" Enums(json, dot)
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Security BearerAuth
+// @Success 200 {object} models.CirriculumGraph "Dependency graph"
+// @Failure 400 {object} ErrorResponse "Invalid cohort or format"
+// @Failure 404 {object} ErrorResponse "Cohort not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/graph [get]
+func (s *Server) GetCirriculumGraphHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid format"})
+		return
+	}
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	graph, err := s.cirriculumService.GetGraph(cohortID, actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum graph: " + err.Error()})
+		return
+	}
+
+	if format == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(service.CirriculumGraphDOT(graph)))
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// PrerequisitesRequest lists the prerequisites of a cirriculum entry
+type PrerequisitesRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
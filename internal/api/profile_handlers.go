@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UpdateProfileHandler changes the caller's public profile
+// @Summary Update my profile
+// @Description Sets the display name and avatar the current user is shown with as an author. Absent fields are left unchanged. An empty display_name falls back to the username. The avatar must be a public image uploaded by the user; send null to remove it.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param profile body PatchProfileRequest true "Fields to change"
+// @Security BearerAuth
+// @Success 200 {object} models.Author "Profile updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Avatar uploaded by someone else"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /me [patch]
+func (s *Server) UpdateProfileHandler(c *gin.Context) {
+	var req PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	patch := service.ProfilePatch{DisplayName: req.DisplayName}
+	if len(req.AvatarID) > 0 {
+		patch.SetAvatar = true
+		if string(req.AvatarID) != "null" {
+			var avatarID uuid.UUID
+			if err := json.Unmarshal(req.AvatarID, &avatarID); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid avatar_id"})
+				return
+			}
+			patch.AvatarID = &avatarID
+		}
+	}
+
+	profile, err := s.profileService.UpdateProfile(patch, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to update profile: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// PatchProfileRequest represents a partial update of the caller's profile
+type PatchProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	// AvatarID is the ID of an uploaded image, or null to remove the avatar
+	AvatarID json.RawMessage `json:"avatar_id" swaggertype:"string"`
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetProgressHandler records the caller's progress on a cirriculum entry
+// @Summary Mark progress on an entry
+// @Description Marks a released cirriculum entry as started or completed for the current user. Entries of a cohort can only be tracked by its members while the cohort is not archived.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param progress body ProgressRequest true "Progress"
+// @Security BearerAuth
+// @Success 200 {object} models.Progress "Progress saved"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/progress [put]
+func (s *Server) SetProgressHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	progress, err := s.progressService.SetProgress(id, req.Status, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to save progress: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// ClearProgressHandler resets the caller's progress on a cirriculum entry
+// @Summary Clear progress on an entry
+// @Description Resets a cirriculum entry to not started for the current user.
+// @Tags progress
+// @Param id path string true "Cirriculum ID"
+// @Security BearerAuth
+// @Success 204 "Progress cleared"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/progress [delete]
+func (s *Server) ClearProgressHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.progressService.ClearProgress(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to clear progress: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMyProgressHandler sums up the caller's progress through the cirriculum
+// @Summary Get my progress
+// @Description Returns the share of released cirriculum entries the current user has completed, overall and per week. Without a cohort the shared cirriculum is summed up.
+// @Tags progress
+// @Produce json
+// @Param cohort query string false "Cohort ID"
+// @Security BearerAuth
+// @Success 200 {object} models.ProgressSummary "Progress summary"
+// @Failure 400 {object} ErrorResponse "Invalid cohort"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /me/progress [get]
+func (s *Server) GetMyProgressHandler(c *gin.Context) {
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	summary, err := s.progressService.GetMyProgress(cohortID, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch progress: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetCohortProgressHandler returns the progress of every student of a cohort
+// @Summary Get cohort progress
+// @Description Returns the status of every student of a cohort on every entry of its cirriculum (mentors and admins only). Statuses line up with the entries. Students with the most overdue entries, then the lowest completion, come first.
+// @Tags progress
+// @Produce json
+// @Param id path string true "Cohort ID"
+// @Security BearerAuth
+// @Success 200 {object} models.CohortProgress "Progress matrix"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cohorts/{id}/progress [get]
+func (s *Server) GetCohortProgressHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	progress, err := s.progressService.GetCohortProgress(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cohort progress: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// ProgressRequest represents a user's progress on a cirriculum entry
+type ProgressRequest struct {
+	Status string `json:"status" binding:"required,oneof=started completed"`
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// revisionSource is the revision history of one kind of item
+type revisionSource interface {
+	ListRevisions(id uuid.UUID, actor *service.Actor) ([]*models.Revision, error)
+	GetRevision(id uuid.UUID, number int, actor *service.Actor) (*models.Revision, error)
+	DiffRevisions(id uuid.UUID, from, to int, actor *service.Actor) (*models.RevisionDiff, error)
+}
+
+// ListNewsRevisionsHandler lists the revisions of a news item
+// @Summary List news revisions
+// @Description Returns the edit history of a news item, newest first. Visible to the author and admins.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "News ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Revision "Revisions"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/revisions [get]
+func (s *Server) ListNewsRevisionsHandler(c *gin.Context) {
+	listRevisions(c, s.newsService)
+}
+
+// GetNewsRevisionHandler returns one revision of a news item
+// @Summary Get a news revision
+// @Description Returns a single revision of a news item with its full snapshot
+// @Tags revisions
+// @Produce json
+// @Param id path string true "News ID"
+// @Param number path int true "Revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.Revision "Revision"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/revisions/{number} [get]
+func (s *Server) GetNewsRevisionHandler(c *gin.Context) {
+	getRevision(c, s.newsService)
+}
+
+// DiffNewsRevisionsHandler compares two revisions of a news item
+// @Summary Diff news revisions
+// @Description Returns a unified text diff between two revisions of a news item
+// @Tags revisions
+// @Produce json
+// @Param id path string true "News ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.RevisionDiff "Diff"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/revisions/diff [get]
+func (s *Server) DiffNewsRevisionsHandler(c *gin.Context) {
+	diffRevisions(c, s.newsService)
+}
+
+// RevertNewsHandler restores a news item to an earlier revision
+// @Summary Revert a news item
+// @Description Restores the title, content, image and category of a news item from an earlier revision. The revert is recorded as a new revision; slug and status are unchanged.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "News ID"
+// @Param number path int true "Revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.News "Reverted news item"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/revisions/{number}/revert [post]
+func (s *Server) RevertNewsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	number, ok := parseRevisionNumber(c)
+	if !ok {
+		return
+	}
+
+	news, err := s.newsService.RevertNews(id, number, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to revert news: " + err.Error()})
+		return
+	}
+
+	includeNewsFields(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+// ListCirriculumRevisionsHandler lists the revisions of a cirriculum entry
+// @Summary List cirriculum revisions
+// @Description Returns the edit history of a cirriculum entry, newest first. Visible to the author and mentors.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Revision "Revisions"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/revisions [get]
+func (s *Server) ListCirriculumRevisionsHandler(c *gin.Context) {
+	listRevisions(c, s.cirriculumService)
+}
+
+// GetCirriculumRevisionHandler returns one revision of a cirriculum entry
+// @Summary Get a cirriculum revision
+// @Description Returns a single revision of a cirriculum entry with its full snapshot
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param number path int true "Revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.Revision "Revision"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/revisions/{number} [get]
+func (s *Server) GetCirriculumRevisionHandler(c *gin.Context) {
+	getRevision(c, s.cirriculumService)
+}
+
+// DiffCirriculumRevisionsHandler compares two revisions of a cirriculum entry
+// @Summary Diff cirriculum revisions
+// @Description Returns a unified text diff between two revisions of a cirriculum entry
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.RevisionDiff "Diff"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/revisions/diff [get]
+func (s *Server) DiffCirriculumRevisionsHandler(c *gin.Context) {
+	diffRevisions(c, s.cirriculumService)
+}
+
+// RevertCirriculumHandler restores a cirriculum entry to an earlier revision
+// @Summary Revert a cirriculum entry
+// @Description Restores a cirriculum entry from an earlier revision. The revert is recorded as a new revision; the slug is unchanged.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param number path int true "Revision number"
+// @Security BearerAuth
+// @Success 200 {object} models.Cirriculum "Reverted cirriculum entry"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/revisions/{number}/revert [post]
+func (s *Server) RevertCirriculumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	number, ok := parseRevisionNumber(c)
+	if !ok {
+		return
+	}
+
+	cirriculum, err := s.cirriculumService.RevertCirriculum(id, number, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to revert cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, cirriculum)
+	c.JSON(http.StatusOK, cirriculum)
+}
+
+func listRevisions(c *gin.Context, source revisionSource) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	revisions, err := source.ListRevisions(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch revisions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func getRevision(c *gin.Context, source revisionSource) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	number, ok := parseRevisionNumber(c)
+	if !ok {
+		return
+	}
+
+	revision, err := source.GetRevision(id, number, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch revision: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func diffRevisions(c *gin.Context, source revisionSource) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, ok := parseIntQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseIntQuery(c, "to")
+	if !ok {
+		return
+	}
+	if from < 1 || to < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from and to revision numbers are required"})
+		return
+	}
+
+	diff, err := source.DiffRevisions(id, from, to, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to diff revisions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// parseRevisionNumber reads the revision number path parameter, responding
+// with 400 when it is malformed
+func parseRevisionNumber(c *gin.Context) (int, bool) {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid revision number"})
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// translationTarget is an item type whose content can be translated
+type translationTarget interface {
+	SaveTranslation(id uuid.UUID, locale, title, content string, actor *service.Actor) (*models.Translation, error)
+	DeleteTranslation(id uuid.UUID, locale string, actor *service.Actor) error
+}
+
+// SaveNewsTranslationHandler adds or replaces a translation of a news item
+// @Summary Save a news translation
+// @Description Adds or replaces the title and content of a news item in a locale other than the default one. Content is Markdown.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "News ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Param translation body TranslationRequest true "Translation"
+// @Security BearerAuth
+// @Success 200 {object} models.Translation "Translation saved"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/translations/{locale} [put]
+func (s *Server) SaveNewsTranslationHandler(c *gin.Context) {
+	saveTranslation(c, s.newsService)
+}
+
+// DeleteNewsTranslationHandler removes a translation of a news item
+// @Summary Delete a news translation
+// @Description Removes the translation of a news item into a locale
+// @Tags translations
+// @Param id path string true "News ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Security BearerAuth
+// @Success 204 "Translation deleted"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /news/{id}/translations/{locale} [delete]
+func (s *Server) DeleteNewsTranslationHandler(c *gin.Context) {
+	deleteTranslation(c, s.newsService)
+}
+
+// SaveCirriculumTranslationHandler adds or replaces a translation of a cirriculum entry
+// @Summary Save a cirriculum translation
+// @Description Adds or replaces the title and description of a cirriculum entry in a locale other than the default one. Content is Markdown.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Cirriculum ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Param translation body TranslationRequest true "Translation"
+// @Security BearerAuth
+// @Success 200 {object} models.Translation "Translation saved"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/translations/{locale} [put]
+func (s *Server) SaveCirriculumTranslationHandler(c *gin.Context) {
+	saveTranslation(c, s.cirriculumService)
+}
+
+// DeleteCirriculumTranslationHandler removes a translation of a cirriculum entry
+// @Summary Delete a cirriculum translation
+// @Description Removes the translation of a cirriculum entry into a locale
+// @Tags translations
+// @Param id path string true "Cirriculum ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Security BearerAuth
+// @Success 204 "Translation deleted"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/{id}/translations/{locale} [delete]
+func (s *Server) DeleteCirriculumTranslationHandler(c *gin.Context) {
+	deleteTranslation(c, s.cirriculumService)
+}
+
+func saveTranslation(c *gin.Context, target translationTarget) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req TranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	translation, err := target.SaveTranslation(id, c.Param("locale"), req.Title, req.Content, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to save translation: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+func deleteTranslation(c *gin.Context, target translationTarget) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := target.DeleteTranslation(id, c.Param("locale"), actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete translation: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// requestLocale picks the locale to render content in from the lang query
+// parameter or the Accept-Language header. Items without a translation are
+// still served in the default locale, so each one reports its own locale.
+func (s *Server) requestLocale(c *gin.Context) string {
+	c.Header("Vary", "Accept-Language")
+	return s.locales.Match(c.Query("lang"), c.GetHeader("Accept-Language"))
+}
+
+// withQuery appends the request's query string to path
+func withQuery(path string, c *gin.Context) string {
+	if c.Request.URL.RawQuery == "" {
+		return path
+	}
+	return path + "?" + c.Request.URL.RawQuery
+}
+
+// TranslationRequest represents the request body for a translation. Content is Markdown.
+type TranslationRequest struct {
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrashHandler lists deleted content
+// @Summary List the trash
+// @Description Returns deleted news, cirriculum entries and uploads, most recently deleted first. Items are purged for good after the retention period. Admins only.
+// @Tags trash
+// @Produce json
+// @Param type query string false "Only list items of this type" Enums(news, cirriculum, asset)
+// @Security BearerAuth
+// @Success 200 {array} models.TrashItem "Deleted items"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /trash [get]
+func (s *Server) ListTrashHandler(c *gin.Context) {
+	items, err := s.trashService.ListTrash(c.Query("type"), actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch trash: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreTrashHandler takes an item out of the trash
+// @Summary Restore a deleted item
+// @Description Restores a deleted news item, cirriculum entry or upload. Admins only.
+// @Tags trash
+// @Param type path string true "Item type" Enums(news, cirriculum, asset)
+// @Param id path string true "Item ID"
+// @Security BearerAuth
+// @Success 204 "Item restored"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 404 {object} ErrorResponse "Not in the trash"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /trash/{type}/{id}/restore [post]
+func (s *Server) RestoreTrashHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.trashService.Restore(c.Param("type"), id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to restore item: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
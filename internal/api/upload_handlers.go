@@ -0,0 +1,212 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadFileHandler stores an uploaded image
+// @Summary Upload an image
+// @Description Uploads an image (JPEG, PNG, GIF or WebP, detected from content) and returns its asset ID. Private assets are served through signed URLs only.
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image file"
+// @Param visibility formData string false "public (default) or private"
+// @Security BearerAuth
+// @Success 201 {object} models.Asset "Asset created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 415 {object} ErrorResponse "Unsupported media type"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /uploads [post]
+func (s *Server) UploadFileHandler(c *gin.Context) {
+	fileHeader, ok := formFile(c, "file", s.uploadMaxSize)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	actor := actorFromContext(c)
+	asset, err := s.uploadService.Upload(c.Request.Context(), fileHeader.Filename, file, c.PostForm("visibility"), actor.UserID)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to upload file: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, asset)
+}
+
+// GetAssetHandler returns asset metadata
+// @Summary Get asset metadata
+// @Description Returns metadata and a fetchable URL for an asset. Private assets get a freshly signed URL and are only visible to their owner and mentors.
+// @Tags uploads
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Asset "Asset"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /uploads/{id}/info [get]
+func (s *Server) GetAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	asset, err := s.uploadService.GetAsset(id, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch asset: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// DeleteAssetHandler moves an asset to the trash
+// @Summary Delete an asset
+// @Description Moves an uploaded file to the trash (owner or admin only). Content using it stops showing the image; the file is removed when the trash is purged.
+// @Tags uploads
+// @Param id path string true "Asset ID"
+// @Security BearerAuth
+// @Success 204 "Asset deleted"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "File was submitted to an assignment"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /uploads/{id} [delete]
+func (s *Server) DeleteAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.uploadService.DeleteAsset(id, actorFromContext(c)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to delete asset: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ServeFileHandler streams an uploaded file
+// @Summary Download an uploaded file
+// @Description Serves the file content. Documents other than images are sent as downloads. Private assets require the expires and signature parameters from their signed URL.
+// @Tags uploads
+// @Produce octet-stream
+// @Param id path string true "Asset ID"
+// @Param expires query string false "Signature expiry (Unix time)"
+// @Param signature query string false "URL signature"
+// @Success 200 {file} file "File content"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 403 {object} ErrorResponse "Invalid or expired signature"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /uploads/{id} [get]
+func (s *Server) ServeFileHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	asset, body, err := s.uploadService.Open(c.Request.Context(), id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch file: " + err.Error()})
+		return
+	}
+	defer body.Close()
+
+	cacheControl := "public, max-age=31536000, immutable"
+	if asset.Visibility != models.AssetVisibilityPublic {
+		cacheControl = "private, no-store"
+	}
+	headers := map[string]string{
+		"Cache-Control":          cacheControl,
+		"X-Content-Type-Options": "nosniff",
+	}
+	// Attached documents download under their original name instead of
+	// opening in the API's origin
+	if !strings.HasPrefix(asset.ContentType, "image/") {
+		headers["Content-Disposition"] = mime.FormatMediaType("attachment", map[string]string{"filename": asset.Filename})
+	}
+	c.DataFromReader(http.StatusOK, asset.Size, asset.ContentType, body, headers)
+}
+
+// ServeVariantHandler streams a generated image variant
+// @Summary Download an image variant
+// @Description Serves a resized variant such as thumbnail.jpeg or medium.webp. Variants are generated in the background after upload and listed on the asset.
+// @Tags uploads
+// @Produce octet-stream
+// @Param id path string true "Asset ID"
+// @Param variant path string true "Variant name and format, e.g. medium.webp"
+// @Param expires query string false "Signature expiry (Unix time)"
+// @Param signature query string false "URL signature"
+// @Success 200 {file} file "File content"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 403 {object} ErrorResponse "Invalid or expired signature"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /uploads/{id}/variants/{variant} [get]
+func (s *Server) ServeVariantHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	variant, body, err := s.uploadService.OpenVariant(c.Request.Context(), id, c.Param("variant"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch file: " + err.Error()})
+		return
+	}
+	defer body.Close()
+
+	cacheControl := "public, max-age=31536000, immutable"
+	if c.Query("signature") != "" {
+		cacheControl = "private, no-store"
+	}
+	c.DataFromReader(http.StatusOK, variant.Size, variant.ContentType, body, map[string]string{
+		"Cache-Control":          cacheControl,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// multipartOverhead is the room left in an upload request for the multipart
+// boundaries, headers and other form fields next to the file
+const multipartOverhead = 1 << 20
+
+// formFile reads an uploaded file from a multipart form, responding with 400
+// when it is missing and 413 when the request is larger than maxSize allows.
+// The body is limited before it is parsed, so oversized uploads are cut off
+// instead of being buffered to disk.
+func formFile(c *gin.Context, name string, maxSize int64) (*multipart.FileHeader, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	fileHeader, err := c.FormFile(name)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("File too large: maximum size is %d bytes", maxSize)})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File is required"})
+		return nil, false
+	}
+	return fileHeader, true
+}
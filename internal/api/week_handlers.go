@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCirriculumWeeksHandler returns the cirriculum grouped by week
+// @Summary Get cirriculum weeks
+// @Description Returns every week of the program with its title, theme and entries in order, including weeks without entries. Entries that are not released yet are only listed for mentors and their author.
+// @Tags cirriculum
+// @Produce json
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Param cohort query string false "Cohort ID; defaults to the cirriculum shared by every cohort"
+// @Security BearerAuth
+// @Success 200 {array} models.CirriculumWeek "Weeks"
+// @Failure 400 {object} ErrorResponse "Invalid cohort"
+// @Failure 404 {object} ErrorResponse "Cohort not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/weeks [get]
+func (s *Server) GetCirriculumWeeksHandler(c *gin.Context) {
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	weeks, err := s.cirriculumService.GetWeeks(cohortID, actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
+		return
+	}
+
+	for _, week := range weeks {
+		includeCirriculumFields(c, week.Entries...)
+	}
+	c.JSON(http.StatusOK, weeks)
+}
+
+// GetCirriculumWeekHandler returns one week of the cirriculum
+// @Summary Get a cirriculum week
+// @Description Returns a week of the program with its title, theme and entries in order. Entries that are not released yet are only listed for mentors and their author.
+// @Tags cirriculum
+// @Produce json
+// @Param week path int true "Week number"
+// @Param lang query string false "Locale, e.g. hr or en; defaults to Accept-Language"
+// @Param include query string false "Comma-separated optional fields to embed" Enums(author)
+// @Param cohort query string false "Cohort ID; defaults to the cirriculum shared by every cohort"
+// @Security BearerAuth
+// @Success 200 {object} models.CirriculumWeek "Week"
+// @Failure 400 {object} ErrorResponse "Invalid week or cohort"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/weeks/{week} [get]
+func (s *Server) GetCirriculumWeekHandler(c *gin.Context) {
+	n, ok := parseWeekParam(c)
+	if !ok {
+		return
+	}
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	week, err := s.cirriculumService.GetWeek(cohortID, n, actorFromContext(c), s.requestLocale(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to fetch cirriculum: " + err.Error()})
+		return
+	}
+
+	includeCirriculumFields(c, week.Entries...)
+	c.JSON(http.StatusOK, week)
+}
+
+// SaveCirriculumWeekHandler sets the title and theme of a week
+// @Summary Set a week's title and theme
+// @Description Sets the title and theme shown for a week of the program. Mentors and admins only.
+// @Tags cirriculum
+// @Accept json
+// @Produce json
+// @Param week path int true "Week number"
+// @Param cohort query string false "Cohort ID; defaults to the cirriculum shared by every cohort"
+// @Param details body CirriculumWeekRequest true "Week details"
+// @Security BearerAuth
+// @Success 200 {object} models.CirriculumWeek "Week"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden or cohort archived"
+// @Failure 500 {object} ErrorResponse "Server error"
+// @Router /cirriculum/weeks/{week} [put]
+func (s *Server) SaveCirriculumWeekHandler(c *gin.Context) {
+	n, ok := parseWeekParam(c)
+	if !ok {
+		return
+	}
+	cohortID, ok := parseCohortQuery(c)
+	if !ok {
+		return
+	}
+
+	var req CirriculumWeekRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	week, err := s.cirriculumService.SaveWeek(cohortID, n, req.Title, req.Theme, actorFromContext(c))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: "Failed to save week: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, week)
+}
+
+// parseWeekParam reads the week number path parameter, responding with 400
+// when it is malformed
+func parseWeekParam(c *gin.Context) (int, bool) {
+	n, err := strconv.Atoi(c.Param("week"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid week"})
+		return 0, false
+	}
+	return n, true
+}
+
+// CirriculumWeekRequest represents the title and theme of a week. Empty
+// values clear them.
+type CirriculumWeekRequest struct {
+	Title string `json:"title"`
+	Theme string `json:"theme"`
+}
@@ -0,0 +1,216 @@
+// Package feed renders RSS 2.0 and Atom 1.0 documents
+package feed
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// Feed is the format-independent description of a feed
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	// Link is the page the feed belongs to, SelfLink the feed's own URL
+	Link     string
+	SelfLink string
+	Language string
+	Updated  time.Time
+	Items    []Item
+}
+
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string // HTML
+	Category  string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+	Enclosure *Enclosure
+}
+
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Category    string        `xml:"category,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// RSS renders the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Language:    f.Language,
+			AtomLink:    atomLink{Href: f.SelfLink, Rel: "self", Type: "application/rss+xml"},
+			Items:       make([]rssItem, 0, len(f.Items)),
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: "false", Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Description: item.Content,
+			Category:    item.Category,
+		}
+		if item.Enclosure != nil {
+			ri.Enclosure = &rssEnclosure{
+				URL:    item.Enclosure.URL,
+				Type:   item.Enclosure.Type,
+				Length: strconv.FormatInt(item.Enclosure.Length, 10),
+			}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+	return marshal(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Lang    string      `xml:"xml:lang,attr,omitempty"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []atomLink    `xml:"link"`
+	Author    *atomAuthor   `xml:"author"`
+	Category  *atomCategory `xml:"category"`
+	Summary   string        `xml:"summary,omitempty"`
+	Content   atomContent   `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom renders the feed as Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Lang:    f.Language,
+		ID:      f.SelfLink,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: make([]atomEntry, 0, len(f.Items)),
+	}
+	if f.Author != "" {
+		doc.Author = &atomAuthor{Name: f.Author}
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        "urn:uuid:" + item.ID,
+			Title:     item.Title,
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Summary:   item.Summary,
+			Content:   atomContent{Type: "html", Value: item.Content},
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		if item.Category != "" {
+			entry.Category = &atomCategory{Term: item.Category}
+		}
+		if item.Enclosure != nil {
+			entry.Links = append(entry.Links, atomLink{
+				Href:   item.Enclosure.URL,
+				Rel:    "enclosure",
+				Type:   item.Enclosure.Type,
+				Length: strconv.FormatInt(item.Enclosure.Length, 10),
+			})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshal(doc)
+}
+
+func marshal(doc any) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,147 @@
+// Package imaging decodes, transforms and encodes uploaded images
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const jpegQuality = 82
+
+// MaxPixels bounds the width × height of images that are decoded, as a small
+// file can declare dimensions that would take gigabytes of memory
+const MaxPixels = 40_000_000
+
+// ErrTooManyPixels is returned for images larger than MaxPixels
+var ErrTooManyPixels = errors.New("image dimensions too large")
+
+// CheckDimensions reads the dimensions declared in an image's header, without
+// decoding it, and rejects images larger than MaxPixels
+func CheckDimensions(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return fmt.Errorf("%w: %dx%d", ErrTooManyPixels, config.Width, config.Height)
+	}
+	return nil
+}
+
+// Decode decodes an image and applies its EXIF orientation so the result is
+// upright. Images larger than MaxPixels are rejected before decoding.
+func Decode(data []byte) (image.Image, error) {
+	if err := CheckDimensions(data); err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format == "jpeg" {
+		img = orient(img, Orientation(data))
+	}
+	return img, nil
+}
+
+// Resize scales img down to maxWidth, keeping the aspect ratio. Images that
+// are already narrower are returned unchanged.
+func Resize(img image.Image, maxWidth int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxWidth {
+		return img
+	}
+	height := b.Dy() * maxWidth / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, maxWidth, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// Blurhash computes a compact placeholder string for img
+func Blurhash(img image.Image) (string, error) {
+	// The hash only captures a few colour components, so a small copy is enough
+	return blurhash.Encode(4, 3, Resize(img, 32))
+}
+
+// Opaque reports whether every pixel of img is fully opaque
+func Opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// Encode writes img in the given format ("jpeg", "png", "gif" or "webp").
+// WebP output is lossless as there is no pure Go lossy encoder.
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// orient transforms img according to an EXIF orientation value
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
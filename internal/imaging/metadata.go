@@ -0,0 +1,234 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed image")
+
+// StripMetadata removes EXIF, XMP, IPTC and text metadata from JPEG, PNG and
+// WebP files without re-encoding them. The JPEG orientation is kept in a
+// minimal EXIF block so the image still displays the right way up. Other
+// formats are returned unchanged.
+func StripMetadata(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return data, nil
+	}
+}
+
+// Orientation returns the EXIF orientation (1-8) of a JPEG, or 1 when none is set
+func Orientation(data []byte) int {
+	orientation := 1
+	walkJPEG(data, func(marker byte, segment []byte) {
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			if o := exifOrientation(segment[len(exifHeader):]); o >= 1 && o <= 8 {
+				orientation = o
+			}
+		}
+	})
+	return orientation
+}
+
+var exifHeader = []byte("Exif\x00\x00")
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errMalformed
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	// The orientation block goes right after JFIF, which must come first
+	var exif []byte
+	if o := Orientation(data); o != 1 {
+		exif = orientationExif(o)
+	}
+	rest, err := walkJPEG(data, func(marker byte, segment []byte) {
+		if exif != nil && marker != 0xE0 {
+			writeJPEGSegment(out, 0xE1, exif)
+			exif = nil
+		}
+		if keepJPEGSegment(marker, segment) {
+			writeJPEGSegment(out, marker, segment)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if exif != nil {
+		writeJPEGSegment(out, 0xE1, exif)
+	}
+	out.Write(rest)
+	return out.Bytes(), nil
+}
+
+// walkJPEG calls fn for every marker segment before the image data and returns
+// the remaining bytes starting at the start-of-scan marker
+func walkJPEG(data []byte, fn func(marker byte, segment []byte)) ([]byte, error) {
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, errMalformed
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			return data[i:], nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, errMalformed
+		}
+		fn(marker, data[i+4:i+2+length])
+		i += 2 + length
+	}
+	return nil, errMalformed
+}
+
+// keepJPEGSegment keeps everything needed to decode and colour-manage the
+// image: JFIF (APP0), ICC profiles (APP2) and Adobe colour transforms (APP14)
+func keepJPEGSegment(marker byte, segment []byte) bool {
+	switch {
+	case marker == 0xE0, marker == 0xEE:
+		return true
+	case marker == 0xE2:
+		return bytes.HasPrefix(segment, []byte("ICC_PROFILE\x00"))
+	case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+		return false
+	default:
+		return true
+	}
+}
+
+func writeJPEGSegment(out *bytes.Buffer, marker byte, segment []byte) {
+	out.Write([]byte{0xFF, marker})
+	binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// orientationExif builds an EXIF block holding only the orientation tag
+func orientationExif(orientation int) []byte {
+	b := bytes.NewBuffer(nil)
+	b.Write(exifHeader)
+	b.WriteString("MM\x00\x2A")
+	binary.Write(b, binary.BigEndian, uint32(8))      // IFD0 offset
+	binary.Write(b, binary.BigEndian, uint16(1))      // entry count
+	binary.Write(b, binary.BigEndian, uint16(0x0112)) // orientation tag
+	binary.Write(b, binary.BigEndian, uint16(3))      // SHORT
+	binary.Write(b, binary.BigEndian, uint32(1))      // count
+	binary.Write(b, binary.BigEndian, uint16(orientation))
+	binary.Write(b, binary.BigEndian, uint16(0))
+	binary.Write(b, binary.BigEndian, uint32(0)) // no next IFD
+	return b.Bytes()
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformed
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformed
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformed
+	}
+
+	body := bytes.NewBuffer(make([]byte, 0, len(data)))
+	body.WriteString("WEBP")
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errMalformed
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, errMalformed
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // clear EXIF and XMP flags
+			}
+			body.Write(chunk)
+		default:
+			body.Write(data[i:end])
+		}
+		i = end
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, body.Len()+8))
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
@@ -0,0 +1,301 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/webp"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, color.RGBA{uint8(x * 30), uint8(y * 60), 90, 255})
+		}
+	}
+	return img
+}
+
+func jpegSegment(marker byte, segment []byte) []byte {
+	var b bytes.Buffer
+	writeJPEGSegment(&b, marker, segment)
+	return b.Bytes()
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.WriteString(kind)
+	b.Write(data)
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(kind), data...)))
+	return b.Bytes()
+}
+
+func webpChunk(fourCC string, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(fourCC)
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	if len(data)%2 == 1 {
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+func riff(chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(len(body)+4))
+	b.WriteString("WEBP")
+	b.Write(body)
+	return b.Bytes()
+}
+
+// testJPEG returns a JPEG carrying an EXIF orientation, XMP, a comment and an
+// ICC profile
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+
+	// The full EXIF block also carries a camera make that must not survive
+	exif := orientationExif(6)
+	exif = append(exif, []byte("Canon EOS")...)
+	var out bytes.Buffer
+	out.Write(data[:2])
+	out.Write(jpegSegment(0xE1, exif))
+	out.Write(jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")))
+	out.Write(jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile")))
+	out.Write(jpegSegment(0xFE, []byte("secret comment")))
+	out.Write(data[2:])
+	return out.Bytes()
+}
+
+// testPNG returns a PNG carrying text, EXIF and time chunks and an ICC profile
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+
+	// IHDR is always the first chunk and 25 bytes long
+	ihdrEnd := len(pngSignature) + 25
+	var out bytes.Buffer
+	out.Write(data[:ihdrEnd])
+	out.Write(pngChunk("iCCP", []byte("icc\x00\x00profile")))
+	out.Write(pngChunk("tEXt", []byte("Author\x00secret")))
+	out.Write(pngChunk("eXIf", []byte("MM\x00\x2Asecret")))
+	out.Write(pngChunk("tIME", []byte{0x07, 0xEA, 10, 18, 12, 0, 0}))
+	out.Write(data[ihdrEnd:])
+	return out.Bytes()
+}
+
+// testWebP returns an extended WebP carrying EXIF, XMP and an ICC profile
+func testWebP(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := nativewebp.Encode(&encoded, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+	vp8l := bytes.Index(data, []byte("VP8L"))
+	if vp8l < 0 {
+		t.Fatal("encoded WebP has no VP8L chunk")
+	}
+
+	// ICC, XMP and EXIF flags; canvas 8x4
+	vp8x := []byte{0x20 | 0x08 | 0x04, 0, 0, 0, 7, 0, 0, 3, 0, 0}
+	return riff(
+		webpChunk("VP8X", vp8x),
+		webpChunk("ICCP", []byte("profile")),
+		webpChunk("EXIF", []byte("MM\x00\x2Asecret")),
+		webpChunk("XMP ", []byte("<x:xmpmeta>secret</x:xmpmeta>")),
+		data[vp8l:],
+	)
+}
+
+func TestStripMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        func(t *testing.T) []byte
+		decode      func(data []byte) (image.Image, error)
+		kept        []string
+	}{
+		{
+			name:        "jpeg",
+			contentType: "image/jpeg",
+			data:        testJPEG,
+			decode:      func(data []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(data)) },
+			kept:        []string{"ICC_PROFILE"},
+		},
+		{
+			name:        "png",
+			contentType: "image/png",
+			data:        testPNG,
+			decode:      func(data []byte) (image.Image, error) { return png.Decode(bytes.NewReader(data)) },
+			kept:        []string{"iCCP"},
+		},
+		{
+			name:        "webp",
+			contentType: "image/webp",
+			data:        testWebP,
+			decode:      func(data []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(data)) },
+			kept:        []string{"ICCP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := StripMetadata(tt.data(t), tt.contentType)
+			if err != nil {
+				t.Fatalf("StripMetadata() error = %v", err)
+			}
+			img, err := tt.decode(out)
+			if err != nil {
+				t.Fatalf("stripped image does not decode: %v", err)
+			}
+			if got := img.Bounds(); got != image.Rect(0, 0, 8, 4) {
+				t.Errorf("bounds = %v, want 8x4", got)
+			}
+			for _, removed := range []string{"secret", "Canon", "xmpmeta", "tIME"} {
+				if bytes.Contains(out, []byte(removed)) {
+					t.Errorf("output still contains %q", removed)
+				}
+			}
+			for _, kept := range tt.kept {
+				if !bytes.Contains(out, []byte(kept)) {
+					t.Errorf("output lost %q", kept)
+				}
+			}
+		})
+	}
+}
+
+func TestStripJPEGKeepsOrientation(t *testing.T) {
+	out, err := StripMetadata(testJPEG(t), "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Orientation(out); got != 6 {
+		t.Errorf("Orientation() = %d, want 6", got)
+	}
+	// The minimal orientation block replaces the original EXIF block
+	if n := bytes.Count(out, exifHeader); n != 1 {
+		t.Errorf("output has %d EXIF blocks, want 1", n)
+	}
+}
+
+func TestStripWebPClearsMetadataFlags(t *testing.T) {
+	out, err := StripMetadata(testWebP(t), "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags := out[20]
+	if flags&(0x08|0x04) != 0 {
+		t.Errorf("EXIF/XMP flags still set: %#x", flags)
+	}
+	if flags&0x20 == 0 {
+		t.Errorf("ICC flag cleared: %#x", flags)
+	}
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(out)-8)
+	}
+}
+
+func TestStripMetadataMalformed(t *testing.T) {
+	jpegData := testJPEG(t)
+	pngData := testPNG(t)
+	webpData := testWebP(t)
+
+	// Offsets of the first segment or chunk length after the header
+	oversizedJPEG := append([]byte(nil), jpegData...)
+	binary.BigEndian.PutUint16(oversizedJPEG[4:], 0xFFFF)
+	shortJPEG := append([]byte(nil), jpegData...)
+	binary.BigEndian.PutUint16(shortJPEG[4:], 1)
+	oversizedPNG := append([]byte(nil), pngData...)
+	binary.BigEndian.PutUint32(oversizedPNG[len(pngSignature):], 0xFFFFFFFF)
+	oversizedWebP := append([]byte(nil), webpData...)
+	binary.LittleEndian.PutUint32(oversizedWebP[16:], 0xFFFFFFFF)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{"jpeg without signature", "image/jpeg", []byte("not a jpeg")},
+		{"jpeg truncated in header", "image/jpeg", jpegData[:3]},
+		{"jpeg truncated in segment", "image/jpeg", jpegData[:20]},
+		{"jpeg without scan", "image/jpeg", jpegData[:2+len(jpegSegment(0xE1, orientationExif(6)))]},
+		{"jpeg oversized segment", "image/jpeg", oversizedJPEG},
+		{"jpeg segment length below 2", "image/jpeg", shortJPEG},
+		{"jpeg bad marker", "image/jpeg", []byte{0xFF, 0xD8, 0x00, 0xE1, 0x00, 0x04}},
+		{"png without signature", "image/png", []byte("not a png")},
+		{"png truncated chunk header", "image/png", pngData[:len(pngSignature)+5]},
+		{"png truncated chunk", "image/png", pngData[:len(pngSignature)+20]},
+		{"png oversized chunk", "image/png", oversizedPNG},
+		{"webp without header", "image/webp", []byte("RIFF")},
+		{"webp wrong form type", "image/webp", append([]byte("RIFF\x00\x00\x00\x00WAVE"), webpData[12:]...)},
+		{"webp truncated chunk header", "image/webp", webpData[:15]},
+		{"webp truncated chunk", "image/webp", webpData[:25]},
+		{"webp oversized chunk", "image/webp", oversizedWebP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := StripMetadata(tt.data, tt.contentType)
+			if !errors.Is(err, errMalformed) {
+				t.Errorf("StripMetadata() = %d bytes, %v, want errMalformed", len(out), err)
+			}
+		})
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	jpegWith := func(exif []byte) []byte {
+		data := []byte{0xFF, 0xD8}
+		data = append(data, jpegSegment(0xE1, exif)...)
+		return append(data, 0xFF, 0xDA, 0x00, 0x02)
+	}
+	littleEndian := []byte("Exif\x00\x00II\x2A\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x03\x00\x00\x00")
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"big endian", jpegWith(orientationExif(8)), 8},
+		{"little endian", jpegWith(littleEndian), 3},
+		{"no exif", jpegWith([]byte("http://ns.adobe.com/xap/1.0/\x00")), 1},
+		{"out of range value", jpegWith(orientationExif(9)), 1},
+		{"unknown byte order", jpegWith([]byte("Exif\x00\x00XX\x00\x2A\x00\x00\x00\x08")), 1},
+		{"truncated tiff header", jpegWith([]byte("Exif\x00\x00MM\x00")), 1},
+		{"ifd offset past end", jpegWith([]byte("Exif\x00\x00MM\x00\x2A\xFF\xFF\xFF\xFF")), 1},
+		{"entry count past end", jpegWith([]byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\xFF\xFF")), 1},
+		{"truncated jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}, 1},
+		{"not a jpeg", []byte("not a jpeg"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Orientation(tt.data); got != tt.want {
+				t.Errorf("Orientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Package locale picks the content language of a request
+package locale
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Set is the list of locales content can be translated into. Content is
+// authored in the default locale; the others are translations.
+type Set struct {
+	Default   string
+	Supported []string
+	matcher   language.Matcher
+}
+
+// NewSet builds a Set. The default locale is always supported and is the
+// fallback when nothing else matches.
+func NewSet(defaultLocale string, supported []string) *Set {
+	s := &Set{Default: normalize(defaultLocale)}
+	s.Supported = append(s.Supported, s.Default)
+	for _, l := range supported {
+		if l = normalize(l); l != "" && !s.Supports(l) {
+			s.Supported = append(s.Supported, l)
+		}
+	}
+
+	tags := make([]language.Tag, len(s.Supported))
+	for i, l := range s.Supported {
+		tags[i] = language.Make(l)
+	}
+	s.matcher = language.NewMatcher(tags)
+	return s
+}
+
+// Supports reports whether l is one of the set's locales
+func (s *Set) Supports(l string) bool {
+	for _, supported := range s.Supported {
+		if supported == l {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the locale to serve: the explicitly requested one when
+// supported, otherwise the best match for an Accept-Language header, falling
+// back to the default
+func (s *Set) Match(requested, acceptLanguage string) string {
+	if l := normalize(requested); s.Supports(l) {
+		return l
+	}
+	if acceptLanguage == "" {
+		return s.Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return s.Default
+	}
+	_, index, confidence := s.matcher.Match(tags...)
+	if confidence == language.No {
+		return s.Default
+	}
+	return s.Supported[index]
+}
+
+func normalize(l string) string {
+	return strings.ToLower(strings.TrimSpace(l))
+}
@@ -0,0 +1,65 @@
+// Package markdown renders user-authored Markdown into HTML that is safe to
+// embed in the frontend
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// ExcerptLength is the maximum length of generated excerpts, in characters
+const ExcerptLength = 200
+
+var (
+	// GFM adds tables, strikethrough, autolinks and task lists. Raw HTML in
+	// the source is omitted by goldmark since unsafe rendering is not enabled.
+	renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	policy     = newPolicy()
+	textPolicy = bluemonday.StrictPolicy()
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// Keep the language hint of fenced code blocks for syntax highlighting
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	// Task list checkboxes
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// ToHTML renders Markdown source to sanitized HTML
+func ToHTML(source string) string {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		// Convert only fails on writer errors, which bytes.Buffer never returns
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return string(policy.SanitizeBytes(buf.Bytes()))
+}
+
+// Excerpt returns the plain text of rendered HTML, shortened to at most
+// maxLen characters on a word boundary
+func Excerpt(renderedHTML string, maxLen int) string {
+	text := html.UnescapeString(textPolicy.Sanitize(renderedHTML))
+	text = strings.TrimSpace(whitespace.ReplaceAllString(text, " "))
+
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndex(cut, " "); i > maxLen/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
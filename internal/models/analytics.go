@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyViews is the number of views a news item got on one day (UTC)
+type DailyViews struct {
+	NewsID uuid.UUID `json:"-"`
+	Date   time.Time `json:"date" format:"date"`
+	Views  int64     `json:"views"`
+}
+
+// NewsViewStats is the view history of one news item over a period
+type NewsViewStats struct {
+	NewsID uuid.UUID     `json:"news_id"`
+	From   time.Time     `json:"from" format:"date"`
+	To     time.Time     `json:"to" format:"date"`
+	Total  int64         `json:"total"`
+	Daily  []*DailyViews `json:"daily"`
+}
+
+// NewsViews is the number of views of a news item over a period
+type NewsViews struct {
+	NewsID uuid.UUID `json:"news_id"`
+	Title  string    `json:"title"`
+	Slug   string    `json:"slug"`
+	Views  int64     `json:"views"`
+}
+
+// TopNews ranks the most viewed news items of a period
+type TopNews struct {
+	From time.Time    `json:"from" format:"date"`
+	To   time.Time    `json:"to" format:"date"`
+	News []*NewsViews `json:"news"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AssetVisibilityPublic  = "public"
+	AssetVisibilityPrivate = "private"
+)
+
+const (
+	AssetProcessingPending    = "pending"
+	AssetProcessingProcessing = "processing"
+	AssetProcessingDone       = "done"
+	AssetProcessingFailed     = "failed"
+)
+
+// Asset is an uploaded file kept in the configured storage backend
+type Asset struct {
+	ID               uuid.UUID      `json:"id"`
+	StorageKey       string         `json:"-"`
+	Filename         string         `json:"filename"`
+	ContentType      string         `json:"content_type"`
+	Size             int64          `json:"size"`
+	Visibility       string         `json:"visibility"`
+	URL              string         `json:"url"`
+	Width            int            `json:"width,omitempty"`
+	Height           int            `json:"height,omitempty"`
+	Blurhash         string         `json:"blurhash,omitempty"`
+	ProcessingStatus string         `json:"processing_status"`
+	Variants         []AssetVariant `json:"variants"`
+	UserID           uuid.UUID      `json:"user_id"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+// AssetVariant is a resized and re-encoded copy of an image asset
+type AssetVariant struct {
+	AssetID     uuid.UUID `json:"-"`
+	Name        string    `json:"name"`
+	Format      string    `json:"format"`
+	StorageKey  string    `json:"-"`
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// LatePolicyReject refuses submissions after the due date
+	LatePolicyReject = "reject"
+	// LatePolicyAccept takes late submissions and flags them
+	LatePolicyAccept = "accept"
+	// LatePolicyPenalty takes late submissions and deducts LatePenalty
+	// percent of the points for every day they are late
+	LatePolicyPenalty = "penalty"
+)
+
+const (
+	SubmissionTypeFile = "file"
+	SubmissionTypeLink = "link"
+)
+
+// Assignment is homework set for a week of a cohort
+type Assignment struct {
+	ID              uuid.UUID `json:"id"`
+	CohortID        uuid.UUID `json:"cohort_id"`
+	Week            int       `json:"week"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description_markdown"`
+	DescriptionHTML string    `json:"description_html"`
+	DueAt           time.Time `json:"due_at"`
+	MaxPoints       int       `json:"max_points"`
+	// AllowedTypes lists the file extensions students may submit; empty when
+	// only links are accepted
+	AllowedTypes []string `json:"allowed_types" example:"pdf,zip"`
+	// AllowLinks accepts a repository link instead of a file
+	AllowLinks bool   `json:"allow_links"`
+	LatePolicy string `json:"late_policy" enums:"reject,accept,penalty"`
+	// LatePenalty is the percentage of points deducted per day late under
+	// the penalty policy
+	LatePenalty int `json:"late_penalty"`
+	// Weight sets how much the assignment counts towards the gradebook
+	// total; 0 leaves it out
+	Weight int `json:"weight"`
+	// Rubric lists the criteria the assignment is graded by, in order
+	Rubric []*RubricCriterion `json:"rubric"`
+	// GradesReleasedAt is when students were shown their grades; nil while
+	// they are hidden
+	GradesReleasedAt *time.Time `json:"grades_released_at"`
+	// OpensAt is the start of the assignment's week; students see it from
+	// then on
+	OpensAt   time.Time `json:"opens_at"`
+	Open      bool      `json:"open"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Submission is one attempt of a student at an assignment
+type Submission struct {
+	ID           uuid.UUID `json:"id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	// Attempt counts the student's submissions to the assignment, from 1
+	Attempt int    `json:"attempt"`
+	Type    string `json:"type" example:"link"`
+	// URL is the submitted repository link, or where the uploaded file can
+	// be fetched for a limited time
+	URL string `json:"url"`
+	// AssetID, Filename, ContentType and Size describe an uploaded file
+	AssetID     *uuid.UUID `json:"asset_id,omitempty"`
+	Filename    string     `json:"filename,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	// Late is set when the submission came in after the due date; DaysLate
+	// counts every started day
+	Late     bool `json:"late"`
+	DaysLate int  `json:"days_late"`
+	// Grade is nil until the submission is graded, and hidden from students
+	// until grades are released
+	Grade *Grade `json:"grade"`
+}
+
+// SubmissionList is what mentors see of an assignment's submissions: each
+// student's latest attempt, or every attempt when the history is requested,
+// and the students who have not submitted
+type SubmissionList struct {
+	AssignmentID uuid.UUID       `json:"assignment_id"`
+	DueAt        time.Time       `json:"due_at"`
+	Submitted    int             `json:"submitted"`
+	Late         int             `json:"late"`
+	Submissions  []*Submission   `json:"submissions"`
+	Missing      []*CohortMember `json:"missing"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AttachmentTypeFile  = "file"
+	AttachmentTypeLink  = "link"
+	AttachmentTypeVideo = "video"
+)
+
+// Attachment is a learning material listed with a cirriculum entry: an
+// uploaded file, an external link or an embedded video
+type Attachment struct {
+	ID           uuid.UUID `json:"id"`
+	CirriculumID uuid.UUID `json:"cirriculum_id"`
+	Type         string    `json:"type" example:"file"`
+	Title        string    `json:"title"`
+	// Position orders the attachments of an entry, starting at 0
+	Position int `json:"position"`
+	// URL is where an uploaded file is served, or the address of a link or
+	// video
+	URL string `json:"url"`
+	// EmbedURL is the player address of a video
+	EmbedURL string `json:"embed_url,omitempty"`
+	// AssetID, Filename, ContentType and Size describe an uploaded file
+	AssetID     *uuid.UUID `json:"asset_id,omitempty"`
+	Filename    string     `json:"filename,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	UserID      uuid.UUID  `json:"user_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -7,10 +7,106 @@ import (
 )
 
 type Cirriculum struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Week      int       `json:"week"`
-	Content   string    `json:"description"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID    uuid.UUID `json:"id"`
+	Slug  string    `json:"slug"`
+	Title string    `json:"title"`
+	Week  int       `json:"week"`
+	// Position orders the entries within a week, starting at 0
+	Position    int        `json:"position"`
+	Content     string     `json:"content_markdown"`
+	ContentHTML string     `json:"content_html"`
+	Excerpt     string     `json:"excerpt"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	// CohortID scopes the entry to one edition of the workshop; nil entries
+	// are shared by every edition
+	CohortID *uuid.UUID `json:"cohort_id"`
+	// AvailableFrom overrides when the entry is released to students
+	AvailableFrom *time.Time `json:"available_from"`
+	// ReleasesAt is AvailableFrom, or the start of the entry's week for cohort
+	// entries; nil when the entry is always available
+	ReleasesAt *time.Time `json:"releases_at"`
+	Released   bool       `json:"released"`
+	// Attachments lists the learning materials of the entry in order
+	Attachments []*Attachment `json:"attachments"`
+	// Prerequisites lists the entries to cover before this one
+	Prerequisites []uuid.UUID `json:"prerequisites"`
+	// Author is only included when requested with ?include=author
+	Author    *Author   `json:"author,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	// Locale is the language the title and content are in
+	Locale           string   `json:"locale"`
+	AvailableLocales []string `json:"available_locales"`
+}
+
+// CirriculumWeekOrder lists the entries of one week in their new order
+type CirriculumWeekOrder struct {
+	Week int         `json:"week"`
+	IDs  []uuid.UUID `json:"ids"`
+}
+
+// CirriculumWeek is one week of the program with its entries in order
+type CirriculumWeek struct {
+	Week    int           `json:"week"`
+	Title   string        `json:"title"`
+	Theme   string        `json:"theme"`
+	Entries []*Cirriculum `json:"entries"`
+}
+
+// CirriculumGraph is the dependency graph of a cirriculum, with an edge from
+// each prerequisite to the entry requiring it
+type CirriculumGraph struct {
+	// CohortID is nil for the shared cirriculum
+	CohortID *uuid.UUID             `json:"cohort_id"`
+	Nodes    []*CirriculumGraphNode `json:"nodes"`
+	Edges    []*CirriculumGraphEdge `json:"edges"`
+}
+
+// CirriculumGraphNode is an entry in the dependency graph
+type CirriculumGraphNode struct {
+	ID       uuid.UUID `json:"id"`
+	Slug     string    `json:"slug"`
+	Title    string    `json:"title"`
+	Week     int       `json:"week"`
+	Released bool      `json:"released"`
+}
+
+// CirriculumGraphEdge points from a prerequisite to the entry requiring it
+type CirriculumGraphEdge struct {
+	From uuid.UUID `json:"from"`
+	To   uuid.UUID `json:"to"`
+}
+
+// CirriculumClone reports the entries and week titles that copying a
+// cirriculum into a cohort created, or would create on a dry run
+type CirriculumClone struct {
+	// SourceCohortID is nil when the shared cirriculum was copied
+	SourceCohortID *uuid.UUID              `json:"source_cohort_id"`
+	TargetCohortID uuid.UUID               `json:"target_cohort_id"`
+	WeekOffset     int                     `json:"week_offset"`
+	DryRun         bool                    `json:"dry_run"`
+	Entries        []*CirriculumCloneEntry `json:"entries"`
+	Weeks          []*CirriculumCloneWeek  `json:"weeks"`
+}
+
+// CirriculumCloneEntry is an entry created from a source entry
+type CirriculumCloneEntry struct {
+	SourceID uuid.UUID `json:"source_id"`
+	// ID is nil on a dry run
+	ID       *uuid.UUID `json:"id"`
+	Slug     string     `json:"slug"`
+	Title    string     `json:"title"`
+	Week     int        `json:"week"`
+	Position int        `json:"position"`
+	// Locales lists the translations copied with the entry
+	Locales []string `json:"locales"`
+	// Attachments is the number of attachments copied with the entry
+	Attachments int `json:"attachments"`
+}
+
+// CirriculumCloneWeek is a week title and theme copied into a cohort
+type CirriculumCloneWeek struct {
+	Week  int    `json:"week"`
+	Title string `json:"title"`
+	Theme string `json:"theme"`
 }
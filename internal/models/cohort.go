@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CohortStatusPlanned  = "planned"
+	CohortStatusActive   = "active"
+	CohortStatusArchived = "archived"
+)
+
+// Cohort is one edition of the workshop. News and cirriculum entries can be
+// scoped to a cohort; once archived, a cohort and its content are read-only.
+type Cohort struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// StartDate and EndDate are calendar days formatted as YYYY-MM-DD
+	StartDate string `json:"start_date" example:"2026-03-02"`
+	EndDate   string `json:"end_date" example:"2026-05-22"`
+	// Timezone is the IANA zone in which weeks start at midnight
+	Timezone    string    `json:"timezone" example:"Europe/Zagreb"`
+	Status      string    `json:"status" enums:"planned,active,archived"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CohortMember is a user enrolled in a cohort
+type CohortMember struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CommentStatusVisible = "visible"
+	CommentStatusHidden  = "hidden"
+	CommentStatusDeleted = "deleted"
+)
+
+type Comment struct {
+	ID          uuid.UUID  `json:"id"`
+	NewsID      uuid.UUID  `json:"news_id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Username    string     `json:"username"`
+	Content     string     `json:"content_markdown"`
+	ContentHTML string     `json:"content_html"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	EditedAt    *time.Time `json:"edited_at"`
+	Replies     []*Comment `json:"replies,omitempty"`
+}
+
+// CommentPage is one page of the top-level comments on a news item, each
+// with all of its replies
+type CommentPage struct {
+	Comments []*Comment `json:"comments"`
+	Locked   bool       `json:"locked"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Total    int        `json:"total"`
+}
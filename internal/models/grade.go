@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// GradeStatusGraded marks an assignment the student got a grade for
+	GradeStatusGraded = "graded"
+	// GradeStatusSubmitted marks a submission waiting to be graded
+	GradeStatusSubmitted = "submitted"
+	// GradeStatusMissing marks an assignment past its due date with nothing
+	// submitted; it counts as zero points
+	GradeStatusMissing = "missing"
+	// GradeStatusPending marks an assignment not yet due with nothing
+	// submitted
+	GradeStatusPending = "pending"
+)
+
+// RubricCriterion is one aspect an assignment is graded on, worth between
+// MinPoints and MaxPoints
+type RubricCriterion struct {
+	ID           uuid.UUID `json:"id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	MinPoints    int       `json:"min_points"`
+	MaxPoints    int       `json:"max_points"`
+	// Position orders the criteria of an assignment, starting at 0
+	Position int `json:"position"`
+}
+
+// Grade is a mentor's assessment of a submission
+type Grade struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	// Points is what the submission was given, the sum of Scores when the
+	// assignment has a rubric
+	Points float64 `json:"points"`
+	// Penalty is deducted from Points for a late submission under the
+	// penalty policy; Final is what counts
+	Penalty  float64          `json:"penalty"`
+	Final    float64          `json:"final"`
+	Feedback string           `json:"feedback"`
+	Scores   []CriterionScore `json:"scores"`
+	GraderID uuid.UUID        `json:"grader_id"`
+	GradedAt time.Time        `json:"graded_at"`
+	// UpdatedAt changes when the grade is revised
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CriterionScore is the points given for one rubric criterion
+type CriterionScore struct {
+	CriterionID uuid.UUID `json:"criterion_id"`
+	Points      float64   `json:"points"`
+}
+
+// Gradebook lists the grades of the students of a cohort on its assignments.
+// Cells line up with the assignments.
+type Gradebook struct {
+	CohortID    uuid.UUID              `json:"cohort_id"`
+	Assignments []*GradebookAssignment `json:"assignments"`
+	Students    []*GradebookRow        `json:"students"`
+}
+
+// GradebookAssignment is a column of the gradebook
+type GradebookAssignment struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Week      int       `json:"week"`
+	DueAt     time.Time `json:"due_at"`
+	MaxPoints int       `json:"max_points"`
+	Weight    int       `json:"weight"`
+}
+
+// GradebookRow holds the grades of one student
+type GradebookRow struct {
+	UserID   uuid.UUID       `json:"user_id"`
+	Username string          `json:"username"`
+	Cells    []GradebookCell `json:"cells"`
+	// Total is the weighted average of the graded and missing assignments
+	// as a percentage of their max points, rounded to one decimal
+	Total float64 `json:"total"`
+}
+
+// GradebookCell is a student's standing on one assignment
+type GradebookCell struct {
+	Status string `json:"status" enums:"graded,submitted,missing,pending"`
+	// Points is the final grade, 0 for a missing assignment and nil
+	// otherwise
+	Points *float64 `json:"points"`
+	Late   bool     `json:"late"`
+}
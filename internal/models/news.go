@@ -6,12 +6,57 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	NewsStatusDraft     = "draft"
+	NewsStatusScheduled = "scheduled"
+	NewsStatusPublished = "published"
+	NewsStatusArchived  = "archived"
+)
+
+// Reactions is the fixed set of reactions users can give news, keyed by name
+var Reactions = map[string]string{
+	"like":      "👍",
+	"love":      "❤️",
+	"laugh":     "😂",
+	"wow":       "😮",
+	"clap":      "👏",
+	"celebrate": "🎉",
+}
+
 type News struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	ImagePath string    `json:"image_path"`
-	Category  string    `json:"category"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID          uuid.UUID  `json:"id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content_markdown"`
+	ContentHTML string     `json:"content_html"`
+	Excerpt     string     `json:"excerpt"`
+	ImagePath   string     `json:"image_path"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	Image       *Asset     `json:"image,omitempty"`
+	Category    string     `json:"category"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	UserID      uuid.UUID  `json:"user_id"`
+	// CohortID scopes the item to one edition of the workshop; nil items are
+	// shared by every edition
+	CohortID *uuid.UUID `json:"cohort_id"`
+	// Author is only included when requested with ?include=author
+	Author    *Author   `json:"author,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Pinned is set while the pin is active; an expired pin keeps its
+	// PinnedAt and PinnedUntil but no longer sorts the item first
+	Pinned      bool       `json:"pinned"`
+	PinnedAt    *time.Time `json:"pinned_at"`
+	PinnedUntil *time.Time `json:"pinned_until"`
+	Featured    bool       `json:"featured"`
+	// CommentsLocked closes the comments to everyone but mentors
+	CommentsLocked bool `json:"comments_locked"`
+	// Reactions counts each reaction given; UserReactions lists those the
+	// requesting user gave
+	Reactions     map[string]int `json:"reactions"`
+	UserReactions []string       `json:"user_reactions"`
+	// Locale is the language the title and content are in
+	Locale           string   `json:"locale"`
+	AvailableLocales []string `json:"available_locales"`
 }
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// When the results of a poll are shown to voters
+const (
+	PollResultsAlways     = "always"
+	PollResultsAfterVote  = "after_vote"
+	PollResultsAfterClose = "after_close"
+)
+
+// Poll is an optional question attached to a news item
+type Poll struct {
+	ID       uuid.UUID `json:"id"`
+	NewsID   uuid.UUID `json:"news_id"`
+	Question string    `json:"question"`
+	// Multiple allows choosing more than one option
+	Multiple bool `json:"multiple"`
+	// Anonymous polls never reveal who voted for what
+	Anonymous         bool          `json:"anonymous"`
+	ResultsVisibility string        `json:"results_visibility" enums:"always,after_vote,after_close"`
+	ClosesAt          *time.Time    `json:"closes_at"`
+	Closed            bool          `json:"closed"`
+	Options           []*PollOption `json:"options"`
+	// Voted reports whether the requesting user has voted, and UserVotes
+	// lists the options they chose
+	Voted     bool        `json:"voted"`
+	UserVotes []uuid.UUID `json:"user_votes"`
+	// Results is nil while they are hidden from the requesting user
+	Results   *PollResults `json:"results"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PollOption struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+// PollResults are the vote counts of a poll at the time of the request
+type PollResults struct {
+	TotalVoters int                 `json:"total_voters"`
+	Options     []*PollOptionResult `json:"options"`
+}
+
+type PollOptionResult struct {
+	OptionID uuid.UUID `json:"option_id"`
+	Text     string    `json:"text"`
+	Votes    int       `json:"votes"`
+	// Voters is only listed for polls that are not anonymous
+	Voters []*PollVoter `json:"voters,omitempty"`
+}
+
+type PollVoter struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProgressStatusNotStarted = "not_started"
+	ProgressStatusStarted    = "started"
+	ProgressStatusCompleted  = "completed"
+)
+
+// Progress records how far a user got with a cirriculum entry
+type Progress struct {
+	CirriculumID uuid.UUID  `json:"cirriculum_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Status       string     `json:"status" enums:"started,completed"`
+	StartedAt    time.Time  `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ProgressStats counts the entries of a week or of the whole cirriculum by
+// progress. Percent is the share of entries completed.
+type ProgressStats struct {
+	Total     int     `json:"total"`
+	Started   int     `json:"started"`
+	Completed int     `json:"completed"`
+	Percent   float64 `json:"percent" example:"62.5"`
+}
+
+// WeekProgress is the progress through one week of the program
+type WeekProgress struct {
+	Week int `json:"week"`
+	ProgressStats
+}
+
+// ProgressSummary is a user's progress through a cirriculum, overall and per
+// week, counting the entries released to them
+type ProgressSummary struct {
+	// CohortID is nil for the shared cirriculum
+	CohortID *uuid.UUID     `json:"cohort_id"`
+	Overall  ProgressStats  `json:"overall"`
+	Weeks    []WeekProgress `json:"weeks"`
+}
+
+// CohortProgress is the progress of every student of a cohort through its
+// cirriculum
+type CohortProgress struct {
+	CohortID uuid.UUID          `json:"cohort_id"`
+	Entries  []*ProgressEntry   `json:"entries"`
+	Students []*StudentProgress `json:"students"`
+}
+
+// ProgressEntry is a column of the progress matrix
+type ProgressEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Week     int       `json:"week"`
+	Released bool      `json:"released"`
+}
+
+// StudentProgress is a row of the progress matrix
+type StudentProgress struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	// Statuses holds the student's status for each entry, in the order of
+	// the matrix entries
+	Statuses []string `json:"statuses" enums:"not_started,started,completed"`
+	ProgressStats
+	// Overdue counts the released entries of weeks that are over and that
+	// the student has not completed
+	Overdue int `json:"overdue"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision is an immutable snapshot of a news or cirriculum item, taken each
+// time the item is saved
+type Revision struct {
+	ID         uuid.UUID       `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id"`
+	Number     int             `json:"number"`
+	Snapshot   json.RawMessage `json:"snapshot" swaggertype:"object"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Username   string          `json:"username"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RevisionDiff is a unified text diff between two revisions of an item
+type RevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
+
+// NewsSnapshot is the state of a news item recorded in a revision
+type NewsSnapshot struct {
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content_markdown"`
+	ImagePath   string     `json:"image_path"`
+	ImageID     *uuid.UUID `json:"image_id"`
+	Category    string     `json:"category"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// CirriculumSnapshot is the state of a cirriculum entry recorded in a revision
+type CirriculumSnapshot struct {
+	Title   string     `json:"title"`
+	Slug    string     `json:"slug"`
+	Week    int        `json:"week"`
+	Content string     `json:"content_markdown"`
+	ImageID *uuid.UUID `json:"image_id"`
+}
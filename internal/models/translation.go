@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Translation holds the title and content of a news or cirriculum item in a
+// locale other than the default one
+type Translation struct {
+	EntityType  string    `json:"entity_type"`
+	EntityID    uuid.UUID `json:"entity_id"`
+	Locale      string    `json:"locale"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content_markdown"`
+	ContentHTML string    `json:"content_html"`
+	UserID      uuid.UUID `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of content that can be moved to the trash
+const (
+	TrashTypeNews       = "news"
+	TrashTypeCirriculum = "cirriculum"
+	TrashTypeAsset      = "asset"
+)
+
+// TrashItem is a deleted item waiting to be restored or purged
+type TrashItem struct {
+	Type      string    `json:"type" enums:"news,cirriculum,asset"`
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	UserID    uuid.UUID `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
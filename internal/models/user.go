@@ -6,9 +6,26 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoleStudent = "student"
+	RoleMentor  = "mentor"
+	RoleAdmin   = "admin"
+)
+
 type User struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
 	Password  string    `json:"-"` // Exclude password from JSON
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// Author is the public profile of the user who wrote a piece of content
+type Author struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// DisplayName falls back to Username when the user has not set one
+	DisplayName string     `json:"display_name"`
+	AvatarID    *uuid.UUID `json:"avatar_id"`
+	AvatarURL   string     `json:"avatar_url,omitempty"`
+}
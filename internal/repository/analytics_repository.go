@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// dateLayout formats days for DATE columns, avoiding any time zone
+// conversion by the session
+const dateLayout = "2006-01-02"
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// AddViews adds buffered view counts to the daily totals. The counts are
+// added rather than set, so several server instances can flush concurrently.
+func (r *AnalyticsRepository) AddViews(views []*models.DailyViews) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Skip views of news deleted since they were recorded instead of failing
+	// the whole batch on the foreign key
+	stmt, err := tx.Prepare(`
+		INSERT INTO news_view_stats (news_id, day, views)
+		SELECT $1, $2::date, $3
+		WHERE EXISTS (SELECT 1 FROM news WHERE id = $1)
+		ON CONFLICT (news_id, day) DO UPDATE SET views = news_view_stats.views + EXCLUDED.views
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, v := range views {
+		if _, err := stmt.Exec(v.NewsID, v.Date.Format(dateLayout), v.Views); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetDailyViews returns the views of a news item for each day between from
+// and to, inclusive, that had any
+func (r *AnalyticsRepository) GetDailyViews(newsID uuid.UUID, from, to time.Time) ([]*models.DailyViews, error) {
+	query := `
+		SELECT news_id, day, views
+		FROM news_view_stats
+		WHERE news_id = $1 AND day BETWEEN $2::date AND $3::date
+		ORDER BY day
+	`
+	rows, err := r.db.Query(query, newsID, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]*models.DailyViews, 0)
+	for rows.Next() {
+		v := &models.DailyViews{}
+		if err := rows.Scan(&v.NewsID, &v.Date, &v.Views); err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+// GetTopNews returns the most viewed news items between from and to,
+// inclusive
+func (r *AnalyticsRepository) GetTopNews(from, to time.Time, limit int) ([]*models.NewsViews, error) {
+	query := `
+		SELECT n.id, n.title, n.slug, SUM(s.views) AS total
+		FROM news_view_stats s
+		JOIN news n ON n.id = s.news_id
+		WHERE s.day BETWEEN $1::date AND $2::date AND n.deleted_at IS NULL
+		GROUP BY n.id, n.title, n.slug
+		ORDER BY total DESC, n.id
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, from.Format(dateLayout), to.Format(dateLayout), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	top := make([]*models.NewsViews, 0)
+	for rows.Next() {
+		v := &models.NewsViews{}
+		if err := rows.Scan(&v.NewsID, &v.Title, &v.Slug, &v.Views); err != nil {
+			return nil, err
+		}
+		top = append(top, v)
+	}
+	return top, rows.Err()
+}
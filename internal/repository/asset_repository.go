@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const assetColumns = `id, storage_key, filename, content_type, size, visibility, width, height, blurhash, processing_status, user_id, created_at`
+
+type AssetRepository struct {
+	db *sql.DB
+}
+
+func NewAssetRepository(db *sql.DB) *AssetRepository {
+	return &AssetRepository{db: db}
+}
+
+func scanAsset(row rowScanner) (*models.Asset, error) {
+	asset := &models.Asset{Variants: make([]models.AssetVariant, 0)}
+	var width, height sql.NullInt64
+	var blurhash sql.NullString
+	err := row.Scan(&asset.ID, &asset.StorageKey, &asset.Filename, &asset.ContentType, &asset.Size, &asset.Visibility, &width, &height, &blurhash, &asset.ProcessingStatus, &asset.UserID, &asset.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	asset.Width = int(width.Int64)
+	asset.Height = int(height.Int64)
+	asset.Blurhash = blurhash.String
+	return asset, nil
+}
+
+func (r *AssetRepository) CreateAsset(asset *models.Asset) error {
+	query := `
+		INSERT INTO assets (id, storage_key, filename, content_type, size, visibility, processing_status, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(query, asset.ID, asset.StorageKey, asset.Filename, asset.ContentType, asset.Size, asset.Visibility, asset.ProcessingStatus, asset.UserID, asset.CreatedAt)
+	return err
+}
+
+func (r *AssetRepository) GetAssetByID(id uuid.UUID) (*models.Asset, error) {
+	query := `
+		SELECT ` + assetColumns + `
+		FROM assets
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	asset, err := scanAsset(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	variants, err := r.getVariants([]uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	asset.Variants = append(asset.Variants, variants[id]...)
+	return asset, nil
+}
+
+// GetAssetsByIDs loads several assets with their variants in two queries
+func (r *AssetRepository) GetAssetsByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.Asset, error) {
+	assets := make(map[uuid.UUID]*models.Asset, len(ids))
+	if len(ids) == 0 {
+		return assets, nil
+	}
+
+	query := `
+		SELECT ` + assetColumns + `
+		FROM assets
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets[asset.ID] = asset
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	variants, err := r.getVariants(ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, v := range variants {
+		if asset, ok := assets[id]; ok {
+			asset.Variants = append(asset.Variants, v...)
+		}
+	}
+	return assets, nil
+}
+
+func (r *AssetRepository) getVariants(ids []uuid.UUID) (map[uuid.UUID][]models.AssetVariant, error) {
+	query := `
+		SELECT asset_id, name, format, storage_key, content_type, width, height, size
+		FROM asset_variants
+		WHERE asset_id = ANY($1::uuid[])
+		ORDER BY width, format
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make(map[uuid.UUID][]models.AssetVariant)
+	for rows.Next() {
+		var v models.AssetVariant
+		if err := rows.Scan(&v.AssetID, &v.Name, &v.Format, &v.StorageKey, &v.ContentType, &v.Width, &v.Height, &v.Size); err != nil {
+			return nil, err
+		}
+		variants[v.AssetID] = append(variants[v.AssetID], v)
+	}
+	return variants, rows.Err()
+}
+
+func (r *AssetRepository) GetVariant(assetID uuid.UUID, name, format string) (*models.AssetVariant, error) {
+	query := `
+		SELECT asset_id, name, format, storage_key, content_type, width, height, size
+		FROM asset_variants
+		WHERE asset_id = $1 AND name = $2 AND format = $3
+	`
+	v := &models.AssetVariant{}
+	err := r.db.QueryRow(query, assetID, name, format).Scan(&v.AssetID, &v.Name, &v.Format, &v.StorageKey, &v.ContentType, &v.Width, &v.Height, &v.Size)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// DeleteAsset moves an asset to the trash. Its files are kept until purged.
+func (r *AssetRepository) DeleteAsset(id uuid.UUID, now time.Time) error {
+	_, err := r.db.Exec(`UPDATE assets SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, now)
+	return err
+}
+
+// IsSubmitted reports whether an asset was submitted to an assignment
+func (r *AssetRepository) IsSubmitted(id uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM submissions WHERE asset_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+// ClaimPendingAsset marks the oldest unprocessed asset as processing and
+// returns it, or sql.ErrNoRows when there is nothing to do. Assets stuck in
+// processing since before staleBefore are picked up again. SKIP LOCKED lets
+// several workers claim different assets concurrently.
+func (r *AssetRepository) ClaimPendingAsset(now, staleBefore time.Time) (*models.Asset, error) {
+	query := `
+		UPDATE assets
+		SET processing_status = 'processing', processing_started_at = $1
+		WHERE id = (
+			SELECT id FROM assets
+			WHERE (processing_status = 'pending'
+				OR (processing_status = 'processing' AND processing_started_at < $2))
+				AND deleted_at IS NULL
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + assetColumns
+	return scanAsset(r.db.QueryRow(query, now, staleBefore))
+}
+
+// SaveProcessingResult stores the image dimensions, placeholder and variants
+// of a processed asset, replacing any earlier variants
+func (r *AssetRepository) SaveProcessingResult(asset *models.Asset) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE assets
+		SET width = $2, height = $3, blurhash = $4, processing_status = 'done', processing_error = NULL
+		WHERE id = $1
+	`, asset.ID, asset.Width, asset.Height, asset.Blurhash)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM asset_variants WHERE asset_id = $1`, asset.ID); err != nil {
+		return err
+	}
+	for _, v := range asset.Variants {
+		_, err := tx.Exec(`
+			INSERT INTO asset_variants (asset_id, name, format, storage_key, content_type, width, height, size)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, asset.ID, v.Name, v.Format, v.StorageKey, v.ContentType, v.Width, v.Height, v.Size)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *AssetRepository) MarkProcessingFailed(id uuid.UUID, reason string) error {
+	query := `
+		UPDATE assets
+		SET processing_status = 'failed', processing_error = $2
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, id, reason)
+	return err
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const assignmentColumns = `id, cohort_id, week, title, description, due_at, max_points, allowed_types, allow_links, late_policy, late_penalty, weight, grades_released_at, user_id, created_at, updated_at`
+
+const submissionColumns = `s.id, s.assignment_id, s.user_id, u.username, s.attempt, s.type, s.url, s.asset_id, a.filename, a.content_type, a.size, s.submitted_at`
+
+// submissionFrom joins each submission s with its author u and uploaded file
+// a. Submitted files cannot be moved to the trash, so every attempt is listed.
+const submissionFrom = `submissions s JOIN users u ON u.id = s.user_id LEFT JOIN assets a ON a.id = s.asset_id`
+
+type AssignmentRepository struct {
+	db *sql.DB
+}
+
+func NewAssignmentRepository(db *sql.DB) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+func scanAssignment(row rowScanner) (*models.Assignment, error) {
+	assignment := &models.Assignment{}
+	var allowedTypes pq.StringArray
+	var gradesReleasedAt sql.NullTime
+	err := row.Scan(&assignment.ID, &assignment.CohortID, &assignment.Week, &assignment.Title, &assignment.Description, &assignment.DueAt, &assignment.MaxPoints, &allowedTypes, &assignment.AllowLinks, &assignment.LatePolicy, &assignment.LatePenalty, &assignment.Weight, &gradesReleasedAt, &assignment.UserID, &assignment.CreatedAt, &assignment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	assignment.AllowedTypes = append(make([]string, 0), allowedTypes...)
+	if gradesReleasedAt.Valid {
+		assignment.GradesReleasedAt = &gradesReleasedAt.Time
+	}
+	return assignment, nil
+}
+
+func scanSubmission(row rowScanner) (*models.Submission, error) {
+	submission := &models.Submission{}
+	var filename, contentType sql.NullString
+	var size sql.NullInt64
+	err := row.Scan(&submission.ID, &submission.AssignmentID, &submission.UserID, &submission.Username, &submission.Attempt, &submission.Type, &submission.URL, &submission.AssetID, &filename, &contentType, &size, &submission.SubmittedAt)
+	if err != nil {
+		return nil, err
+	}
+	submission.Filename = filename.String
+	submission.ContentType = contentType.String
+	submission.Size = size.Int64
+	return submission, nil
+}
+
+// GetAssignments returns the assignments of a cohort in the order they are
+// due, only those of one week when week is not 0
+func (r *AssignmentRepository) GetAssignments(cohortID uuid.UUID, week int) ([]*models.Assignment, error) {
+	query := `
+		SELECT ` + assignmentColumns + `
+		FROM assignments
+		WHERE cohort_id = $1 AND ($2 = 0 OR week = $2)
+		ORDER BY week, due_at, created_at
+	`
+	rows, err := r.db.Query(query, cohortID, week)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := make([]*models.Assignment, 0)
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+func (r *AssignmentRepository) GetAssignmentByID(id uuid.UUID) (*models.Assignment, error) {
+	query := `
+		SELECT ` + assignmentColumns + `
+		FROM assignments
+		WHERE id = $1
+	`
+	return scanAssignment(r.db.QueryRow(query, id))
+}
+
+func (r *AssignmentRepository) CreateAssignment(assignment *models.Assignment) error {
+	query := `
+		INSERT INTO assignments (id, cohort_id, week, title, description, due_at, max_points, allowed_types, allow_links, late_policy, late_penalty, weight, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	_, err := r.db.Exec(query, assignment.ID, assignment.CohortID, assignment.Week, assignment.Title, assignment.Description, assignment.DueAt, assignment.MaxPoints, pq.Array(assignment.AllowedTypes), assignment.AllowLinks, assignment.LatePolicy, assignment.LatePenalty, assignment.Weight, assignment.UserID, assignment.CreatedAt, assignment.UpdatedAt)
+	return err
+}
+
+func (r *AssignmentRepository) UpdateAssignment(assignment *models.Assignment) error {
+	query := `
+		UPDATE assignments
+		SET week = $2, title = $3, description = $4, due_at = $5, max_points = $6, allowed_types = $7, allow_links = $8, late_policy = $9, late_penalty = $10, weight = $11, updated_at = $12
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, assignment.ID, assignment.Week, assignment.Title, assignment.Description, assignment.DueAt, assignment.MaxPoints, pq.Array(assignment.AllowedTypes), assignment.AllowLinks, assignment.LatePolicy, assignment.LatePenalty, assignment.Weight, assignment.UpdatedAt)
+	return err
+}
+
+// SetGradesReleased shows the grades of an assignment to students from the
+// given time, or hides them again when releasedAt is nil
+func (r *AssignmentRepository) SetGradesReleased(id uuid.UUID, releasedAt *time.Time) error {
+	_, err := r.db.Exec(`UPDATE assignments SET grades_released_at = $2 WHERE id = $1`, id, releasedAt)
+	return err
+}
+
+func (r *AssignmentRepository) DeleteAssignment(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM assignments WHERE id = $1`, id)
+	return err
+}
+
+// HasSubmissions reports whether anyone submitted to an assignment
+func (r *AssignmentRepository) HasSubmissions(assignmentID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM submissions WHERE assignment_id = $1)`, assignmentID).Scan(&exists)
+	return exists, err
+}
+
+// GetSubmissions returns the submissions to an assignment by username and
+// attempt, of every student or, when userID is not nil, of that student only
+func (r *AssignmentRepository) GetSubmissions(assignmentID uuid.UUID, userID *uuid.UUID) ([]*models.Submission, error) {
+	query := `
+		SELECT ` + submissionColumns + `
+		FROM ` + submissionFrom + `
+		WHERE s.assignment_id = $1 AND ($2::uuid IS NULL OR s.user_id = $2)
+		ORDER BY u.username, s.attempt
+	`
+	rows, err := r.db.Query(query, assignmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := make([]*models.Submission, 0)
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions, rows.Err()
+}
+
+func (r *AssignmentRepository) GetSubmissionByID(id uuid.UUID) (*models.Submission, error) {
+	query := `
+		SELECT ` + submissionColumns + `
+		FROM ` + submissionFrom + `
+		WHERE s.id = $1
+	`
+	return scanSubmission(r.db.QueryRow(query, id))
+}
+
+// CreateSubmission stores a student's next attempt at an assignment and sets
+// its attempt number and the student's username. Two attempts racing for the
+// same number fail on the unique constraint.
+func (r *AssignmentRepository) CreateSubmission(submission *models.Submission) error {
+	query := `
+		INSERT INTO submissions (id, assignment_id, user_id, attempt, type, url, asset_id, submitted_at)
+		SELECT $1, $2, $3, COALESCE(MAX(attempt), 0) + 1, $4, $5, $6, $7
+		FROM submissions
+		WHERE assignment_id = $2 AND user_id = $3
+		RETURNING attempt, (SELECT username FROM users WHERE id = $3)
+	`
+	return r.db.QueryRow(query, submission.ID, submission.AssignmentID, submission.UserID, submission.Type, submission.URL, submission.AssetID, submission.SubmittedAt).Scan(&submission.Attempt, &submission.Username)
+}
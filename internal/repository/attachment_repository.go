@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachmentColumns = `t.id, t.cirriculum_id, t.type, t.title, t.position, t.url, t.asset_id, a.filename, a.content_type, a.size, t.user_id, t.created_at`
+
+// attachmentFrom joins each attachment t with its uploaded file a. Files
+// moved to the trash drop out of the list until they are restored.
+const attachmentFrom = `cirriculum_attachments t LEFT JOIN assets a ON a.id = t.asset_id AND a.deleted_at IS NULL`
+
+// attachmentVisible leaves out file attachments whose file is in the trash
+const attachmentVisible = `(t.asset_id IS NULL OR a.id IS NOT NULL)`
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	var filename, contentType sql.NullString
+	var size sql.NullInt64
+	err := row.Scan(&attachment.ID, &attachment.CirriculumID, &attachment.Type, &attachment.Title, &attachment.Position, &attachment.URL, &attachment.AssetID, &filename, &contentType, &size, &attachment.UserID, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	attachment.Filename = filename.String
+	attachment.ContentType = contentType.String
+	attachment.Size = size.Int64
+	return attachment, nil
+}
+
+// GetAttachments returns the attachments of the given entries in order,
+// grouped by entry, in a single query
+func (r *AttachmentRepository) GetAttachments(cirriculumIDs []uuid.UUID) (map[uuid.UUID][]*models.Attachment, error) {
+	attachments := make(map[uuid.UUID][]*models.Attachment)
+	if len(cirriculumIDs) == 0 {
+		return attachments, nil
+	}
+
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM ` + attachmentFrom + `
+		WHERE t.cirriculum_id = ANY($1::uuid[]) AND ` + attachmentVisible + `
+		ORDER BY t.position, t.created_at
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(cirriculumIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments[attachment.CirriculumID] = append(attachments[attachment.CirriculumID], attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *AttachmentRepository) GetAttachmentByID(id uuid.UUID) (*models.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM ` + attachmentFrom + `
+		WHERE t.id = $1 AND ` + attachmentVisible + `
+	`
+	return scanAttachment(r.db.QueryRow(query, id))
+}
+
+// NextPosition returns the position after the last attachment of an entry
+func (r *AttachmentRepository) NextPosition(cirriculumID uuid.UUID) (int, error) {
+	var position int
+	query := `SELECT COALESCE(MAX(position) + 1, 0) FROM cirriculum_attachments WHERE cirriculum_id = $1`
+	err := r.db.QueryRow(query, cirriculumID).Scan(&position)
+	return position, err
+}
+
+func (r *AttachmentRepository) CreateAttachment(attachment *models.Attachment) error {
+	query := `
+		INSERT INTO cirriculum_attachments (id, cirriculum_id, type, title, position, url, asset_id, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(query, attachment.ID, attachment.CirriculumID, attachment.Type, attachment.Title, attachment.Position, attachment.URL, attachment.AssetID, attachment.UserID, attachment.CreatedAt)
+	return err
+}
+
+func (r *AttachmentRepository) UpdateAttachment(attachment *models.Attachment) error {
+	query := `
+		UPDATE cirriculum_attachments
+		SET title = $2, url = $3
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, attachment.ID, attachment.Title, attachment.URL)
+	return err
+}
+
+// Reorder puts the listed attachments of an entry first, in the given order.
+// Attachments that are not listed keep their relative order after them.
+func (r *AttachmentRepository) Reorder(cirriculumID uuid.UUID, ids []uuid.UUID) error {
+	query := `
+		WITH ranked AS (
+			SELECT t.id,
+				ROW_NUMBER() OVER (
+					ORDER BY array_position($2::uuid[], t.id) NULLS LAST, t.position, t.created_at
+				) - 1 AS position
+			FROM cirriculum_attachments t
+			WHERE t.cirriculum_id = $1
+		)
+		UPDATE cirriculum_attachments t
+		SET position = ranked.position
+		FROM ranked
+		WHERE t.id = ranked.id
+	`
+	_, err := r.db.Exec(query, cirriculumID, pq.Array(uuidStrings(ids)))
+	return err
+}
+
+func (r *AttachmentRepository) DeleteAttachment(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM cirriculum_attachments WHERE id = $1`, id)
+	return err
+}
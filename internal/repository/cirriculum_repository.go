@@ -2,10 +2,19 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const cirriculumColumns = `c.id, c.slug, c.title, c.week, c.position, c.description, c.image_id, c.user_id, c.cohort_id, c.available_from, c.created_at, ` + authorColumns
+
+// cirriculumFrom joins each cirriculum entry c with its author u
+const cirriculumFrom = `cirriculum c JOIN users u ON u.id = c.user_id`
+
 type CirriculumRepository struct {
 	db *sql.DB
 }
@@ -14,13 +23,47 @@ func NewCirriculumRepository(db *sql.DB) *CirriculumRepository {
 	return &CirriculumRepository{db: db}
 }
 
-func (r *CirriculumRepository) GetAllCirriculum() ([]*models.Cirriculum, error) {
+func scanCirriculum(row rowScanner) (*models.Cirriculum, error) {
+	cirriculum := &models.Cirriculum{}
+	author := &models.Author{}
+	var availableFrom sql.NullTime
+	err := row.Scan(&cirriculum.ID, &cirriculum.Slug, &cirriculum.Title, &cirriculum.Week, &cirriculum.Position, &cirriculum.Content, &cirriculum.ImageID, &cirriculum.UserID, &cirriculum.CohortID, &availableFrom, &cirriculum.CreatedAt, &author.Username, &author.DisplayName, &author.AvatarID)
+	if err != nil {
+		return nil, err
+	}
+	if availableFrom.Valid {
+		cirriculum.AvailableFrom = &availableFrom.Time
+	}
+	author.ID = cirriculum.UserID
+	cirriculum.Author = author
+	return cirriculum, nil
+}
+
+// GetAllCirriculum returns the entries of a cohort in order, or the shared
+// entries that belong to no cohort when cohortID is nil
+func (r *CirriculumRepository) GetAllCirriculum(cohortID *uuid.UUID) ([]*models.Cirriculum, error) {
 	query := `
-		SELECT id, title, week, description, user_id, created_at
-		FROM cirriculum
-		ORDER BY created_at DESC
+		SELECT ` + cirriculumColumns + `
+		FROM ` + cirriculumFrom + `
+		WHERE c.cohort_id IS NOT DISTINCT FROM $1 AND c.deleted_at IS NULL
+		ORDER BY c.week, c.position, c.created_at
 	`
-	rows, err := r.db.Query(query)
+	return r.queryCirriculum(query, cohortID)
+}
+
+// GetCirriculumByWeek returns the entries of one week of a cohort in order
+func (r *CirriculumRepository) GetCirriculumByWeek(cohortID *uuid.UUID, week int) ([]*models.Cirriculum, error) {
+	query := `
+		SELECT ` + cirriculumColumns + `
+		FROM ` + cirriculumFrom + `
+		WHERE c.cohort_id IS NOT DISTINCT FROM $1 AND c.week = $2 AND c.deleted_at IS NULL
+		ORDER BY c.position, c.created_at
+	`
+	return r.queryCirriculum(query, cohortID, week)
+}
+
+func (r *CirriculumRepository) queryCirriculum(query string, args ...any) ([]*models.Cirriculum, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,21 +71,342 @@ func (r *CirriculumRepository) GetAllCirriculum() ([]*models.Cirriculum, error)
 
 	var cirriculaList []*models.Cirriculum = make([]*models.Cirriculum, 0)
 	for rows.Next() {
-		cirriculum := &models.Cirriculum{}
-		err := rows.Scan(&cirriculum.ID, &cirriculum.Title, &cirriculum.Week, &cirriculum.Content, &cirriculum.UserID, &cirriculum.CreatedAt)
+		cirriculum, err := scanCirriculum(rows)
 		if err != nil {
 			return nil, err
 		}
 		cirriculaList = append(cirriculaList, cirriculum)
 	}
-	return cirriculaList, nil
+	return cirriculaList, rows.Err()
 }
 
-func (r *CirriculumRepository) CreateCirriculum(cirriculum *models.Cirriculum) error {
+func (r *CirriculumRepository) GetCirriculumByID(id uuid.UUID) (*models.Cirriculum, error) {
 	query := `
-		INSERT INTO cirriculum (id, title, week, description, user_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		SELECT ` + cirriculumColumns + `
+		FROM ` + cirriculumFrom + `
+		WHERE c.id = $1 AND c.deleted_at IS NULL
 	`
-	_, err := r.db.Exec(query, cirriculum.ID, cirriculum.Title, cirriculum.Week, cirriculum.Content, cirriculum.UserID, cirriculum.CreatedAt)
+	return scanCirriculum(r.db.QueryRow(query, id))
+}
+
+func (r *CirriculumRepository) GetCirriculumBySlug(slug string) (*models.Cirriculum, error) {
+	query := `
+		SELECT ` + cirriculumColumns + `
+		FROM ` + cirriculumFrom + `
+		WHERE c.slug = $1 AND c.deleted_at IS NULL
+	`
+	return scanCirriculum(r.db.QueryRow(query, slug))
+}
+
+// CreateCirriculum stores a cirriculum entry together with its first revision
+func (r *CirriculumRepository) CreateCirriculum(cirriculum *models.Cirriculum, revision *models.Revision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO cirriculum (id, slug, title, week, position, description, image_id, user_id, cohort_id, available_from, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = tx.Exec(query, cirriculum.ID, cirriculum.Slug, cirriculum.Title, cirriculum.Week, cirriculum.Position, cirriculum.Content, cirriculum.ImageID, cirriculum.UserID, cirriculum.CohortID, cirriculum.AvailableFrom, cirriculum.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertRevision(tx, revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateCirriculum saves a cirriculum entry and appends the revision
+// recording the change in one transaction. When the slug changed, oldSlug is
+// kept as a redirect.
+func (r *CirriculumRepository) UpdateCirriculum(cirriculum *models.Cirriculum, oldSlug string, revision *models.Revision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE cirriculum
+		SET slug = $2, title = $3, week = $4, position = $5, description = $6, image_id = $7, available_from = $8
+		WHERE id = $1
+	`
+	_, err = tx.Exec(query, cirriculum.ID, cirriculum.Slug, cirriculum.Title, cirriculum.Week, cirriculum.Position, cirriculum.Content, cirriculum.ImageID, cirriculum.AvailableFrom)
+	if err != nil {
+		return err
+	}
+	if err := renameSlug(tx, SlugEntityCirriculum, cirriculum.ID, oldSlug, cirriculum.Slug); err != nil {
+		return err
+	}
+	if err := insertRevision(tx, revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteCirriculum moves a cirriculum entry to the trash and drops its
+// prerequisite links in both directions. The cycle and week checks do not see
+// trashed entries, so links kept through the trash could come back broken.
+func (r *CirriculumRepository) DeleteCirriculum(id uuid.UUID, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE cirriculum SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, now)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM cirriculum_prerequisites WHERE cirriculum_id = $1 OR prerequisite_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// prerequisitesFrom joins each prerequisite link p with the entry c that
+// requires it and the required entry r, so links to trashed entries drop out
+const prerequisitesFrom = `cirriculum_prerequisites p
+	JOIN cirriculum c ON c.id = p.cirriculum_id AND c.deleted_at IS NULL
+	JOIN cirriculum r ON r.id = p.prerequisite_id AND r.deleted_at IS NULL`
+
+// GetPrerequisites returns the prerequisites of the given entries, keyed by
+// the entry requiring them, in the order they are scheduled
+func (r *CirriculumRepository) GetPrerequisites(ids []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	query := `
+		SELECT p.cirriculum_id, p.prerequisite_id
+		FROM ` + prerequisitesFrom + `
+		WHERE p.cirriculum_id = ANY($1::uuid[])
+		ORDER BY r.week, r.position
+	`
+	return r.queryPrerequisites(query, pq.Array(uuidStrings(ids)))
+}
+
+// GetCohortPrerequisites returns every prerequisite link between the entries
+// of a cohort, or of the shared cirriculum when cohortID is nil
+func (r *CirriculumRepository) GetCohortPrerequisites(cohortID *uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	query := `
+		SELECT p.cirriculum_id, p.prerequisite_id
+		FROM ` + prerequisitesFrom + `
+		WHERE c.cohort_id IS NOT DISTINCT FROM $1
+		ORDER BY r.week, r.position
+	`
+	return r.queryPrerequisites(query, cohortID)
+}
+
+func (r *CirriculumRepository) queryPrerequisites(query string, args ...any) (map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prerequisites := make(map[uuid.UUID][]uuid.UUID)
+	for rows.Next() {
+		var id, prerequisiteID uuid.UUID
+		if err := rows.Scan(&id, &prerequisiteID); err != nil {
+			return nil, err
+		}
+		prerequisites[id] = append(prerequisites[id], prerequisiteID)
+	}
+	return prerequisites, rows.Err()
+}
+
+// SetPrerequisites replaces the prerequisites of an entry
+func (r *CirriculumRepository) SetPrerequisites(id uuid.UUID, prerequisiteIDs []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM cirriculum_prerequisites WHERE cirriculum_id = $1`, id); err != nil {
+		return err
+	}
+	for _, prerequisiteID := range prerequisiteIDs {
+		_, err := tx.Exec(`INSERT INTO cirriculum_prerequisites (cirriculum_id, prerequisite_id) VALUES ($1, $2)`, id, prerequisiteID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// NextPosition returns the position after the last entry of a week of a
+// cohort
+func (r *CirriculumRepository) NextPosition(cohortID *uuid.UUID, week int) (int, error) {
+	var position int
+	query := `SELECT COALESCE(MAX(position) + 1, 0) FROM cirriculum WHERE cohort_id IS NOT DISTINCT FROM $1 AND week = $2 AND deleted_at IS NULL`
+	err := r.db.QueryRow(query, cohortID, week).Scan(&position)
+	return position, err
+}
+
+// Reorder moves the listed entries of a cohort to their week, in the given
+// order, in a single statement. Entries that are not listed but share a week
+// with a listed one, or were moved out of it, keep their relative order after
+// the listed ones. Other cohorts are left alone. The revisions of entries
+// that changed week are appended in the same transaction.
+func (r *CirriculumRepository) Reorder(cohortID *uuid.UUID, weeks []models.CirriculumWeekOrder, revisions []*models.Revision) error {
+	var ids []string
+	var weekNumbers, order []int64
+	for _, week := range weeks {
+		for i, id := range week.IDs {
+			ids = append(ids, id.String())
+			weekNumbers = append(weekNumbers, int64(week.Week))
+			order = append(order, int64(i))
+		}
+	}
+	query := `
+		WITH moves AS (
+			SELECT id::uuid AS id, week, ord
+			FROM unnest($1::text[], $2::int[], $3::int[]) AS m(id, week, ord)
+		), affected AS (
+			SELECT week FROM moves
+			UNION
+			SELECT c.week FROM cirriculum c JOIN moves m ON m.id = c.id
+		), ranked AS (
+			SELECT c.id, COALESCE(m.week, c.week) AS week,
+				ROW_NUMBER() OVER (
+					PARTITION BY COALESCE(m.week, c.week)
+					ORDER BY m.ord NULLS LAST, c.position, c.created_at
+				) - 1 AS position
+			FROM cirriculum c
+			LEFT JOIN moves m ON m.id = c.id
+			WHERE c.deleted_at IS NULL AND c.cohort_id IS NOT DISTINCT FROM $4
+				AND (m.id IS NOT NULL OR c.week IN (SELECT week FROM affected))
+		)
+		UPDATE cirriculum c
+		SET week = ranked.week, position = ranked.position
+		FROM ranked
+		WHERE c.id = ranked.id
+	`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, pq.Array(ids), pq.Array(weekNumbers), pq.Array(order), cohortID); err != nil {
+		return err
+	}
+	for _, revision := range revisions {
+		if err := insertRevision(tx, revision); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// weekKey matches the unique index on cirriculum_weeks, which treats the
+// shared weeks without a cohort as one cohort
+const weekKey = `(COALESCE(cohort_id, '00000000-0000-0000-0000-000000000000'::uuid)), week`
+
+// GetWeeks returns the weeks of a cohort, or of the shared cirriculum when
+// cohortID is nil, that have a title or theme set, keyed by week
+func (r *CirriculumRepository) GetWeeks(cohortID *uuid.UUID) (map[int]*models.CirriculumWeek, error) {
+	rows, err := r.db.Query(`SELECT week, title, theme FROM cirriculum_weeks WHERE cohort_id IS NOT DISTINCT FROM $1`, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weeks := make(map[int]*models.CirriculumWeek)
+	for rows.Next() {
+		week := &models.CirriculumWeek{}
+		if err := rows.Scan(&week.Week, &week.Title, &week.Theme); err != nil {
+			return nil, err
+		}
+		weeks[week.Week] = week
+	}
+	return weeks, rows.Err()
+}
+
+// SaveWeek sets the title and theme of a week of a cohort
+func (r *CirriculumRepository) SaveWeek(cohortID *uuid.UUID, week *models.CirriculumWeek, now time.Time) error {
+	query := `
+		INSERT INTO cirriculum_weeks (cohort_id, week, title, theme, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (` + weekKey + `) DO UPDATE SET title = EXCLUDED.title, theme = EXCLUDED.theme, updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Exec(query, cohortID, week.Week, week.Title, week.Theme, now)
 	return err
 }
+
+// Clone stores entries copied into a cohort together with their attachments,
+// prerequisites, translations and first revisions, and the copied week titles, in one
+// transaction. Week
+// titles only replace weeks of the cohort that have neither title nor theme.
+func (r *CirriculumRepository) Clone(cohortID uuid.UUID, entries []*models.Cirriculum, translations []*models.Translation, revisions []*models.Revision, weeks []*models.CirriculumWeek, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range entries {
+		_, err := tx.Exec(`
+			INSERT INTO cirriculum (id, slug, title, week, position, description, image_id, user_id, cohort_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, c.ID, c.Slug, c.Title, c.Week, c.Position, c.Content, c.ImageID, c.UserID, c.CohortID, c.CreatedAt)
+		if err != nil {
+			return err
+		}
+		for _, a := range c.Attachments {
+			_, err := tx.Exec(`
+				INSERT INTO cirriculum_attachments (id, cirriculum_id, type, title, position, url, asset_id, user_id, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`, a.ID, a.CirriculumID, a.Type, a.Title, a.Position, a.URL, a.AssetID, a.UserID, a.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Prerequisites go in once every copy exists, since they may point to a
+	// later copy in the same week
+	for _, c := range entries {
+		for _, prerequisiteID := range c.Prerequisites {
+			_, err := tx.Exec(`INSERT INTO cirriculum_prerequisites (cirriculum_id, prerequisite_id) VALUES ($1, $2)`, c.ID, prerequisiteID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, t := range translations {
+		_, err := tx.Exec(`
+			INSERT INTO translations (entity_type, entity_id, locale, title, content, user_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, t.EntityType, t.EntityID, t.Locale, t.Title, t.Content, t.UserID, t.CreatedAt, t.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rev := range revisions {
+		_, err := tx.Exec(`
+			INSERT INTO revisions (id, entity_type, entity_id, number, snapshot, user_id, created_at)
+			VALUES ($1, $2, $3, 1, $4, $5, $6)
+		`, rev.ID, rev.EntityType, rev.EntityID, []byte(rev.Snapshot), rev.UserID, rev.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	for _, week := range weeks {
+		_, err := tx.Exec(`
+			INSERT INTO cirriculum_weeks AS w (cohort_id, week, title, theme, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (`+weekKey+`) DO UPDATE SET title = EXCLUDED.title, theme = EXCLUDED.theme, updated_at = EXCLUDED.updated_at
+			WHERE w.title = '' AND w.theme = ''
+		`, cohortID, week.Week, week.Title, week.Theme, now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
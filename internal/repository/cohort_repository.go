@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const cohortColumns = `c.id, c.name, to_char(c.start_date, 'YYYY-MM-DD'), to_char(c.end_date, 'YYYY-MM-DD'), c.timezone, c.status, c.created_at,
+	(SELECT COUNT(*) FROM cohort_members m WHERE m.cohort_id = c.id)`
+
+type CohortRepository struct {
+	db *sql.DB
+}
+
+func NewCohortRepository(db *sql.DB) *CohortRepository {
+	return &CohortRepository{db: db}
+}
+
+func scanCohort(row rowScanner) (*models.Cohort, error) {
+	cohort := &models.Cohort{}
+	err := row.Scan(&cohort.ID, &cohort.Name, &cohort.StartDate, &cohort.EndDate, &cohort.Timezone, &cohort.Status, &cohort.CreatedAt, &cohort.MemberCount)
+	if err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+func (r *CohortRepository) queryCohorts(query string, args ...any) ([]*models.Cohort, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cohorts := make([]*models.Cohort, 0)
+	for rows.Next() {
+		cohort, err := scanCohort(rows)
+		if err != nil {
+			return nil, err
+		}
+		cohorts = append(cohorts, cohort)
+	}
+	return cohorts, rows.Err()
+}
+
+// GetCohorts returns the cohorts with the given status, or every cohort when
+// status is empty, latest first
+func (r *CohortRepository) GetCohorts(status string) ([]*models.Cohort, error) {
+	query := `
+		SELECT ` + cohortColumns + `
+		FROM cohorts c
+		WHERE $1 = '' OR c.status = $1
+		ORDER BY c.start_date DESC, c.name
+	`
+	return r.queryCohorts(query, status)
+}
+
+// GetUserCohorts returns the cohorts a user is enrolled in, latest first
+func (r *CohortRepository) GetUserCohorts(userID uuid.UUID) ([]*models.Cohort, error) {
+	query := `
+		SELECT ` + cohortColumns + `
+		FROM cohorts c
+		JOIN cohort_members cm ON cm.cohort_id = c.id
+		WHERE cm.user_id = $1
+		ORDER BY c.start_date DESC, c.name
+	`
+	return r.queryCohorts(query, userID)
+}
+
+func (r *CohortRepository) GetCohortByID(id uuid.UUID) (*models.Cohort, error) {
+	query := `
+		SELECT ` + cohortColumns + `
+		FROM cohorts c
+		WHERE c.id = $1
+	`
+	return scanCohort(r.db.QueryRow(query, id))
+}
+
+func (r *CohortRepository) CreateCohort(cohort *models.Cohort) error {
+	query := `
+		INSERT INTO cohorts (id, name, start_date, end_date, timezone, status, created_at)
+		VALUES ($1, $2, $3::date, $4::date, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query, cohort.ID, cohort.Name, cohort.StartDate, cohort.EndDate, cohort.Timezone, cohort.Status, cohort.CreatedAt)
+	return err
+}
+
+func (r *CohortRepository) UpdateCohort(cohort *models.Cohort) error {
+	query := `
+		UPDATE cohorts
+		SET name = $2, start_date = $3::date, end_date = $4::date, timezone = $5, status = $6
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, cohort.ID, cohort.Name, cohort.StartDate, cohort.EndDate, cohort.Timezone, cohort.Status)
+	return err
+}
+
+// NameTaken reports whether another cohort than id already uses name
+func (r *CohortRepository) NameTaken(name string, id uuid.UUID) (bool, error) {
+	var taken bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM cohorts WHERE lower(name) = lower($1) AND id <> $2)`, name, id).Scan(&taken)
+	return taken, err
+}
+
+// GetMembers returns the users enrolled in a cohort, by username
+func (r *CohortRepository) GetMembers(cohortID uuid.UUID) ([]*models.CohortMember, error) {
+	query := `
+		SELECT u.id, u.username, u.role, cm.enrolled_at
+		FROM cohort_members cm
+		JOIN users u ON u.id = cm.user_id
+		WHERE cm.cohort_id = $1
+		ORDER BY u.username
+	`
+	rows, err := r.db.Query(query, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*models.CohortMember, 0)
+	for rows.Next() {
+		member := &models.CohortMember{}
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role, &member.EnrolledAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// IsMember reports whether a user is enrolled in a cohort
+func (r *CohortRepository) IsMember(cohortID, userID uuid.UUID) (bool, error) {
+	var member bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM cohort_members WHERE cohort_id = $1 AND user_id = $2)`, cohortID, userID).Scan(&member)
+	return member, err
+}
+
+// AddMember enrolls a user in a cohort; enrolling them twice is a no-op
+func (r *CohortRepository) AddMember(cohortID, userID uuid.UUID, now time.Time) error {
+	query := `
+		INSERT INTO cohort_members (cohort_id, user_id, enrolled_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(query, cohortID, userID, now)
+	return err
+}
+
+// RemoveMember unenrolls a user, returning sql.ErrNoRows when they were not
+// enrolled
+func (r *CohortRepository) RemoveMember(cohortID, userID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM cohort_members WHERE cohort_id = $1 AND user_id = $2`, cohortID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
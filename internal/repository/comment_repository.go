@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const commentColumns = `c.id, c.news_id, c.parent_id, c.user_id, u.username, c.content, c.status, c.created_at, c.updated_at, c.edited_at`
+
+// commentVisible is the condition under which comment c is shown to the
+// viewer: visible comments to everyone, hidden ones to moderators ($2) and
+// their author ($3)
+const commentVisible = `(c.status = 'visible' OR (c.status = 'hidden' AND ($2 OR c.user_id = $3)))`
+
+type CommentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+func scanComment(row rowScanner) (*models.Comment, error) {
+	comment := &models.Comment{}
+	var editedAt sql.NullTime
+	err := row.Scan(&comment.ID, &comment.NewsID, &comment.ParentID, &comment.UserID, &comment.Username, &comment.Content, &comment.Status, &comment.CreatedAt, &comment.UpdatedAt, &editedAt)
+	if err != nil {
+		return nil, err
+	}
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+	return comment, nil
+}
+
+func (r *CommentRepository) queryComments(query string, args ...any) ([]*models.Comment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]*models.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// GetThreads returns a page of the top-level comments on a news item, oldest
+// first, and the total number of top-level comments the viewer can see.
+// Deleted comments are kept as placeholders while they still have replies.
+func (r *CommentRepository) GetThreads(newsID uuid.UUID, moderator bool, viewerID uuid.UUID, limit, offset int) ([]*models.Comment, int, error) {
+	where := `
+		WHERE c.news_id = $1 AND c.parent_id IS NULL AND (` + commentVisible + `
+			OR (c.status = 'deleted' AND EXISTS (
+				SELECT 1 FROM comments r WHERE r.parent_id = c.id AND r.status <> 'deleted')))
+	`
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM comments c`+where, newsID, moderator, viewerID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+	` + where + `
+		ORDER BY c.created_at, c.id
+		LIMIT $4 OFFSET $5
+	`
+	comments, err := r.queryComments(query, newsID, moderator, viewerID, limit, offset)
+	return comments, total, err
+}
+
+// GetReplies returns the replies the viewer can see to any of the given
+// comments, oldest first
+func (r *CommentRepository) GetReplies(parentIDs []uuid.UUID, moderator bool, viewerID uuid.UUID) ([]*models.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.parent_id = ANY($1::uuid[]) AND ` + commentVisible + `
+		ORDER BY c.created_at, c.id
+	`
+	return r.queryComments(query, pq.Array(uuidStrings(parentIDs)), moderator, viewerID)
+}
+
+func (r *CommentRepository) GetCommentByID(id uuid.UUID) (*models.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.id = $1
+	`
+	return scanComment(r.db.QueryRow(query, id))
+}
+
+func (r *CommentRepository) CreateComment(comment *models.Comment) error {
+	query := `
+		INSERT INTO comments (id, news_id, parent_id, user_id, content, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(query, comment.ID, comment.NewsID, comment.ParentID, comment.UserID, comment.Content, comment.Status, comment.CreatedAt, comment.UpdatedAt)
+	return err
+}
+
+func (r *CommentRepository) UpdateComment(comment *models.Comment) error {
+	query := `
+		UPDATE comments
+		SET content = $2, status = $3, updated_at = $4, edited_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, comment.ID, comment.Content, comment.Status, comment.UpdatedAt, comment.EditedAt)
+	return err
+}
+
+// SetLocked opens or closes the comments of a news item
+func (r *CommentRepository) SetLocked(newsID uuid.UUID, locked bool) error {
+	_, err := r.db.Exec(`UPDATE news SET comments_locked = $2 WHERE id = $1`, newsID, locked)
+	return err
+}
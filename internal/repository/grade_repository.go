@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const gradeColumns = `g.submission_id, g.points, g.feedback, g.grader_id, g.graded_at, g.updated_at`
+
+type GradeRepository struct {
+	db *sql.DB
+}
+
+func NewGradeRepository(db *sql.DB) *GradeRepository {
+	return &GradeRepository{db: db}
+}
+
+func scanGrade(row rowScanner) (*models.Grade, error) {
+	grade := &models.Grade{Scores: make([]models.CriterionScore, 0)}
+	err := row.Scan(&grade.SubmissionID, &grade.Points, &grade.Feedback, &grade.GraderID, &grade.GradedAt, &grade.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return grade, nil
+}
+
+// GetRubrics returns the rubric criteria of the given assignments in order,
+// grouped by assignment, in a single query
+func (r *GradeRepository) GetRubrics(assignmentIDs []uuid.UUID) (map[uuid.UUID][]*models.RubricCriterion, error) {
+	rubrics := make(map[uuid.UUID][]*models.RubricCriterion)
+	if len(assignmentIDs) == 0 {
+		return rubrics, nil
+	}
+
+	query := `
+		SELECT id, assignment_id, title, description, min_points, max_points, position
+		FROM rubric_criteria
+		WHERE assignment_id = ANY($1::uuid[])
+		ORDER BY position
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(assignmentIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		criterion := &models.RubricCriterion{}
+		if err := rows.Scan(&criterion.ID, &criterion.AssignmentID, &criterion.Title, &criterion.Description, &criterion.MinPoints, &criterion.MaxPoints, &criterion.Position); err != nil {
+			return nil, err
+		}
+		rubrics[criterion.AssignmentID] = append(rubrics[criterion.AssignmentID], criterion)
+	}
+	return rubrics, rows.Err()
+}
+
+// SetRubric replaces the rubric of an assignment with the given criteria
+func (r *GradeRepository) SetRubric(assignmentID uuid.UUID, criteria []*models.RubricCriterion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM rubric_criteria WHERE assignment_id = $1`, assignmentID); err != nil {
+		return err
+	}
+	for _, criterion := range criteria {
+		query := `
+			INSERT INTO rubric_criteria (id, assignment_id, title, description, min_points, max_points, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		if _, err := tx.Exec(query, criterion.ID, assignmentID, criterion.Title, criterion.Description, criterion.MinPoints, criterion.MaxPoints, criterion.Position); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// HasGrades reports whether any submission to an assignment was graded
+func (r *GradeRepository) HasGrades(assignmentID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM grades g JOIN submissions s ON s.id = g.submission_id
+			WHERE s.assignment_id = $1
+		)
+	`
+	var exists bool
+	err := r.db.QueryRow(query, assignmentID).Scan(&exists)
+	return exists, err
+}
+
+// HighestPoints returns the most points given to a submission to an
+// assignment, or 0 when none was graded
+func (r *GradeRepository) HighestPoints(assignmentID uuid.UUID) (float64, error) {
+	query := `
+		SELECT COALESCE(MAX(g.points), 0)
+		FROM grades g JOIN submissions s ON s.id = g.submission_id
+		WHERE s.assignment_id = $1
+	`
+	var points float64
+	err := r.db.QueryRow(query, assignmentID).Scan(&points)
+	return points, err
+}
+
+// GetGrades returns the grades of the given submissions with their rubric
+// scores, keyed by submission. Ungraded submissions are left out.
+func (r *GradeRepository) GetGrades(submissionIDs []uuid.UUID) (map[uuid.UUID]*models.Grade, error) {
+	grades := make(map[uuid.UUID]*models.Grade)
+	if len(submissionIDs) == 0 {
+		return grades, nil
+	}
+
+	query := `
+		SELECT ` + gradeColumns + `
+		FROM grades g
+		WHERE g.submission_id = ANY($1::uuid[])
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(submissionIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		grade, err := scanGrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		grades[grade.SubmissionID] = grade
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT gs.submission_id, gs.criterion_id, gs.points
+		FROM grade_scores gs
+		JOIN rubric_criteria rc ON rc.id = gs.criterion_id
+		WHERE gs.submission_id = ANY($1::uuid[])
+		ORDER BY rc.position
+	`
+	scoreRows, err := r.db.Query(query, pq.Array(uuidStrings(submissionIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer scoreRows.Close()
+
+	for scoreRows.Next() {
+		var submissionID uuid.UUID
+		var score models.CriterionScore
+		if err := scoreRows.Scan(&submissionID, &score.CriterionID, &score.Points); err != nil {
+			return nil, err
+		}
+		if grade, ok := grades[submissionID]; ok {
+			grade.Scores = append(grade.Scores, score)
+		}
+	}
+	return grades, scoreRows.Err()
+}
+
+// SaveGrade stores the grade of a submission with its rubric scores,
+// replacing an earlier grade. The time it was first graded is kept.
+func (r *GradeRepository) SaveGrade(grade *models.Grade) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO grades AS g (submission_id, points, feedback, grader_id, graded_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (submission_id)
+		DO UPDATE SET points = EXCLUDED.points, feedback = EXCLUDED.feedback, grader_id = EXCLUDED.grader_id, updated_at = EXCLUDED.updated_at
+		RETURNING g.graded_at
+	`
+	err = tx.QueryRow(query, grade.SubmissionID, grade.Points, grade.Feedback, grade.GraderID, grade.GradedAt, grade.UpdatedAt).Scan(&grade.GradedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM grade_scores WHERE submission_id = $1`, grade.SubmissionID); err != nil {
+		return err
+	}
+	for _, score := range grade.Scores {
+		_, err := tx.Exec(`INSERT INTO grade_scores (submission_id, criterion_id, points) VALUES ($1, $2, $3)`, grade.SubmissionID, score.CriterionID, score.Points)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCohortGrades returns, for every student and assignment of a cohort they
+// submitted to, the submission that counts: the latest graded attempt, or the
+// latest attempt when none is graded. Grades come without rubric scores. With
+// a userID only that student's submissions are returned.
+func (r *GradeRepository) GetCohortGrades(cohortID uuid.UUID, userID *uuid.UUID) ([]*models.Submission, error) {
+	query := `
+		SELECT DISTINCT ON (s.assignment_id, s.user_id)
+			s.id, s.assignment_id, s.user_id, s.attempt, s.submitted_at,
+			g.points, g.feedback, g.grader_id, g.graded_at, g.updated_at
+		FROM submissions s
+		JOIN assignments t ON t.id = s.assignment_id
+		LEFT JOIN grades g ON g.submission_id = s.id
+		WHERE t.cohort_id = $1 AND ($2::uuid IS NULL OR s.user_id = $2)
+		ORDER BY s.assignment_id, s.user_id, g.submission_id IS NOT NULL DESC, s.attempt DESC
+	`
+	rows, err := r.db.Query(query, cohortID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := make([]*models.Submission, 0)
+	for rows.Next() {
+		submission := &models.Submission{}
+		var points sql.NullFloat64
+		var feedback sql.NullString
+		var graderID uuid.NullUUID
+		var gradedAt, updatedAt sql.NullTime
+		err := rows.Scan(&submission.ID, &submission.AssignmentID, &submission.UserID, &submission.Attempt, &submission.SubmittedAt,
+			&points, &feedback, &graderID, &gradedAt, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if points.Valid {
+			submission.Grade = &models.Grade{
+				SubmissionID: submission.ID,
+				Points:       points.Float64,
+				Feedback:     feedback.String,
+				Scores:       make([]models.CriterionScore, 0),
+				GraderID:     graderID.UUID,
+				GradedAt:     gradedAt.Time,
+				UpdatedAt:    updatedAt.Time,
+			}
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions, rows.Err()
+}
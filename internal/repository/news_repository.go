@@ -2,10 +2,19 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const newsColumns = `n.id, n.slug, n.title, n.content, n.image_path, n.image_id, n.category, n.status, n.published_at, n.user_id, n.created_at, n.updated_at, n.comments_locked, n.pinned_at, n.pinned_until, n.featured, n.cohort_id, ` + authorColumns
+
+// newsFrom joins each news item n with its author u
+const newsFrom = `news n JOIN users u ON u.id = n.user_id`
+
 type NewsRepository struct {
 	db *sql.DB
 }
@@ -14,13 +23,37 @@ func NewNewsRepository(db *sql.DB) *NewsRepository {
 	return &NewsRepository{db: db}
 }
 
-func (r *NewsRepository) GetAllNews() ([]*models.News, error) {
-	query := `
-		SELECT id, title, content,image_path,category, user_id, created_at
-		FROM news
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query)
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNews(row rowScanner) (*models.News, error) {
+	news := &models.News{}
+	var imagePath, category sql.NullString
+	var publishedAt, pinnedAt, pinnedUntil sql.NullTime
+	author := &models.Author{}
+	err := row.Scan(&news.ID, &news.Slug, &news.Title, &news.Content, &imagePath, &news.ImageID, &category, &news.Status, &publishedAt, &news.UserID, &news.CreatedAt, &news.UpdatedAt, &news.CommentsLocked, &pinnedAt, &pinnedUntil, &news.Featured, &news.CohortID, &author.Username, &author.DisplayName, &author.AvatarID)
+	if err != nil {
+		return nil, err
+	}
+	author.ID = news.UserID
+	news.Author = author
+	news.ImagePath = imagePath.String
+	news.Category = category.String
+	if publishedAt.Valid {
+		news.PublishedAt = &publishedAt.Time
+	}
+	if pinnedAt.Valid {
+		news.PinnedAt = &pinnedAt.Time
+	}
+	if pinnedUntil.Valid {
+		news.PinnedUntil = &pinnedUntil.Time
+	}
+	return news, nil
+}
+
+func (r *NewsRepository) queryNews(query string, args ...any) ([]*models.News, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,21 +61,231 @@ func (r *NewsRepository) GetAllNews() ([]*models.News, error) {
 
 	var newsList []*models.News = make([]*models.News, 0)
 	for rows.Next() {
-		news := &models.News{}
-		err := rows.Scan(&news.ID, &news.Title, &news.Content, &news.ImagePath, &news.Category, &news.UserID, &news.CreatedAt)
+		news, err := scanNews(rows)
 		if err != nil {
 			return nil, err
 		}
 		newsList = append(newsList, news)
 	}
-	return newsList, nil
+	return newsList, rows.Err()
 }
 
-func (r *NewsRepository) CreateNews(news *models.News) error {
+// pinnedFirst orders news whose pin is active at $1 before the rest, most
+// recently pinned first, and everything else by publish date
+const pinnedFirst = `
+	ORDER BY (n.pinned_at IS NOT NULL AND (n.pinned_until IS NULL OR n.pinned_until > $1)) DESC,
+		n.pinned_at DESC NULLS LAST, n.published_at DESC
+`
+
+// GetPublishedNews returns the publicly visible news, pinned first and then
+// newest first, limited to one category unless category is empty. When
+// cohortID is not nil, only the news of that cohort and the news shared by
+// every cohort are returned. A limit above zero caps the number of news.
+func (r *NewsRepository) GetPublishedNews(category string, cohortID *uuid.UUID, limit int, now time.Time) ([]*models.News, error) {
 	query := `
-		INSERT INTO news (id, title, content,image_path,category, user_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		SELECT ` + newsColumns + `
+		FROM ` + newsFrom + `
+		WHERE n.status = 'published' AND ($2 = '' OR n.category = $2) AND n.deleted_at IS NULL
+			AND ($3::uuid IS NULL OR n.cohort_id IS NULL OR n.cohort_id = $3)
+	` + pinnedFirst + `
+		LIMIT NULLIF($4, 0)
 	`
-	_, err := r.db.Exec(query, news.ID, news.Title, news.Content, news.ImagePath, news.Category, news.UserID, news.CreatedAt)
+	return r.queryNews(query, now, category, cohortID, limit)
+}
+
+// GetFeaturedNews returns the published news flagged as featured, in the same
+// order as GetPublishedNews
+func (r *NewsRepository) GetFeaturedNews(now time.Time) ([]*models.News, error) {
+	query := `
+		SELECT ` + newsColumns + `
+		FROM ` + newsFrom + `
+		WHERE n.status = 'published' AND n.featured AND n.deleted_at IS NULL
+	` + pinnedFirst
+	return r.queryNews(query, now)
+}
+
+// GetUnpublishedNews returns drafts, scheduled and archived news. When
+// userID is nil the news of every author is returned.
+func (r *NewsRepository) GetUnpublishedNews(userID *uuid.UUID) ([]*models.News, error) {
+	query := `
+		SELECT ` + newsColumns + `
+		FROM ` + newsFrom + `
+		WHERE n.status <> 'published' AND ($1::uuid IS NULL OR n.user_id = $1) AND n.deleted_at IS NULL
+		ORDER BY n.updated_at DESC
+	`
+	return r.queryNews(query, userID)
+}
+
+func (r *NewsRepository) GetNewsByID(id uuid.UUID) (*models.News, error) {
+	query := `
+		SELECT ` + newsColumns + `
+		FROM ` + newsFrom + `
+		WHERE n.id = $1 AND n.deleted_at IS NULL
+	`
+	return scanNews(r.db.QueryRow(query, id))
+}
+
+func (r *NewsRepository) GetNewsBySlug(slug string) (*models.News, error) {
+	query := `
+		SELECT ` + newsColumns + `
+		FROM ` + newsFrom + `
+		WHERE n.slug = $1 AND n.deleted_at IS NULL
+	`
+	return scanNews(r.db.QueryRow(query, slug))
+}
+
+// CreateNews stores a news item together with its first revision
+func (r *NewsRepository) CreateNews(news *models.News, revision *models.Revision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO news (id, slug, title, content, image_path, image_id, category, status, published_at, user_id, created_at, updated_at, cohort_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = tx.Exec(query, news.ID, news.Slug, news.Title, news.Content, news.ImagePath, news.ImageID, news.Category, news.Status, news.PublishedAt, news.UserID, news.CreatedAt, news.UpdatedAt, news.CohortID)
+	if err != nil {
+		return err
+	}
+	if err := insertRevision(tx, revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateNews saves a news item and appends the revision recording the change
+// in one transaction. When the slug changed, oldSlug is kept as a redirect.
+func (r *NewsRepository) UpdateNews(news *models.News, oldSlug string, revision *models.Revision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE news
+		SET slug = $2, title = $3, content = $4, image_path = $5, image_id = $6, category = $7, status = $8, published_at = $9, updated_at = $10
+		WHERE id = $1
+	`
+	_, err = tx.Exec(query, news.ID, news.Slug, news.Title, news.Content, news.ImagePath, news.ImageID, news.Category, news.Status, news.PublishedAt, news.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := renameSlug(tx, SlugEntityNews, news.ID, oldSlug, news.Slug); err != nil {
+		return err
+	}
+	if err := insertRevision(tx, revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetPinned pins a news item until pinnedUntil, or indefinitely when it is
+// nil. A nil pinnedAt unpins it.
+func (r *NewsRepository) SetPinned(id uuid.UUID, pinnedAt, pinnedUntil *time.Time) error {
+	_, err := r.db.Exec(`UPDATE news SET pinned_at = $2, pinned_until = $3 WHERE id = $1`, id, pinnedAt, pinnedUntil)
+	return err
+}
+
+// DeleteNews moves a news item to the trash
+func (r *NewsRepository) DeleteNews(id uuid.UUID, now time.Time) error {
+	_, err := r.db.Exec(`UPDATE news SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, now)
+	return err
+}
+
+func (r *NewsRepository) SetFeatured(id uuid.UUID, featured bool) error {
+	_, err := r.db.Exec(`UPDATE news SET featured = $2 WHERE id = $1`, id, featured)
+	return err
+}
+
+// PublishDueNews flips every scheduled news item whose publish time has
+// passed to published. The conditional update is atomic, so several server
+// instances can run it concurrently without publishing an item twice.
+func (r *NewsRepository) PublishDueNews(now time.Time) (int64, error) {
+	query := `
+		UPDATE news
+		SET status = 'published', updated_at = $1
+		WHERE status = 'scheduled' AND published_at <= $1 AND deleted_at IS NULL
+	`
+	res, err := r.db.Exec(query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetReactionCounts returns how often each reaction was given to each of the
+// news items, in a single query
+func (r *NewsRepository) GetReactionCounts(newsIDs []uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	query := `
+		SELECT news_id, reaction, COUNT(*)
+		FROM news_reactions
+		WHERE news_id = ANY($1::uuid[])
+		GROUP BY news_id, reaction
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(newsIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]map[string]int)
+	for rows.Next() {
+		var newsID uuid.UUID
+		var reaction string
+		var count int
+		if err := rows.Scan(&newsID, &reaction, &count); err != nil {
+			return nil, err
+		}
+		if counts[newsID] == nil {
+			counts[newsID] = make(map[string]int)
+		}
+		counts[newsID][reaction] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetUserReactions returns the reactions a user gave to each of the news items
+func (r *NewsRepository) GetUserReactions(newsIDs []uuid.UUID, userID uuid.UUID) (map[uuid.UUID][]string, error) {
+	query := `
+		SELECT news_id, reaction
+		FROM news_reactions
+		WHERE news_id = ANY($1::uuid[]) AND user_id = $2
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(newsIDs)), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var newsID uuid.UUID
+		var reaction string
+		if err := rows.Scan(&newsID, &reaction); err != nil {
+			return nil, err
+		}
+		reactions[newsID] = append(reactions[newsID], reaction)
+	}
+	return reactions, rows.Err()
+}
+
+// AddReaction records a reaction; giving the same reaction twice is a no-op
+func (r *NewsRepository) AddReaction(newsID, userID uuid.UUID, reaction string, now time.Time) error {
+	query := `
+		INSERT INTO news_reactions (news_id, user_id, reaction, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(query, newsID, userID, reaction, now)
+	return err
+}
+
+func (r *NewsRepository) RemoveReaction(newsID, userID uuid.UUID, reaction string) error {
+	_, err := r.db.Exec(`DELETE FROM news_reactions WHERE news_id = $1 AND user_id = $2 AND reaction = $3`, newsID, userID, reaction)
 	return err
 }
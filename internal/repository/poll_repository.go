@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const pollColumns = `id, news_id, question, multiple, anonymous, results_visibility, closes_at, user_id, created_at`
+
+type PollRepository struct {
+	db *sql.DB
+}
+
+func NewPollRepository(db *sql.DB) *PollRepository {
+	return &PollRepository{db: db}
+}
+
+// GetPollByNewsID returns the poll of a news item with its options in order
+func (r *PollRepository) GetPollByNewsID(newsID uuid.UUID) (*models.Poll, error) {
+	query := `
+		SELECT ` + pollColumns + `
+		FROM polls
+		WHERE news_id = $1
+	`
+	poll := &models.Poll{}
+	var closesAt sql.NullTime
+	err := r.db.QueryRow(query, newsID).Scan(&poll.ID, &poll.NewsID, &poll.Question, &poll.Multiple, &poll.Anonymous, &poll.ResultsVisibility, &closesAt, &poll.UserID, &poll.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if closesAt.Valid {
+		poll.ClosesAt = &closesAt.Time
+	}
+
+	rows, err := r.db.Query(`SELECT id, text FROM poll_options WHERE poll_id = $1 ORDER BY position`, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	poll.Options = make([]*models.PollOption, 0)
+	for rows.Next() {
+		option := &models.PollOption{}
+		if err := rows.Scan(&option.ID, &option.Text); err != nil {
+			return nil, err
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	return poll, rows.Err()
+}
+
+// CreatePoll stores a poll and its options. It fails on the unique news_id
+// constraint when the news item already has a poll.
+func (r *PollRepository) CreatePoll(poll *models.Poll) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO polls (`+pollColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, poll.ID, poll.NewsID, poll.Question, poll.Multiple, poll.Anonymous, poll.ResultsVisibility, poll.ClosesAt, poll.UserID, poll.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for i, option := range poll.Options {
+		_, err := tx.Exec(`INSERT INTO poll_options (id, poll_id, position, text) VALUES ($1, $2, $3, $4)`, option.ID, poll.ID, i, option.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeletePoll removes a poll with its options and votes
+func (r *PollRepository) DeletePoll(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM polls WHERE id = $1`, id)
+	return err
+}
+
+// ClosePoll brings the closing time of a poll forward to now. Polls that
+// already closed keep their closing time.
+func (r *PollRepository) ClosePoll(id uuid.UUID, now time.Time) error {
+	_, err := r.db.Exec(`UPDATE polls SET closes_at = $2 WHERE id = $1 AND (closes_at IS NULL OR closes_at > $2)`, id, now)
+	return err
+}
+
+// Vote records a user's ballot with the chosen options. It returns false,
+// storing nothing, when the user has already voted.
+func (r *PollRepository) Vote(pollID, userID uuid.UUID, optionIDs []uuid.UUID, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO poll_ballots (poll_id, user_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, pollID, userID, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO poll_votes (poll_id, option_id, user_id, created_at)
+		SELECT $1, option_id::uuid, $2, $4
+		FROM unnest($3::text[]) AS option_id
+	`, pollID, userID, pq.Array(uuidStrings(optionIDs)), now)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetUserVotes returns the options a user chose, or nil when they have not
+// voted
+func (r *PollRepository) GetUserVotes(pollID, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT v.option_id
+		FROM poll_votes v
+		JOIN poll_options o ON o.id = v.option_id
+		WHERE v.poll_id = $1 AND v.user_id = $2
+		ORDER BY o.position
+	`
+	rows, err := r.db.Query(query, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		votes = append(votes, id)
+	}
+	return votes, rows.Err()
+}
+
+// GetVoteCounts returns the number of votes for each option of a poll and
+// the number of users who voted
+func (r *PollRepository) GetVoteCounts(pollID uuid.UUID) (map[uuid.UUID]int, int, error) {
+	query := `
+		SELECT option_id, COUNT(*)
+		FROM poll_votes
+		WHERE poll_id = $1
+		GROUP BY option_id
+	`
+	rows, err := r.db.Query(query, pollID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, 0, err
+		}
+		counts[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var voters int
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM poll_ballots WHERE poll_id = $1`, pollID).Scan(&voters)
+	return counts, voters, err
+}
+
+// GetVoters returns who voted for each option of a poll, earliest first
+func (r *PollRepository) GetVoters(pollID uuid.UUID) (map[uuid.UUID][]*models.PollVoter, error) {
+	query := `
+		SELECT v.option_id, v.user_id, u.username
+		FROM poll_votes v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.poll_id = $1
+		ORDER BY v.created_at, u.username
+	`
+	rows, err := r.db.Query(query, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	voters := make(map[uuid.UUID][]*models.PollVoter)
+	for rows.Next() {
+		var optionID uuid.UUID
+		voter := &models.PollVoter{}
+		if err := rows.Scan(&optionID, &voter.UserID, &voter.Username); err != nil {
+			return nil, err
+		}
+		voters[optionID] = append(voters[optionID], voter)
+	}
+	return voters, rows.Err()
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ProgressRepository struct {
+	db *sql.DB
+}
+
+func NewProgressRepository(db *sql.DB) *ProgressRepository {
+	return &ProgressRepository{db: db}
+}
+
+// GetProgress returns the progress recorded on the given entries, by every
+// user or, when userID is not nil, by that user only
+func (r *ProgressRepository) GetProgress(cirriculumIDs []uuid.UUID, userID *uuid.UUID) ([]*models.Progress, error) {
+	query := `
+		SELECT cirriculum_id, user_id, status, started_at, completed_at, updated_at
+		FROM cirriculum_progress
+		WHERE cirriculum_id = ANY($1::uuid[]) AND ($2::uuid IS NULL OR user_id = $2)
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(cirriculumIDs)), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := make([]*models.Progress, 0)
+	for rows.Next() {
+		p := &models.Progress{}
+		var completedAt sql.NullTime
+		if err := rows.Scan(&p.CirriculumID, &p.UserID, &p.Status, &p.StartedAt, &completedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if completedAt.Valid {
+			p.CompletedAt = &completedAt.Time
+		}
+		progress = append(progress, p)
+	}
+	return progress, rows.Err()
+}
+
+// SaveProgress records a user's status on an entry. The start time is kept
+// from the first time the entry was marked, and the completion time from
+// when it was completed until it is marked as started again.
+func (r *ProgressRepository) SaveProgress(p *models.Progress) error {
+	query := `
+		INSERT INTO cirriculum_progress AS cp (user_id, cirriculum_id, status, started_at, completed_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, cirriculum_id)
+		DO UPDATE SET status = EXCLUDED.status,
+			completed_at = CASE WHEN EXCLUDED.status = 'completed' THEN COALESCE(cp.completed_at, EXCLUDED.completed_at) END,
+			updated_at = EXCLUDED.updated_at
+		RETURNING started_at, completed_at
+	`
+	var completedAt sql.NullTime
+	err := r.db.QueryRow(query, p.UserID, p.CirriculumID, p.Status, p.StartedAt, p.CompletedAt, p.UpdatedAt).Scan(&p.StartedAt, &completedAt)
+	if err != nil {
+		return err
+	}
+	p.CompletedAt = nil
+	if completedAt.Valid {
+		p.CompletedAt = &completedAt.Time
+	}
+	return nil
+}
+
+// DeleteProgress resets a user's progress on an entry to not started
+func (r *ProgressRepository) DeleteProgress(userID, cirriculumID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM cirriculum_progress WHERE user_id = $1 AND cirriculum_id = $2`, userID, cirriculumID)
+	return err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RevisionEntityNews       = "news"
+	RevisionEntityCirriculum = "cirriculum"
+)
+
+const revisionColumns = `r.id, r.entity_type, r.entity_id, r.number, r.snapshot, r.user_id, u.username, r.created_at`
+
+// RevisionRepository stores the edit history of news and cirriculum items
+type RevisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+func scanRevision(row rowScanner) (*models.Revision, error) {
+	revision := &models.Revision{}
+	var snapshot []byte
+	err := row.Scan(&revision.ID, &revision.EntityType, &revision.EntityID, &revision.Number, &snapshot, &revision.UserID, &revision.Username, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	revision.Snapshot = snapshot
+	return revision, nil
+}
+
+// insertRevision appends a revision to the item's history within the
+// transaction that changed the item, numbering it after the latest one.
+// Callers update the item's row first, so its row lock keeps concurrent edits
+// from picking the same number.
+func insertRevision(tx *sql.Tx, revision *models.Revision) error {
+	query := `
+		INSERT INTO revisions (id, entity_type, entity_id, number, snapshot, user_id, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(number), 0) + 1, $4, $5, $6
+		FROM revisions
+		WHERE entity_type = $2 AND entity_id = $3
+		RETURNING number
+	`
+	return tx.QueryRow(query, revision.ID, revision.EntityType, revision.EntityID, []byte(revision.Snapshot), revision.UserID, revision.CreatedAt).Scan(&revision.Number)
+}
+
+// GetRevisions returns the history of an item, newest first
+func (r *RevisionRepository) GetRevisions(entity string, entityID uuid.UUID) ([]*models.Revision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM revisions r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.entity_type = $1 AND r.entity_id = $2
+		ORDER BY r.number DESC
+	`
+	rows, err := r.db.Query(query, entity, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.Revision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *RevisionRepository) GetRevision(entity string, entityID uuid.UUID, number int) (*models.Revision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM revisions r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.entity_type = $1 AND r.entity_id = $2 AND r.number = $3
+	`
+	return scanRevision(r.db.QueryRow(query, entity, entityID, number))
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SlugEntityNews       = "news"
+	SlugEntityCirriculum = "cirriculum"
+)
+
+// slugTables maps slug entity types to the table holding the current slugs
+var slugTables = map[string]string{
+	SlugEntityNews:       "news",
+	SlugEntityCirriculum: "cirriculum",
+}
+
+// SlugRepository tracks slug uniqueness and redirects from old slugs
+type SlugRepository struct {
+	db *sql.DB
+}
+
+func NewSlugRepository(db *sql.DB) *SlugRepository {
+	return &SlugRepository{db: db}
+}
+
+// IsTaken reports whether slug is used by another item of the entity type,
+// either as its current slug or as a redirect
+func (r *SlugRepository) IsTaken(entity, slug string, excludeID uuid.UUID) (bool, error) {
+	table, ok := slugTables[entity]
+	if !ok {
+		return false, fmt.Errorf("unknown slug entity %q", entity)
+	}
+	query := `
+		SELECT EXISTS(SELECT 1 FROM ` + table + ` WHERE slug = $1 AND id <> $2)
+			OR EXISTS(SELECT 1 FROM slug_redirects WHERE entity_type = $3 AND old_slug = $1 AND entity_id <> $2)
+	`
+	var taken bool
+	err := r.db.QueryRow(query, slug, excludeID, entity).Scan(&taken)
+	return taken, err
+}
+
+// renameSlug records oldSlug as a redirect to the item and drops any
+// redirect that pointed the new slug back at the same item, within the
+// transaction that renames the item. Nothing is recorded when the slug did
+// not change.
+func renameSlug(tx *sql.Tx, entity string, id uuid.UUID, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+	_, err := tx.Exec(`DELETE FROM slug_redirects WHERE entity_type = $1 AND old_slug = $2`, entity, newSlug)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO slug_redirects (entity_type, old_slug, entity_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id
+	`, entity, oldSlug, id)
+	return err
+}
+
+// FindRedirect returns the ID of the item that used to have slug
+func (r *SlugRepository) FindRedirect(entity, slug string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(`SELECT entity_id FROM slug_redirects WHERE entity_type = $1 AND old_slug = $2`, entity, slug).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	TranslationEntityNews       = "news"
+	TranslationEntityCirriculum = "cirriculum"
+)
+
+type TranslationRepository struct {
+	db *sql.DB
+}
+
+func NewTranslationRepository(db *sql.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+// GetTranslations returns every translation of the given items, grouped by
+// item and ordered by locale, in a single query
+func (r *TranslationRepository) GetTranslations(entity string, ids []uuid.UUID) (map[uuid.UUID][]*models.Translation, error) {
+	query := `
+		SELECT entity_type, entity_id, locale, title, content, user_id, created_at, updated_at
+		FROM translations
+		WHERE entity_type = $1 AND entity_id = ANY($2::uuid[])
+		ORDER BY locale
+	`
+	rows, err := r.db.Query(query, entity, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[uuid.UUID][]*models.Translation)
+	for rows.Next() {
+		t := &models.Translation{}
+		if err := rows.Scan(&t.EntityType, &t.EntityID, &t.Locale, &t.Title, &t.Content, &t.UserID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations[t.EntityID] = append(translations[t.EntityID], t)
+	}
+	return translations, rows.Err()
+}
+
+// SaveTranslation creates the translation or replaces an existing one for the
+// same locale
+func (r *TranslationRepository) SaveTranslation(t *models.Translation) error {
+	query := `
+		INSERT INTO translations (entity_type, entity_id, locale, title, content, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (entity_type, entity_id, locale)
+		DO UPDATE SET title = EXCLUDED.title, content = EXCLUDED.content, user_id = EXCLUDED.user_id, updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+	return r.db.QueryRow(query, t.EntityType, t.EntityID, t.Locale, t.Title, t.Content, t.UserID, t.CreatedAt, t.UpdatedAt).Scan(&t.CreatedAt)
+}
+
+// DeleteTranslation removes a translation, reporting whether it existed
+func (r *TranslationRepository) DeleteTranslation(entity string, id uuid.UUID, locale string) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM translations WHERE entity_type = $1 AND entity_id = $2 AND locale = $3`, entity, id, locale)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// trashTable describes where an item type in the trash is stored
+type trashTable struct {
+	table string
+	// title is the column shown as the item's title
+	title string
+	// history is whether the item has revisions, translations and slug
+	// redirects, stored under the same entity type, to purge with it
+	history bool
+	// cohort is whether the item can belong to a cohort
+	cohort bool
+	// purgeable is an extra condition rows must meet to be purged, for items
+	// that other content still depends on
+	purgeable string
+}
+
+var trashTables = map[string]trashTable{
+	models.TrashTypeNews:       {table: "news", title: "title", history: true, cohort: true},
+	models.TrashTypeCirriculum: {table: "cirriculum", title: "title", history: true, cohort: true},
+	models.TrashTypeAsset:      {table: "assets", title: "filename", purgeable: "NOT EXISTS (SELECT 1 FROM submissions WHERE asset_id = assets.id)"},
+}
+
+// trashOrder lists the item types in a stable order for queries over all of them
+var trashOrder = []string{models.TrashTypeNews, models.TrashTypeCirriculum, models.TrashTypeAsset}
+
+// TrashRepository lists, restores and permanently removes soft-deleted items
+type TrashRepository struct {
+	db *sql.DB
+}
+
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// trashQuery selects the trashed items of the given types, limited to those
+// deleted before $1 unless it is NULL
+func trashQuery(types []string) string {
+	parts := make([]string, len(types))
+	for i, t := range types {
+		table := trashTables[t]
+		parts[i] = fmt.Sprintf(`SELECT '%s' AS type, id, %s AS title, user_id, deleted_at FROM %s WHERE deleted_at IS NOT NULL AND ($1::timestamp IS NULL OR deleted_at < $1)`, t, table.title, table.table)
+	}
+	return strings.Join(parts, " UNION ALL ") + " ORDER BY deleted_at DESC"
+}
+
+func (r *TrashRepository) queryTrash(itemType string, before *time.Time) ([]*models.TrashItem, error) {
+	types := trashOrder
+	if itemType != "" {
+		if _, ok := trashTables[itemType]; !ok {
+			return nil, fmt.Errorf("unknown trash type %q", itemType)
+		}
+		types = []string{itemType}
+	}
+
+	rows, err := r.db.Query(trashQuery(types), before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.TrashItem, 0)
+	for rows.Next() {
+		item := &models.TrashItem{}
+		if err := rows.Scan(&item.Type, &item.ID, &item.Title, &item.UserID, &item.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetTrash returns the trashed items of a type, or of every type when
+// itemType is empty, most recently deleted first
+func (r *TrashRepository) GetTrash(itemType string) ([]*models.TrashItem, error) {
+	return r.queryTrash(itemType, nil)
+}
+
+// GetExpired returns the trashed items of every type deleted before cutoff
+func (r *TrashRepository) GetExpired(cutoff time.Time) ([]*models.TrashItem, error) {
+	return r.queryTrash("", &cutoff)
+}
+
+// GetCohortID returns the cohort a trashed item belongs to, or nil when it is
+// shared by every cohort or the item type has no cohort. It returns
+// sql.ErrNoRows when the item is not in the trash.
+func (r *TrashRepository) GetCohortID(itemType string, id uuid.UUID) (*uuid.UUID, error) {
+	table, ok := trashTables[itemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash type %q", itemType)
+	}
+	column := "NULL::uuid"
+	if table.cohort {
+		column = "cohort_id"
+	}
+	var cohortID *uuid.UUID
+	err := r.db.QueryRow(`SELECT `+column+` FROM `+table.table+` WHERE id = $1 AND deleted_at IS NOT NULL`, id).Scan(&cohortID)
+	return cohortID, err
+}
+
+// Restore takes an item out of the trash. It returns sql.ErrNoRows when the
+// item is not in the trash.
+func (r *TrashRepository) Restore(itemType string, id uuid.UUID) error {
+	table, ok := trashTables[itemType]
+	if !ok {
+		return fmt.Errorf("unknown trash type %q", itemType)
+	}
+	res, err := r.db.Exec(`UPDATE `+table.table+` SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Purge permanently deletes a trashed item with its history. For assets it
+// returns the storage keys of the file and its variants, which the caller
+// removes from storage once the rows are gone. It returns sql.ErrNoRows when
+// the item is not in the trash or other content still depends on it.
+func (r *TrashRepository) Purge(itemType string, id uuid.UUID) ([]string, error) {
+	table, ok := trashTables[itemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash type %q", itemType)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var keys []string
+	if itemType == models.TrashTypeAsset {
+		rows, err := tx.Query(`
+			SELECT storage_key FROM assets WHERE id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT v.storage_key FROM asset_variants v JOIN assets a ON a.id = v.asset_id
+			WHERE a.id = $1 AND a.deleted_at IS NOT NULL
+		`, id)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `DELETE FROM ` + table.table + ` WHERE id = $1 AND deleted_at IS NOT NULL`
+	if table.purgeable != "" {
+		query += ` AND ` + table.purgeable
+	}
+	res, err := tx.Exec(query, id)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if table.history {
+		for _, history := range []string{"revisions", "translations", "slug_redirects"} {
+			if _, err := tx.Exec(`DELETE FROM `+history+` WHERE entity_type = $1 AND entity_id = $2`, itemType, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return keys, tx.Commit()
+}
@@ -4,9 +4,15 @@ import (
 	"blazperic/radionica/internal/models"
 	"database/sql"
 
+	"github.com/google/uuid"
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
+// authorColumns selects the public profile of the author u of a content item.
+// The avatar is only exposed while it is a public, undeleted asset.
+const authorColumns = `u.username, COALESCE(u.display_name, u.username),
+	(SELECT a.id FROM assets a WHERE a.id = u.avatar_id AND a.visibility = 'public' AND a.deleted_at IS NULL)`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -17,23 +23,59 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) CreateUser(user *models.User) error {
 	query := `
-        INSERT INTO users (id, username, password, created_at)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO users (id, username, password, role, created_at)
+        VALUES ($1, $2, $3, $4, $5)
     `
-	_, err := r.db.Exec(query, user.ID, user.Username, user.Password, user.CreatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Username, user.Password, user.Role, user.CreatedAt)
 	return err
 }
 
 func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 	query := `
-        SELECT id, username, password, created_at
+        SELECT id, username, password, role, created_at
         FROM users
         WHERE username = $1
     `
 	user := &models.User{}
-	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password, &user.CreatedAt)
+	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
+	query := `
+        SELECT id, username, password, role, created_at
+        FROM users
+        WHERE id = $1
+    `
+	user := &models.User{}
+	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
+
+// GetProfile returns the public profile of a user
+func (r *UserRepository) GetProfile(id uuid.UUID) (*models.Author, error) {
+	query := `
+		SELECT u.id, ` + authorColumns + `
+		FROM users u
+		WHERE u.id = $1
+	`
+	author := &models.Author{}
+	err := r.db.QueryRow(query, id).Scan(&author.ID, &author.Username, &author.DisplayName, &author.AvatarID)
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// UpdateProfile sets the display name and avatar of a user. A nil display
+// name falls back to the username, a nil avatar removes it.
+func (r *UserRepository) UpdateProfile(id uuid.UUID, displayName *string, avatarID *uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE users SET display_name = $2, avatar_id = $3 WHERE id = $1`, id, displayName, avatarID)
+	return err
+}
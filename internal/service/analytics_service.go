@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTopNewsLimit = 10
+	MaxTopNewsLimit     = 100
+	// maxAnalyticsPeriod caps the number of days a single report can span
+	maxAnalyticsPeriod = 366
+)
+
+// AnalyticsService reports on news views. All reports are for admins.
+type AnalyticsService struct {
+	repo *repository.AnalyticsRepository
+	news *NewsService
+}
+
+func NewAnalyticsService(repo *repository.AnalyticsRepository, news *NewsService) *AnalyticsService {
+	return &AnalyticsService{repo: repo, news: news}
+}
+
+// NewsViews returns the daily views of a news item between from and to,
+// inclusive
+func (s *AnalyticsService) NewsViews(newsID uuid.UUID, from, to time.Time, actor *Actor) (*models.NewsViewStats, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	if _, err := s.news.getNews(newsID); err != nil {
+		return nil, err
+	}
+
+	daily, err := s.repo.GetDailyViews(newsID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := &models.NewsViewStats{NewsID: newsID, From: from, To: to, Daily: daily}
+	for _, d := range daily {
+		stats.Total += d.Views
+	}
+	return stats, nil
+}
+
+// TopNews returns the most viewed news items between from and to, inclusive
+func (s *AnalyticsService) TopNews(from, to time.Time, limit int, actor *Actor) (*models.TopNews, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = DefaultTopNewsLimit
+	}
+	if limit > MaxTopNewsLimit {
+		limit = MaxTopNewsLimit
+	}
+
+	news, err := s.repo.GetTopNews(from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TopNews{From: from, To: to, News: news}, nil
+}
+
+func checkPeriod(from, to time.Time) error {
+	if to.Before(from) {
+		return fmt.Errorf("%w: from is after to", ErrInvalidInput)
+	}
+	if to.Sub(from) > maxAnalyticsPeriod*24*time.Hour {
+		return fmt.Errorf("%w: period is longer than %d days", ErrInvalidInput, maxAnalyticsPeriod)
+	}
+	return nil
+}
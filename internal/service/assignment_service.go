@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/markdown"
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxAssignmentTitleLength matches the assignments.title column
+const maxAssignmentTitleLength = 200
+
+// AssignmentInput holds the editable fields of an assignment
+type AssignmentInput struct {
+	Title       string
+	Description string
+	Week        int
+	DueAt       *time.Time
+	MaxPoints   int
+	// AllowedTypes lists the file extensions students may submit, with or
+	// without the leading dot
+	AllowedTypes []string
+	AllowLinks   bool
+	// LatePolicy defaults to reject
+	LatePolicy  string
+	LatePenalty int
+	// Weight defaults to 1
+	Weight *int
+}
+
+// AssignmentService manages the homework of cohorts and the submissions to
+// it. Mentors set assignments; students enrolled in the cohort see them from
+// the start of their week and submit files or repository links.
+type AssignmentService struct {
+	repo         *repository.AssignmentRepository
+	grades       *repository.GradeRepository
+	cohorts      *repository.CohortRepository
+	uploads      *UploadService
+	programWeeks int
+}
+
+func NewAssignmentService(repo *repository.AssignmentRepository, grades *repository.GradeRepository, cohorts *repository.CohortRepository, uploads *UploadService, programWeeks int) *AssignmentService {
+	return &AssignmentService{repo: repo, grades: grades, cohorts: cohorts, uploads: uploads, programWeeks: programWeeks}
+}
+
+// ListAssignments returns the assignments of a cohort in the order they are
+// due, only those of one week when week is not 0. Students only see the
+// assignments of weeks that have started.
+func (s *AssignmentService) ListAssignments(cohortID uuid.UUID, week int, actor *Actor) ([]*models.Assignment, error) {
+	cohort, err := s.getCohortFor(cohortID, actor)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.repo.GetAssignments(cohortID, week)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hydrate(assignments, cohort, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	visible := assignments[:0]
+	for _, assignment := range assignments {
+		if assignment.Open || actor.IsMentor() {
+			visible = append(visible, assignment)
+		}
+	}
+	return visible, nil
+}
+
+// GetAssignment returns an assignment the actor can see
+func (s *AssignmentService) GetAssignment(id uuid.UUID, actor *Actor) (*models.Assignment, error) {
+	assignment, _, err := s.getAssignment(id, actor)
+	return assignment, err
+}
+
+// CreateAssignment sets homework for a week of a cohort. Mentors only.
+func (s *AssignmentService) CreateAssignment(cohortID uuid.UUID, input AssignmentInput, actor *Actor) (*models.Assignment, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	cohort, err := getCohort(s.cohorts, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	if cohort.Status == models.CohortStatusArchived {
+		return nil, errCohortArchived
+	}
+
+	now := time.Now().UTC()
+	assignment := &models.Assignment{
+		ID:        uuid.New(),
+		CohortID:  cohortID,
+		UserID:    actor.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(assignment, cohort, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAssignment(assignment); err != nil {
+		return nil, err
+	}
+	return assignment, s.hydrate([]*models.Assignment{assignment}, cohort, now)
+}
+
+// UpdateAssignment replaces the editable fields of an assignment. Submissions
+// already made keep their time, so moving the due date changes which of them
+// count as late. The max points must stay the rubric total and cannot drop
+// below points already given. Mentors only.
+func (s *AssignmentService) UpdateAssignment(id uuid.UUID, input AssignmentInput, actor *Actor) (*models.Assignment, error) {
+	assignment, cohort, err := s.getWritableAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	maxPoints := assignment.MaxPoints
+	if err := s.apply(assignment, cohort, input); err != nil {
+		return nil, err
+	}
+	if assignment.MaxPoints < maxPoints {
+		highest, err := s.grades.HighestPoints(id)
+		if err != nil {
+			return nil, err
+		}
+		if float64(assignment.MaxPoints) < highest {
+			return nil, fmt.Errorf("%w: max_points cannot be lower than the %g points already given", ErrConflict, highest)
+		}
+	}
+	if len(assignment.Rubric) > 0 {
+		if total := rubricTotal(assignment.Rubric); total != assignment.MaxPoints {
+			return nil, fmt.Errorf("%w: max_points must match the rubric total of %d", ErrInvalidInput, total)
+		}
+	}
+	assignment.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateAssignment(assignment); err != nil {
+		return nil, err
+	}
+	return assignment, s.hydrate([]*models.Assignment{assignment}, cohort, assignment.UpdatedAt)
+}
+
+// DeleteAssignment removes an assignment nobody has submitted to yet.
+// Mentors only.
+func (s *AssignmentService) DeleteAssignment(id uuid.UUID, actor *Actor) error {
+	if _, _, err := s.getWritableAssignment(id, actor); err != nil {
+		return err
+	}
+	submitted, err := s.repo.HasSubmissions(id)
+	if err != nil {
+		return err
+	}
+	if submitted {
+		return fmt.Errorf("%w: students have already submitted to the assignment", ErrConflict)
+	}
+	return s.repo.DeleteAssignment(id)
+}
+
+// SubmitLink submits a repository link as the actor's next attempt at an
+// assignment
+func (s *AssignmentService) SubmitLink(id uuid.UUID, link string, actor *Actor) (*models.Submission, error) {
+	assignment, err := s.getSubmittable(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !assignment.AllowLinks {
+		return nil, fmt.Errorf("%w: the assignment does not accept links", ErrInvalidInput)
+	}
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an http or https address", ErrInvalidInput)
+	}
+
+	return s.submit(assignment, &models.Submission{
+		Type: models.SubmissionTypeLink,
+		URL:  link,
+	}, actor)
+}
+
+// SubmitFile stores an uploaded file privately and submits it as the actor's
+// next attempt at an assignment. The file must have one of the extensions the
+// assignment allows.
+func (s *AssignmentService) SubmitFile(ctx context.Context, id uuid.UUID, filename string, r io.Reader, actor *Actor) (*models.Submission, error) {
+	assignment, err := s.getSubmittable(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(path.Base(filename))), ".")
+	if !slices.Contains(assignment.AllowedTypes, ext) {
+		if len(assignment.AllowedTypes) == 0 {
+			return nil, fmt.Errorf("%w: the assignment only accepts links", ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("%w: %q files; the assignment accepts %s", ErrUnsupported, ext, strings.Join(assignment.AllowedTypes, ", "))
+	}
+
+	asset, err := s.uploads.UploadFile(ctx, filename, r, models.AssetVisibilityPrivate, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.submit(assignment, &models.Submission{
+		Type:        models.SubmissionTypeFile,
+		AssetID:     &asset.ID,
+		Filename:    asset.Filename,
+		ContentType: asset.ContentType,
+		Size:        asset.Size,
+	}, actor)
+}
+
+// ListSubmissions returns each student's latest attempt at an assignment, or
+// every attempt when history is set, along with the students who have not
+// submitted. Mentors only.
+func (s *AssignmentService) ListSubmissions(id uuid.UUID, history bool, actor *Actor) (*models.SubmissionList, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	assignment, _, err := s.getAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.repo.GetSubmissions(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.cohorts.GetMembers(assignment.CohortID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillSubmissions(assignment, submissions, true); err != nil {
+		return nil, err
+	}
+
+	latest := latestSubmissions(submissions)
+	list := &models.SubmissionList{
+		AssignmentID: id,
+		DueAt:        assignment.DueAt,
+		Submitted:    len(latest),
+		Submissions:  submissions,
+		Missing:      make([]*models.CohortMember, 0),
+	}
+	if !history {
+		list.Submissions = latest
+	}
+	submitted := make(map[uuid.UUID]bool, len(latest))
+	for _, submission := range latest {
+		submitted[submission.UserID] = true
+		if submission.Late {
+			list.Late++
+		}
+	}
+	for _, member := range members {
+		if member.Role == models.RoleStudent && !submitted[member.UserID] {
+			list.Missing = append(list.Missing, member)
+		}
+	}
+	return list, nil
+}
+
+// ListMySubmissions returns the actor's attempts at an assignment, oldest
+// first. Their grades are included once the grades are released.
+func (s *AssignmentService) ListMySubmissions(id uuid.UUID, actor *Actor) ([]*models.Submission, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	assignment, _, err := s.getAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.repo.GetSubmissions(id, &actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillSubmissions(assignment, submissions, assignment.GradesReleasedAt != nil || actor.IsMentor()); err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+func (s *AssignmentService) submit(assignment *models.Assignment, submission *models.Submission, actor *Actor) (*models.Submission, error) {
+	submission.ID = uuid.New()
+	submission.AssignmentID = assignment.ID
+	submission.UserID = actor.UserID
+	// Stored without a zone like due_at, so both are kept in UTC
+	submission.SubmittedAt = time.Now().UTC()
+	if err := s.repo.CreateSubmission(submission); err != nil {
+		return nil, err
+	}
+	if err := s.fillSubmissions(assignment, []*models.Submission{submission}, false); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// getCohortFor returns a cohort whose assignments the actor may see: any
+// cohort for mentors, otherwise one they are enrolled in
+func (s *AssignmentService) getCohortFor(cohortID uuid.UUID, actor *Actor) (*models.Cohort, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	cohort, err := getCohort(s.cohorts, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.IsMentor() {
+		return cohort, nil
+	}
+	member, err := s.cohorts.IsMember(cohortID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("%w: only members of the cohort can see its assignments", ErrForbidden)
+	}
+	return cohort, nil
+}
+
+// getAssignment returns an assignment the actor can see, with its cohort.
+// Assignments of weeks that have not started are hidden from students.
+func (s *AssignmentService) getAssignment(id uuid.UUID, actor *Actor) (*models.Assignment, *models.Cohort, error) {
+	assignment, err := s.repo.GetAssignmentByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	cohort, err := s.getCohortFor(assignment.CohortID, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.hydrate([]*models.Assignment{assignment}, cohort, time.Now().UTC()); err != nil {
+		return nil, nil, err
+	}
+	if !assignment.Open && !actor.IsMentor() {
+		return nil, nil, ErrNotFound
+	}
+	return assignment, cohort, nil
+}
+
+// getWritableAssignment returns an assignment a mentor may change: one whose
+// cohort is not archived
+func (s *AssignmentService) getWritableAssignment(id uuid.UUID, actor *Actor) (*models.Assignment, *models.Cohort, error) {
+	if !actor.IsMentor() {
+		return nil, nil, ErrForbidden
+	}
+	assignment, cohort, err := s.getAssignment(id, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cohort.Status == models.CohortStatusArchived {
+		return nil, nil, errCohortArchived
+	}
+	return assignment, cohort, nil
+}
+
+// getSubmittable returns an assignment the actor may submit to: one of a
+// cohort they are enrolled in that is not archived, before the due date
+// unless the assignment takes late submissions
+func (s *AssignmentService) getSubmittable(id uuid.UUID, actor *Actor) (*models.Assignment, error) {
+	assignment, cohort, err := s.getAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.cohorts.IsMember(cohort.ID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("%w: only members of the cohort can submit", ErrForbidden)
+	}
+	if cohort.Status == models.CohortStatusArchived {
+		return nil, errCohortArchived
+	}
+	if assignment.LatePolicy == models.LatePolicyReject && time.Now().UTC().After(assignment.DueAt) {
+		return nil, fmt.Errorf("%w: the assignment was due at %s and takes no late submissions", ErrForbidden, assignment.DueAt.Format(time.RFC3339))
+	}
+	return assignment, nil
+}
+
+// apply validates input and sets it on an assignment of the given cohort
+func (s *AssignmentService) apply(assignment *models.Assignment, cohort *models.Cohort, input AssignmentInput) error {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(title) > maxAssignmentTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", ErrInvalidInput, maxAssignmentTitleLength)
+	}
+	if input.Week < 1 || input.Week > s.programWeeks {
+		return fmt.Errorf("%w: week must be between 1 and %d", ErrInvalidInput, s.programWeeks)
+	}
+	if input.DueAt == nil {
+		return fmt.Errorf("%w: due_at is required", ErrInvalidInput)
+	}
+	opensAt, err := weekStart(cohort, input.Week)
+	if err != nil {
+		return err
+	}
+	if input.DueAt.Before(opensAt) {
+		return fmt.Errorf("%w: due_at is before week %d starts on %s", ErrInvalidInput, input.Week, opensAt.Format(cohortDateLayout))
+	}
+	if input.MaxPoints < 1 {
+		return fmt.Errorf("%w: max_points must be positive", ErrInvalidInput)
+	}
+
+	allowedTypes := make([]string, 0, len(input.AllowedTypes))
+	for _, ext := range input.AllowedTypes {
+		ext = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ext)), ".")
+		if _, ok := allowedFileTypes["."+ext]; !ok {
+			return fmt.Errorf("%w: %q is not a supported file type", ErrInvalidInput, ext)
+		}
+		if !slices.Contains(allowedTypes, ext) {
+			allowedTypes = append(allowedTypes, ext)
+		}
+	}
+	sort.Strings(allowedTypes)
+	if len(allowedTypes) == 0 && !input.AllowLinks {
+		return fmt.Errorf("%w: the assignment must accept files or links", ErrInvalidInput)
+	}
+
+	latePolicy := input.LatePolicy
+	if latePolicy == "" {
+		latePolicy = models.LatePolicyReject
+	}
+	latePenalty := 0
+	switch latePolicy {
+	case models.LatePolicyReject, models.LatePolicyAccept:
+	case models.LatePolicyPenalty:
+		if input.LatePenalty < 1 || input.LatePenalty > 100 {
+			return fmt.Errorf("%w: late_penalty must be between 1 and 100 percent per day", ErrInvalidInput)
+		}
+		latePenalty = input.LatePenalty
+	default:
+		return fmt.Errorf("%w: unknown late policy %q", ErrInvalidInput, latePolicy)
+	}
+	weight := 1
+	if input.Weight != nil {
+		weight = *input.Weight
+	}
+	if weight < 0 {
+		return fmt.Errorf("%w: weight cannot be negative", ErrInvalidInput)
+	}
+
+	assignment.Title = title
+	assignment.Description = input.Description
+	assignment.Week = input.Week
+	assignment.DueAt = input.DueAt.UTC()
+	assignment.MaxPoints = input.MaxPoints
+	assignment.AllowedTypes = allowedTypes
+	assignment.AllowLinks = input.AllowLinks
+	assignment.LatePolicy = latePolicy
+	assignment.LatePenalty = latePenalty
+	assignment.Weight = weight
+	return nil
+}
+
+// fillSubmissions flags late submissions and signs the URLs of uploaded
+// files. With withGrades, the grades of graded submissions are included.
+func (s *AssignmentService) fillSubmissions(assignment *models.Assignment, submissions []*models.Submission, withGrades bool) error {
+	grades := make(map[uuid.UUID]*models.Grade)
+	if withGrades {
+		ids := make([]uuid.UUID, len(submissions))
+		for i, submission := range submissions {
+			ids[i] = submission.ID
+		}
+		var err error
+		if grades, err = s.grades.GetGrades(ids); err != nil {
+			return err
+		}
+	}
+	for _, submission := range submissions {
+		setLate(assignment, submission)
+		if submission.AssetID != nil {
+			submission.URL = s.uploads.PrivateURL(*submission.AssetID)
+		}
+		submission.Grade = grades[submission.ID]
+		applyPenalty(assignment, submission)
+	}
+	return nil
+}
+
+// hydrate renders the Markdown descriptions of a cohort's assignments, lists
+// their rubrics and works out whether their week has started
+func (s *AssignmentService) hydrate(assignments []*models.Assignment, cohort *models.Cohort, now time.Time) error {
+	ids := make([]uuid.UUID, len(assignments))
+	for i, assignment := range assignments {
+		ids[i] = assignment.ID
+	}
+	rubrics, err := s.grades.GetRubrics(ids)
+	if err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		opensAt, err := weekStart(cohort, assignment.Week)
+		if err != nil {
+			return err
+		}
+		assignment.OpensAt = opensAt
+		assignment.Open = !now.Before(opensAt)
+		assignment.DescriptionHTML = markdown.ToHTML(assignment.Description)
+		assignment.Rubric = append(make([]*models.RubricCriterion, 0), rubrics[assignment.ID]...)
+	}
+	return nil
+}
+
+// setLate flags a submission that came in after the assignment was due and
+// counts the days it is late, every started day counting in full
+func setLate(assignment *models.Assignment, submission *models.Submission) {
+	submission.Late = submission.SubmittedAt.After(assignment.DueAt)
+	submission.DaysLate = 0
+	if submission.Late {
+		late := submission.SubmittedAt.Sub(assignment.DueAt)
+		submission.DaysLate = int((late + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+}
+
+// latestSubmissions keeps each student's last attempt from submissions
+// ordered by student and attempt
+func latestSubmissions(submissions []*models.Submission) []*models.Submission {
+	latest := make([]*models.Submission, 0, len(submissions))
+	for i, submission := range submissions {
+		if i+1 == len(submissions) || submissions[i+1].UserID != submission.UserID {
+			latest = append(latest, submission)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"blazperic/radionica/internal/models"
+)
+
+func TestSetLate(t *testing.T) {
+	due := time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)
+	zagreb := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		name         string
+		submittedAt  time.Time
+		wantLate     bool
+		wantDaysLate int
+	}{
+		{"well before", due.Add(-72 * time.Hour), false, 0},
+		{"exactly on time", due, false, 0},
+		{"one nanosecond late", due.Add(time.Nanosecond), true, 1},
+		{"one minute late", due.Add(time.Minute), true, 1},
+		{"just under a day", due.Add(24*time.Hour - time.Second), true, 1},
+		{"exactly one day", due.Add(24 * time.Hour), true, 1},
+		{"just over a day", due.Add(24*time.Hour + time.Second), true, 2},
+		{"a week late", due.Add(7*24*time.Hour - time.Hour), true, 7},
+		// Same instant as the due date, written in another zone
+		{"on time in another zone", due.In(zagreb), false, 0},
+		{"late in another zone", due.Add(25 * time.Hour).In(zagreb), true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := &models.Assignment{DueAt: due}
+			submission := &models.Submission{SubmittedAt: tt.submittedAt, Late: true, DaysLate: 99}
+			setLate(assignment, submission)
+			if submission.Late != tt.wantLate || submission.DaysLate != tt.wantDaysLate {
+				t.Errorf("late, days late = %v, %d, want %v, %d", submission.Late, submission.DaysLate, tt.wantLate, tt.wantDaysLate)
+			}
+		})
+	}
+}
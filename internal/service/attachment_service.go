@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxAttachmentTitleLength matches the cirriculum_attachments.title column
+const maxAttachmentTitleLength = 200
+
+var (
+	youTubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoID   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// AttachmentInput holds a link or video added to a cirriculum entry
+type AttachmentInput struct {
+	Type  string
+	Title string
+	URL   string
+}
+
+// AttachmentPatch holds the attachment fields to change; nil fields are left
+// unchanged. Only links and videos have a URL to change.
+type AttachmentPatch struct {
+	Title *string
+	URL   *string
+}
+
+// AttachmentService manages the learning materials of cirriculum entries.
+// Anyone who can see an entry can see its attachments; only mentors can
+// change them.
+type AttachmentService struct {
+	repo       *repository.AttachmentRepository
+	cirriculum *CirriculumService
+	uploads    *UploadService
+}
+
+func NewAttachmentService(repo *repository.AttachmentRepository, cirriculum *CirriculumService, uploads *UploadService) *AttachmentService {
+	return &AttachmentService{repo: repo, cirriculum: cirriculum, uploads: uploads}
+}
+
+// ListAttachments returns the attachments of an entry in order
+func (s *AttachmentService) ListAttachments(cirriculumID uuid.UUID, actor *Actor) ([]*models.Attachment, error) {
+	cirriculum, err := s.cirriculum.GetCirriculum(cirriculumID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	return cirriculum.Attachments, nil
+}
+
+// AddAttachment adds a link or a video after the existing attachments of an
+// entry. Videos must be on YouTube or Vimeo so they can be embedded.
+func (s *AttachmentService) AddAttachment(cirriculumID uuid.UUID, input AttachmentInput, actor *Actor) (*models.Attachment, error) {
+	if _, err := s.getAttachableCirriculum(cirriculumID, actor); err != nil {
+		return nil, err
+	}
+	if input.Type != models.AttachmentTypeLink && input.Type != models.AttachmentTypeVideo {
+		return nil, fmt.Errorf("%w: attachment type must be link or video; upload files instead", ErrInvalidInput)
+	}
+	attachment := &models.Attachment{
+		ID:           uuid.New(),
+		CirriculumID: cirriculumID,
+		Type:         input.Type,
+		UserID:       actor.UserID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := applyAttachment(attachment, input.Title, input.URL); err != nil {
+		return nil, err
+	}
+	return s.create(attachment)
+}
+
+// UploadAttachment stores an uploaded file and adds it after the existing
+// attachments of an entry. The title defaults to the file name.
+func (s *AttachmentService) UploadAttachment(ctx context.Context, cirriculumID uuid.UUID, title, filename string, r io.Reader, actor *Actor) (*models.Attachment, error) {
+	if _, err := s.getAttachableCirriculum(cirriculumID, actor); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(title) == "" {
+		title = path.Base(filename)
+	}
+	attachment := &models.Attachment{
+		ID:           uuid.New(),
+		CirriculumID: cirriculumID,
+		Type:         models.AttachmentTypeFile,
+		UserID:       actor.UserID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := applyAttachment(attachment, title, ""); err != nil {
+		return nil, err
+	}
+
+	asset, err := s.uploads.UploadFile(ctx, filename, r, models.AssetVisibilityPublic, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	attachment.AssetID = &asset.ID
+	attachment.Filename = asset.Filename
+	attachment.ContentType = asset.ContentType
+	attachment.Size = asset.Size
+	return s.create(attachment)
+}
+
+// UpdateAttachment changes the title of an attachment, or the address of a
+// link or video
+func (s *AttachmentService) UpdateAttachment(cirriculumID, id uuid.UUID, patch AttachmentPatch, actor *Actor) (*models.Attachment, error) {
+	if _, err := s.getAttachableCirriculum(cirriculumID, actor); err != nil {
+		return nil, err
+	}
+	attachment, err := s.getAttachment(cirriculumID, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.URL != nil && attachment.Type == models.AttachmentTypeFile {
+		return nil, fmt.Errorf("%w: upload a new file instead of changing its url", ErrInvalidInput)
+	}
+	title, address := attachment.Title, attachment.URL
+	if patch.Title != nil {
+		title = *patch.Title
+	}
+	if patch.URL != nil {
+		address = *patch.URL
+	}
+	if err := applyAttachment(attachment, title, address); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateAttachment(attachment); err != nil {
+		return nil, err
+	}
+	fillAttachments(s.uploads, []*models.Attachment{attachment})
+	return attachment, nil
+}
+
+// ReorderAttachments sets the order of the attachments of an entry. Those
+// not listed keep their relative order after the listed ones.
+func (s *AttachmentService) ReorderAttachments(cirriculumID uuid.UUID, ids []uuid.UUID, actor *Actor) ([]*models.Attachment, error) {
+	if _, err := s.getAttachableCirriculum(cirriculumID, actor); err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetAttachments([]uuid.UUID{cirriculumID})
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[uuid.UUID]bool, len(current[cirriculumID]))
+	for _, attachment := range current[cirriculumID] {
+		known[attachment.ID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: attachment %s does not belong to the entry", ErrInvalidInput, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: attachment %s is listed twice", ErrInvalidInput, id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.repo.Reorder(cirriculumID, ids); err != nil {
+		return nil, err
+	}
+	reordered, err := s.repo.GetAttachments([]uuid.UUID{cirriculumID})
+	if err != nil {
+		return nil, err
+	}
+	attachments := append(make([]*models.Attachment, 0), reordered[cirriculumID]...)
+	fillAttachments(s.uploads, attachments)
+	return attachments, nil
+}
+
+// DeleteAttachment removes an attachment from an entry. Uploaded files stay
+// in the uploads, since clones of the entry may still list them.
+func (s *AttachmentService) DeleteAttachment(cirriculumID, id uuid.UUID, actor *Actor) error {
+	if _, err := s.getAttachableCirriculum(cirriculumID, actor); err != nil {
+		return err
+	}
+	if _, err := s.getAttachment(cirriculumID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteAttachment(id)
+}
+
+func (s *AttachmentService) create(attachment *models.Attachment) (*models.Attachment, error) {
+	position, err := s.repo.NextPosition(attachment.CirriculumID)
+	if err != nil {
+		return nil, err
+	}
+	attachment.Position = position
+	if err := s.repo.CreateAttachment(attachment); err != nil {
+		return nil, err
+	}
+	fillAttachments(s.uploads, []*models.Attachment{attachment})
+	return attachment, nil
+}
+
+// getAttachableCirriculum returns an entry whose attachments the actor may
+// change. Materials are published for the whole cohort, so only mentors
+// manage them, even on entries they did not write.
+func (s *AttachmentService) getAttachableCirriculum(id uuid.UUID, actor *Actor) (*models.Cirriculum, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	return s.cirriculum.getWritableCirriculum(id, actor)
+}
+
+// getAttachment returns an attachment of the given entry
+func (s *AttachmentService) getAttachment(cirriculumID, id uuid.UUID) (*models.Attachment, error) {
+	attachment, err := s.repo.GetAttachmentByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attachment.CirriculumID != cirriculumID {
+		return nil, ErrNotFound
+	}
+	return attachment, nil
+}
+
+// applyAttachment validates and sets the title of an attachment and, for
+// links and videos, its address
+func applyAttachment(attachment *models.Attachment, title, address string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(title) > maxAttachmentTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", ErrInvalidInput, maxAttachmentTitleLength)
+	}
+	attachment.Title = title
+	if attachment.Type == models.AttachmentTypeFile {
+		return nil
+	}
+
+	address = strings.TrimSpace(address)
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an http or https address", ErrInvalidInput)
+	}
+	if attachment.Type == models.AttachmentTypeVideo {
+		if _, ok := videoEmbedURL(u); !ok {
+			return fmt.Errorf("%w: videos must be YouTube or Vimeo addresses", ErrInvalidInput)
+		}
+	}
+	attachment.URL = address
+	return nil
+}
+
+// fillAttachments sets the URLs that are derived rather than stored: where
+// uploaded files are served and how videos are embedded
+func fillAttachments(uploads *UploadService, attachments []*models.Attachment) {
+	for _, attachment := range attachments {
+		switch attachment.Type {
+		case models.AttachmentTypeFile:
+			if attachment.AssetID != nil {
+				attachment.URL = uploads.PublicURL(*attachment.AssetID)
+			}
+		case models.AttachmentTypeVideo:
+			if u, err := url.Parse(attachment.URL); err == nil {
+				attachment.EmbedURL, _ = videoEmbedURL(u)
+			}
+		}
+	}
+}
+
+// videoEmbedURL returns the player address for a YouTube or Vimeo video
+func videoEmbedURL(u *url.URL) (string, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	var id string
+	switch host {
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		if segments[0] == "watch" {
+			id = u.Query().Get("v")
+		} else if len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live") {
+			id = segments[1]
+		}
+		if youTubeID.MatchString(id) {
+			return "https://www.youtube-nocookie.com/embed/" + id, true
+		}
+	case "youtu.be":
+		if id = segments[0]; youTubeID.MatchString(id) {
+			return "https://www.youtube-nocookie.com/embed/" + id, true
+		}
+	case "vimeo.com", "player.vimeo.com":
+		id = segments[len(segments)-1]
+		if vimeoID.MatchString(id) {
+			return "https://player.vimeo.com/video/" + id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestVideoEmbedURL(t *testing.T) {
+	const youTube = "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"
+
+	tests := []struct {
+		name   string
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"youtube watch", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", youTube, true},
+		{"youtube watch with more parameters", "https://youtube.com/watch?t=42&v=dQw4w9WgXcQ&list=PL1", youTube, true},
+		{"youtube mobile", "https://m.youtube.com/watch?v=dQw4w9WgXcQ", youTube, true},
+		{"youtube upper case host", "https://WWW.YouTube.com/watch?v=dQw4w9WgXcQ", youTube, true},
+		{"youtube short link", "https://youtu.be/dQw4w9WgXcQ?t=42", youTube, true},
+		{"youtube shorts", "https://www.youtube.com/shorts/dQw4w9WgXcQ", youTube, true},
+		{"youtube live", "https://www.youtube.com/live/dQw4w9WgXcQ", youTube, true},
+		{"youtube embed", "https://www.youtube.com/embed/dQw4w9WgXcQ", youTube, true},
+		{"youtube nocookie embed", "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", youTube, true},
+		{"youtube id with dash and underscore", "https://youtu.be/a-b_c-d_e-f", "https://www.youtube-nocookie.com/embed/a-b_c-d_e-f", true},
+		{"vimeo", "https://vimeo.com/76979871", "https://player.vimeo.com/video/76979871", true},
+		{"vimeo channel", "https://vimeo.com/channels/staffpicks/76979871", "https://player.vimeo.com/video/76979871", true},
+		{"vimeo player", "https://player.vimeo.com/video/76979871", "https://player.vimeo.com/video/76979871", true},
+		{"youtube watch without id", "https://www.youtube.com/watch", "", false},
+		{"youtube id too short", "https://www.youtube.com/watch?v=dQw4w9WgXc", "", false},
+		{"youtube id with invalid characters", "https://youtu.be/dQw4w9WgX%3C", "", false},
+		{"youtube channel", "https://www.youtube.com/@golang", "", false},
+		{"youtube shorts without id", "https://www.youtube.com/shorts", "", false},
+		{"youtube short link without id", "https://youtu.be/", "", false},
+		{"vimeo non-numeric", "https://vimeo.com/golang", "", false},
+		{"lookalike host", "https://youtube.com.example.com/watch?v=dQw4w9WgXcQ", "", false},
+		{"other host", "https://example.com/watch?v=dQw4w9WgXcQ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := videoEmbedURL(u)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("videoEmbedURL(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -43,7 +43,8 @@ func (s *AuthService) Register(username, password string) (*models.User, error)
 		ID:        uuid.New(),
 		Username:  username,
 		Password:  string(hashedPassword),
-		CreatedAt: time.Now(),
+		Role:      models.RoleStudent,
+		CreatedAt: time.Now().UTC(),
 	}
 
 	if err := s.repo.CreateUser(user); err != nil {
@@ -62,20 +63,7 @@ func (s *AuthService) Login(username, password string) (*TokenPair, error) {
 		return nil, errors.New("invalid credentials")
 	}
 
-	accessToken, err := s.generateToken(user.ID, s.tokenDuration)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := s.generateToken(user.ID, s.refreshTokenDuration)
-	if err != nil {
-		return nil, err
-	}
-
-	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	return s.generateTokenPair(user)
 }
 
 func (s *AuthService) RefreshToken(refreshToken string) (*TokenPair, error) {
@@ -105,26 +93,37 @@ func (s *AuthService) RefreshToken(refreshToken string) (*TokenPair, error) {
 		return nil, errors.New("invalid user_id in token")
 	}
 
-	newAccessToken, err := s.generateToken(userID, s.tokenDuration)
+	// Look the user up again so role changes take effect on refresh
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return s.generateTokenPair(user)
+}
+
+func (s *AuthService) generateTokenPair(user *models.User) (*TokenPair, error) {
+	accessToken, err := s.generateToken(user, s.tokenDuration)
 	if err != nil {
 		return nil, err
 	}
 
-	newRefreshToken, err := s.generateToken(userID, s.refreshTokenDuration)
+	refreshToken, err := s.generateToken(user, s.refreshTokenDuration)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *AuthService) generateToken(userID uuid.UUID, duration time.Duration) (string, error) {
+func (s *AuthService) generateToken(user *models.User, duration time.Duration) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),
-		"exp":     time.Now().Add(duration).Unix(),
+		"user_id": user.ID.String(),
+		"role":    user.Role,
+		"exp":     time.Now().UTC().Add(duration).Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(s.jwtSecret))
@@ -1,37 +1,895 @@
 package service
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"blazperic/radionica/internal/locale"
+	"blazperic/radionica/internal/markdown"
 	"blazperic/radionica/internal/models"
 	"blazperic/radionica/internal/repository"
 
 	"github.com/google/uuid"
 )
 
+// CirriculumInput holds the editable fields of a cirriculum entry
+type CirriculumInput struct {
+	Title       string
+	Description string
+	Week        int
+	ImageID     *uuid.UUID
+	// Slug changes the slug on update; the old one keeps redirecting
+	Slug string
+	// AvailableFrom releases the entry at a set time instead of at the start
+	// of its week
+	AvailableFrom *time.Time
+	// CohortID scopes a new entry to a cohort; it cannot be changed on update
+	CohortID *uuid.UUID
+}
+
+// CirriculumPatch holds the fields to change in a partial update. Nil fields
+// are left as they are.
+type CirriculumPatch struct {
+	Title       *string
+	Description *string
+	Week        *int
+	// ImageID is only changed when SetImage is true, so it can be cleared
+	ImageID  *uuid.UUID
+	SetImage bool
+	Slug     *string
+	// AvailableFrom is only changed when SetAvailableFrom is true
+	AvailableFrom    *time.Time
+	SetAvailableFrom bool
+}
+
+// CirriculumCloneInput selects the cirriculum to copy into a cohort
+type CirriculumCloneInput struct {
+	// SourceCohortID copies the shared cirriculum when nil
+	SourceCohortID *uuid.UUID
+	// WeekOffset is added to the week of every copied entry and week title
+	WeekOffset int
+	// DryRun reports what would be copied without storing anything
+	DryRun bool
+}
+
+// maxWeekTitleLength matches the cirriculum_weeks.title column
+const maxWeekTitleLength = 200
+
 type CirriculumService struct {
-	repo *repository.CirriculumRepository
+	repo         *repository.CirriculumRepository
+	cohorts      *repository.CohortRepository
+	slugs        *repository.SlugRepository
+	revisions    *repository.RevisionRepository
+	translations *repository.TranslationRepository
+	attachments  *repository.AttachmentRepository
+	uploads      *UploadService
+	locales      *locale.Set
+	// programWeeks is the length of the program; entries go in weeks 1 to
+	// programWeeks
+	programWeeks int
+}
+
+func NewCirriculumService(repo *repository.CirriculumRepository, cohorts *repository.CohortRepository, slugs *repository.SlugRepository, revisions *repository.RevisionRepository, translations *repository.TranslationRepository, attachments *repository.AttachmentRepository, uploads *UploadService, locales *locale.Set, programWeeks int) *CirriculumService {
+	return &CirriculumService{repo: repo, cohorts: cohorts, slugs: slugs, revisions: revisions, translations: translations, attachments: attachments, uploads: uploads, locales: locales, programWeeks: programWeeks}
+}
+
+// GetAllCirriculum returns the entries of a cohort, or the shared entries
+// when cohortID is nil, rendered in the given locale where translated.
+// Entries that are not released yet are left out unless the actor is a
+// mentor or wrote them.
+func (s *CirriculumService) GetAllCirriculum(cohortID *uuid.UUID, actor *Actor, locale string) ([]*models.Cirriculum, error) {
+	if err := s.checkCohort(cohortID); err != nil {
+		return nil, err
+	}
+	cirricula, err := s.repo.GetAllCirriculum(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hydrate(cirricula, locale); err != nil {
+		return nil, err
+	}
+	return visibleCirricula(cirricula, actor), nil
+}
+
+// GetCirriculum returns a single cirriculum entry. Entries that are not
+// released yet are only visible to mentors and their author.
+func (s *CirriculumService) GetCirriculum(id uuid.UUID, actor *Actor, locale string) (*models.Cirriculum, error) {
+	cirriculum, err := s.getCirriculum(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hydrate([]*models.Cirriculum{cirriculum}, locale); err != nil {
+		return nil, err
+	}
+	if !canSeeCirriculum(cirriculum, actor) {
+		return nil, ErrNotFound
+	}
+	return cirriculum, nil
+}
+
+// GetWeeks returns every week of the program with its title, theme and
+// entries in order. Weeks without entries are included; entries left in a
+// week beyond the program length get a week of their own.
+func (s *CirriculumService) GetWeeks(cohortID *uuid.UUID, actor *Actor, locale string) ([]*models.CirriculumWeek, error) {
+	cirricula, err := s.GetAllCirriculum(cohortID, actor, locale)
+	if err != nil {
+		return nil, err
+	}
+	weeks, err := s.repo.GetWeeks(cohortID)
+	if err != nil {
+		return nil, err
+	}
+
+	week := func(n int) *models.CirriculumWeek {
+		if weeks[n] == nil {
+			weeks[n] = &models.CirriculumWeek{Week: n}
+		}
+		return weeks[n]
+	}
+	for n := 1; n <= s.programWeeks; n++ {
+		week(n)
+	}
+	for _, cirriculum := range cirricula {
+		w := week(cirriculum.Week)
+		w.Entries = append(w.Entries, cirriculum)
+	}
+
+	result := make([]*models.CirriculumWeek, 0, len(weeks))
+	for n, w := range weeks {
+		if (n < 1 || n > s.programWeeks) && len(w.Entries) == 0 {
+			continue
+		}
+		if w.Entries == nil {
+			w.Entries = make([]*models.Cirriculum, 0)
+		}
+		result = append(result, w)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Week < result[j].Week })
+	return result, nil
 }
 
-func NewCirriculumService(repo *repository.CirriculumRepository) *CirriculumService {
-	return &CirriculumService{repo: repo}
+// GetWeek returns one week of the program with its entries in order
+func (s *CirriculumService) GetWeek(cohortID *uuid.UUID, n int, actor *Actor, locale string) (*models.CirriculumWeek, error) {
+	if err := s.checkCohort(cohortID); err != nil {
+		return nil, err
+	}
+	cirricula, err := s.repo.GetCirriculumByWeek(cohortID, n)
+	if err != nil {
+		return nil, err
+	}
+	if (n < 1 || n > s.programWeeks) && len(cirricula) == 0 {
+		return nil, ErrNotFound
+	}
+	if err := s.hydrate(cirricula, locale); err != nil {
+		return nil, err
+	}
+	cirricula = visibleCirricula(cirricula, actor)
+	weeks, err := s.repo.GetWeeks(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	week := &models.CirriculumWeek{Week: n}
+	if weeks[n] != nil {
+		week = weeks[n]
+	}
+	week.Entries = cirricula
+	return week, nil
 }
 
-func (s *CirriculumService) GetAllCirriculum() ([]*models.Cirriculum, error) {
-	return s.repo.GetAllCirriculum()
+// SaveWeek sets the title and theme of a week of a cohort, or of the shared
+// cirriculum when cohortID is nil. Mentors only.
+func (s *CirriculumService) SaveWeek(cohortID *uuid.UUID, n int, title, theme string, actor *Actor) (*models.CirriculumWeek, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	if err := s.checkWeek(n); err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.cohorts, cohortID); err != nil {
+		return nil, err
+	}
+	week := &models.CirriculumWeek{Week: n, Title: strings.TrimSpace(title), Theme: strings.TrimSpace(theme)}
+	if utf8.RuneCountInString(week.Title) > maxWeekTitleLength {
+		return nil, fmt.Errorf("%w: week title is limited to %d characters", ErrInvalidInput, maxWeekTitleLength)
+	}
+	if err := s.repo.SaveWeek(cohortID, week, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetWeek(cohortID, n, actor, "")
+}
+
+// GetCirriculumBySlug returns a cirriculum entry by its slug. When the slug is
+// one the entry had before being renamed, redirected is true and the returned
+// entry carries its current slug.
+func (s *CirriculumService) GetCirriculumBySlug(slug string, actor *Actor, locale string) (cirriculum *models.Cirriculum, redirected bool, err error) {
+	cirriculum, err = s.repo.GetCirriculumBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		id, redirectErr := s.slugs.FindRedirect(repository.SlugEntityCirriculum, slug)
+		if errors.Is(redirectErr, sql.ErrNoRows) {
+			return nil, false, ErrNotFound
+		}
+		if redirectErr != nil {
+			return nil, false, redirectErr
+		}
+		cirriculum, err = s.repo.GetCirriculumByID(id)
+		redirected = true
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, ErrNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.hydrate([]*models.Cirriculum{cirriculum}, locale); err != nil {
+		return nil, false, err
+	}
+	if !canSeeCirriculum(cirriculum, actor) {
+		return nil, false, ErrNotFound
+	}
+	return cirriculum, redirected, nil
 }
 
-func (s *CirriculumService) CreateCirriculum(title, description string, week int, userID uuid.UUID) (*models.Cirriculum, error) {
+// CreateCirriculum adds an entry at the end of its week, shared by every
+// cohort or scoped to one when input.CohortID is not nil
+func (s *CirriculumService) CreateCirriculum(input CirriculumInput, userID uuid.UUID) (*models.Cirriculum, error) {
+	if err := s.checkWeek(input.Week); err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.cohorts, input.CohortID); err != nil {
+		return nil, err
+	}
+	if input.ImageID != nil {
+		if _, err := s.uploads.ResolveImage(*input.ImageID); err != nil {
+			return nil, err
+		}
+	}
+	position, err := s.repo.NextPosition(input.CohortID, input.Week)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	slug, err := uniqueSlug(s.slugs, repository.SlugEntityCirriculum, input.Title, id)
+	if err != nil {
+		return nil, err
+	}
 	cirriculum := &models.Cirriculum{
-		ID:        uuid.New(),
-		Title:     title,
-		Week:      week,
-		Content:   description,
-		UserID:    userID,
-		CreatedAt: time.Now(),
+		ID:            id,
+		Slug:          slug,
+		Title:         input.Title,
+		Week:          input.Week,
+		Position:      position,
+		Content:       input.Description,
+		ImageID:       input.ImageID,
+		UserID:        userID,
+		CohortID:      input.CohortID,
+		AvailableFrom: utcTime(input.AvailableFrom),
+		CreatedAt:     time.Now().UTC(),
+	}
+	revision, err := s.newRevision(cirriculum, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCirriculum(cirriculum, revision); err != nil {
+		return nil, err
+	}
+	return cirriculum, s.hydrate([]*models.Cirriculum{cirriculum}, "")
+}
+
+// UpdateCirriculum replaces the editable fields of a cirriculum entry. An
+// entry moved to another week goes to the end of that week.
+func (s *CirriculumService) UpdateCirriculum(id uuid.UUID, input CirriculumInput, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getWritableCirriculum(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(cirriculum, input, actor)
+}
+
+// PatchCirriculum changes only the fields set in patch
+func (s *CirriculumService) PatchCirriculum(id uuid.UUID, patch CirriculumPatch, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getWritableCirriculum(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	input := CirriculumInput{
+		Title:         cirriculum.Title,
+		Description:   cirriculum.Content,
+		Week:          cirriculum.Week,
+		ImageID:       cirriculum.ImageID,
+		AvailableFrom: cirriculum.AvailableFrom,
+	}
+	if patch.Title != nil {
+		input.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		input.Description = *patch.Description
+	}
+	if patch.Week != nil {
+		input.Week = *patch.Week
+	}
+	if patch.SetImage {
+		input.ImageID = patch.ImageID
+	}
+	if patch.Slug != nil {
+		input.Slug = *patch.Slug
+	}
+	if patch.SetAvailableFrom {
+		input.AvailableFrom = patch.AvailableFrom
+	}
+	return s.update(cirriculum, input, actor)
+}
+
+func (s *CirriculumService) update(cirriculum *models.Cirriculum, input CirriculumInput, actor *Actor) (*models.Cirriculum, error) {
+	if strings.TrimSpace(input.Title) == "" || strings.TrimSpace(input.Description) == "" {
+		return nil, fmt.Errorf("%w: title and description are required", ErrInvalidInput)
+	}
+	if err := s.checkWeek(input.Week); err != nil {
+		return nil, err
+	}
+	if input.ImageID != nil && (cirriculum.ImageID == nil || *input.ImageID != *cirriculum.ImageID) {
+		if _, err := s.uploads.ResolveImage(*input.ImageID); err != nil {
+			return nil, err
+		}
+	}
+	if input.Week != cirriculum.Week {
+		if err := s.moveToWeek(cirriculum, input.Week); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	oldSlug := cirriculum.Slug
+	if cirriculum.Slug, err = changeSlug(s.slugs, repository.SlugEntityCirriculum, cirriculum.ID, cirriculum.Slug, input.Slug); err != nil {
+		return nil, err
+	}
+	cirriculum.Title = input.Title
+	cirriculum.Content = input.Description
+	cirriculum.Week = input.Week
+	cirriculum.ImageID = input.ImageID
+	cirriculum.AvailableFrom = utcTime(input.AvailableFrom)
+	revision, err := s.newRevision(cirriculum, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCirriculum(cirriculum, oldSlug, revision); err != nil {
+		return nil, err
+	}
+	return cirriculum, s.hydrate([]*models.Cirriculum{cirriculum}, "")
+}
+
+// ReorderCirriculum moves entries of a cohort, or the shared entries when
+// cohortID is nil, between weeks and sets their order within each listed
+// week, all at once. Mentors only.
+func (s *CirriculumService) ReorderCirriculum(cohortID *uuid.UUID, weeks []models.CirriculumWeekOrder, actor *Actor) ([]*models.Cirriculum, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	if err := checkCohortWritable(s.cohorts, cohortID); err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetAllCirriculum(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Cirriculum, len(current))
+	for _, cirriculum := range current {
+		byID[cirriculum.ID] = cirriculum
+	}
+
+	seenWeeks := make(map[int]bool, len(weeks))
+	seenIDs := make(map[uuid.UUID]bool)
+	var moved []*models.Cirriculum
+	for _, week := range weeks {
+		if err := s.checkWeek(week.Week); err != nil {
+			return nil, err
+		}
+		if seenWeeks[week.Week] {
+			return nil, fmt.Errorf("%w: week %d is listed twice", ErrInvalidInput, week.Week)
+		}
+		seenWeeks[week.Week] = true
+		for _, id := range week.IDs {
+			cirriculum, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: cirriculum entry %s does not exist", ErrInvalidInput, id)
+			}
+			if seenIDs[id] {
+				return nil, fmt.Errorf("%w: cirriculum entry %s is listed twice", ErrInvalidInput, id)
+			}
+			seenIDs[id] = true
+			if cirriculum.Week != week.Week {
+				cirriculum.Week = week.Week
+				moved = append(moved, cirriculum)
+			}
+		}
+	}
+
+	if len(moved) > 0 {
+		if err := s.checkPrerequisiteWeeks(cohortID, current); err != nil {
+			return nil, err
+		}
+	}
+	revisions := make([]*models.Revision, len(moved))
+	for i, cirriculum := range moved {
+		if revisions[i], err = s.newRevision(cirriculum, actor.UserID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Reorder(cohortID, weeks, revisions); err != nil {
+		return nil, err
+	}
+	return s.GetAllCirriculum(cohortID, actor, "")
+}
+
+// CloneCirriculum copies the entries of another cohort, or of the shared
+// cirriculum, into a cohort along with their translations and week titles,
+// shifting every week by the offset. Copies go after the entries the cohort
+// already has, and its week titles are only filled in where it has none.
+// Attachments are copied as references, so copies share uploaded files, and
+// prerequisites are linked between the copies.
+// Explicit release times are not copied, so copies follow the cohort's
+// schedule.
+// Everything is stored at once, or nothing on a dry run. Admins only.
+func (s *CirriculumService) CloneCirriculum(cohortID uuid.UUID, input CirriculumCloneInput, actor *Actor) (*models.CirriculumClone, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if input.SourceCohortID != nil && *input.SourceCohortID == cohortID {
+		return nil, fmt.Errorf("%w: cannot clone a cohort's cirriculum into itself", ErrInvalidInput)
 	}
-	if err := s.repo.CreateCirriculum(cirriculum); err != nil {
+	target, err := getCohort(s.cohorts, cohortID)
+	if err != nil {
 		return nil, err
 	}
+	if target.Status == models.CohortStatusArchived {
+		return nil, errCohortArchived
+	}
+	if err := s.checkCohort(input.SourceCohortID); err != nil {
+		return nil, err
+	}
+
+	sources, err := s.repo.GetAllCirriculum(input.SourceCohortID)
+	if err != nil {
+		return nil, err
+	}
+	sourceWeeks, err := s.repo.GetWeeks(input.SourceCohortID)
+	if err != nil {
+		return nil, err
+	}
+	targetWeeks, err := s.repo.GetWeeks(&cohortID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(sources))
+	for i, source := range sources {
+		ids[i] = source.ID
+	}
+	sourceTranslations, err := s.translations.GetTranslations(repository.TranslationEntityCirriculum, ids)
+	if err != nil {
+		return nil, err
+	}
+	sourceAttachments, err := s.attachments.GetAttachments(ids)
+	if err != nil {
+		return nil, err
+	}
+	sourcePrerequisites, err := s.repo.GetPrerequisites(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &models.CirriculumClone{
+		SourceCohortID: input.SourceCohortID,
+		TargetCohortID: cohortID,
+		WeekOffset:     input.WeekOffset,
+		DryRun:         input.DryRun,
+		Entries:        make([]*models.CirriculumCloneEntry, 0, len(sources)),
+		Weeks:          make([]*models.CirriculumCloneWeek, 0),
+	}
+	now := time.Now().UTC()
+	var entries []*models.Cirriculum
+	var translations []*models.Translation
+	var revisions []*models.Revision
+	positions := make(map[int]int)
+	slugs := make(map[string]bool)
+	for _, source := range sources {
+		week := source.Week + input.WeekOffset
+		if week < 1 || week > s.programWeeks {
+			return nil, fmt.Errorf("%w: %q would move to week %d, outside the program", ErrInvalidInput, source.Title, week)
+		}
+		position, ok := positions[week]
+		if !ok {
+			if position, err = s.repo.NextPosition(&cohortID, week); err != nil {
+				return nil, err
+			}
+		}
+		positions[week] = position + 1
+
+		entry := &models.Cirriculum{
+			ID:        uuid.New(),
+			Title:     source.Title,
+			Week:      week,
+			Position:  position,
+			Content:   source.Content,
+			ImageID:   source.ImageID,
+			UserID:    source.UserID,
+			CohortID:  &cohortID,
+			CreatedAt: now,
+		}
+		if entry.Slug, err = uniqueSlugExcept(s.slugs, repository.SlugEntityCirriculum, entry.Title, entry.ID, slugs); err != nil {
+			return nil, err
+		}
+		slugs[entry.Slug] = true
+		result := &models.CirriculumCloneEntry{
+			SourceID: source.ID,
+			Slug:     entry.Slug,
+			Title:    entry.Title,
+			Week:     entry.Week,
+			Position: entry.Position,
+			Locales:  make([]string, 0),
+		}
+		if !input.DryRun {
+			result.ID = &entry.ID
+		}
+		for _, t := range sourceTranslations[source.ID] {
+			translations = append(translations, &models.Translation{
+				EntityType: repository.TranslationEntityCirriculum,
+				EntityID:   entry.ID,
+				Locale:     t.Locale,
+				Title:      t.Title,
+				Content:    t.Content,
+				UserID:     t.UserID,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+			result.Locales = append(result.Locales, t.Locale)
+		}
+		for _, a := range sourceAttachments[source.ID] {
+			entry.Attachments = append(entry.Attachments, &models.Attachment{
+				ID:           uuid.New(),
+				CirriculumID: entry.ID,
+				Type:         a.Type,
+				Title:        a.Title,
+				Position:     a.Position,
+				URL:          a.URL,
+				AssetID:      a.AssetID,
+				UserID:       a.UserID,
+				CreatedAt:    now,
+			})
+		}
+		result.Attachments = len(entry.Attachments)
+		revision, err := newRevision(repository.RevisionEntityCirriculum, entry.ID, cirriculumSnapshot(entry), actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		revisions = append(revisions, revision)
+		clone.Entries = append(clone.Entries, result)
+	}
+
+	copies := make(map[uuid.UUID]uuid.UUID, len(sources))
+	for i, source := range sources {
+		copies[source.ID] = entries[i].ID
+	}
+	for i, source := range sources {
+		for _, prerequisiteID := range sourcePrerequisites[source.ID] {
+			if copyID, ok := copies[prerequisiteID]; ok {
+				entries[i].Prerequisites = append(entries[i].Prerequisites, copyID)
+			}
+		}
+	}
+
+	var weeks []*models.CirriculumWeek
+	for n, source := range sourceWeeks {
+		if source.Title == "" && source.Theme == "" {
+			continue
+		}
+		week := n + input.WeekOffset
+		if week < 1 || week > s.programWeeks {
+			return nil, fmt.Errorf("%w: the title of week %d would move to week %d, outside the program", ErrInvalidInput, n, week)
+		}
+		if target := targetWeeks[week]; target != nil && (target.Title != "" || target.Theme != "") {
+			continue
+		}
+		weeks = append(weeks, &models.CirriculumWeek{Week: week, Title: source.Title, Theme: source.Theme})
+		clone.Weeks = append(clone.Weeks, &models.CirriculumCloneWeek{Week: week, Title: source.Title, Theme: source.Theme})
+	}
+	sort.Slice(clone.Weeks, func(i, j int) bool { return clone.Weeks[i].Week < clone.Weeks[j].Week })
+
+	if input.DryRun {
+		return clone, nil
+	}
+	if err := s.repo.Clone(cohortID, entries, translations, revisions, weeks, now); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// DeleteCirriculum moves a cirriculum entry to the trash, from where admins
+// can restore it until it is purged. Its prerequisite links are removed and
+// have to be set again after a restore.
+func (s *CirriculumService) DeleteCirriculum(id uuid.UUID, actor *Actor) error {
+	if _, err := s.getWritableCirriculum(id, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteCirriculum(id, time.Now().UTC())
+}
+
+// ListRevisions returns the edit history of a cirriculum entry, newest first
+func (s *CirriculumService) ListRevisions(id uuid.UUID, actor *Actor) ([]*models.Revision, error) {
+	if _, err := s.getEditableCirriculum(id, actor); err != nil {
+		return nil, err
+	}
+	return s.revisions.GetRevisions(repository.RevisionEntityCirriculum, id)
+}
+
+func (s *CirriculumService) GetRevision(id uuid.UUID, number int, actor *Actor) (*models.Revision, error) {
+	if _, err := s.getEditableCirriculum(id, actor); err != nil {
+		return nil, err
+	}
+	return getRevision(s.revisions, repository.RevisionEntityCirriculum, id, number)
+}
+
+// DiffRevisions compares two revisions of a cirriculum entry
+func (s *CirriculumService) DiffRevisions(id uuid.UUID, from, to int, actor *Actor) (*models.RevisionDiff, error) {
+	if _, err := s.getEditableCirriculum(id, actor); err != nil {
+		return nil, err
+	}
+	return diffRevisions(s.revisions, repository.RevisionEntityCirriculum, id, from, to)
+}
+
+// RevertCirriculum restores a cirriculum entry to an earlier revision,
+// recording the result as a new revision. The slug is left as it is.
+func (s *CirriculumService) RevertCirriculum(id uuid.UUID, number int, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getWritableCirriculum(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := getRevision(s.revisions, repository.RevisionEntityCirriculum, id, number)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot models.CirriculumSnapshot
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.ImageID != nil {
+		if _, err := s.uploads.ResolveImage(*snapshot.ImageID); err != nil {
+			return nil, err
+		}
+	}
+
+	if snapshot.Week != cirriculum.Week {
+		if err := s.checkWeek(snapshot.Week); err != nil {
+			return nil, err
+		}
+		if err := s.moveToWeek(cirriculum, snapshot.Week); err != nil {
+			return nil, err
+		}
+	}
+	cirriculum.Title = snapshot.Title
+	cirriculum.Week = snapshot.Week
+	cirriculum.Content = snapshot.Content
+	cirriculum.ImageID = snapshot.ImageID
+	reverted, err := s.newRevision(cirriculum, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCirriculum(cirriculum, cirriculum.Slug, reverted); err != nil {
+		return nil, err
+	}
+	return cirriculum, s.hydrate([]*models.Cirriculum{cirriculum}, "")
+}
+
+// newRevision builds the revision recording the current state of an entry
+func (s *CirriculumService) newRevision(cirriculum *models.Cirriculum, userID uuid.UUID) (*models.Revision, error) {
+	return newRevision(repository.RevisionEntityCirriculum, cirriculum.ID, cirriculumSnapshot(cirriculum), userID)
+}
+
+func cirriculumSnapshot(cirriculum *models.Cirriculum) models.CirriculumSnapshot {
+	return models.CirriculumSnapshot{
+		Title:   cirriculum.Title,
+		Slug:    cirriculum.Slug,
+		Week:    cirriculum.Week,
+		Content: cirriculum.Content,
+		ImageID: cirriculum.ImageID,
+	}
+}
+
+// checkWeek rejects weeks outside the program
+// moveToWeek checks that an entry can move to another week without coming
+// before one of its prerequisites or after an entry requiring it, and places
+// it last in that week. The caller sets the week itself.
+func (s *CirriculumService) moveToWeek(cirriculum *models.Cirriculum, week int) error {
+	entries, err := s.repo.GetAllCirriculum(cirriculum.CohortID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ID == cirriculum.ID {
+			entry.Week = week
+		}
+	}
+	if err := s.checkPrerequisiteWeeks(cirriculum.CohortID, entries); err != nil {
+		return err
+	}
+	position, err := s.repo.NextPosition(cirriculum.CohortID, week)
+	if err != nil {
+		return err
+	}
+	cirriculum.Position = position
+	return nil
+}
+
+func (s *CirriculumService) checkWeek(week int) error {
+	if week < 1 || week > s.programWeeks {
+		return fmt.Errorf("%w: week must be between 1 and %d", ErrInvalidInput, s.programWeeks)
+	}
+	return nil
+}
+
+func (s *CirriculumService) getCirriculum(id uuid.UUID) (*models.Cirriculum, error) {
+	cirriculum, err := s.repo.GetCirriculumByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return cirriculum, err
+}
+
+// getEditableCirriculum returns an entry the actor may edit: their own, or
+// any entry for mentors
+func (s *CirriculumService) getEditableCirriculum(id uuid.UUID, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getCirriculum(id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Owns(cirriculum.UserID) && !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
 	return cirriculum, nil
 }
+
+// getWritableCirriculum is getEditableCirriculum for changes, which are
+// rejected once the entry's cohort is archived
+func (s *CirriculumService) getWritableCirriculum(id uuid.UUID, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getEditableCirriculum(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.cohorts, cirriculum.CohortID); err != nil {
+		return nil, err
+	}
+	return cirriculum, nil
+}
+
+// checkCohort rejects filtering by a cohort that does not exist
+func (s *CirriculumService) checkCohort(cohortID *uuid.UUID) error {
+	if cohortID == nil {
+		return nil
+	}
+	_, err := getCohort(s.cohorts, *cohortID)
+	return err
+}
+
+// SaveTranslation adds or replaces the translation of a cirriculum entry into
+// a non-default locale
+func (s *CirriculumService) SaveTranslation(id uuid.UUID, l, title, content string, actor *Actor) (*models.Translation, error) {
+	if _, err := s.getWritableCirriculum(id, actor); err != nil {
+		return nil, err
+	}
+	return saveTranslation(s.translations, s.locales, repository.TranslationEntityCirriculum, id, l, title, content, actor.UserID)
+}
+
+func (s *CirriculumService) DeleteTranslation(id uuid.UUID, l string, actor *Actor) error {
+	if _, err := s.getWritableCirriculum(id, actor); err != nil {
+		return err
+	}
+	return deleteTranslation(s.translations, s.locales, repository.TranslationEntityCirriculum, id, l)
+}
+
+// hydrate translates entries into the requested locale and renders their
+// Markdown descriptions
+func (s *CirriculumService) hydrate(cirricula []*models.Cirriculum, l string) error {
+	if len(cirricula) == 0 {
+		return nil
+	}
+	l = resolveLocale(s.locales, l)
+	ids := make([]uuid.UUID, len(cirricula))
+	for i, cirriculum := range cirricula {
+		ids[i] = cirriculum.ID
+	}
+
+	translations, err := s.translations.GetTranslations(repository.TranslationEntityCirriculum, ids)
+	if err != nil {
+		return err
+	}
+	attachments, err := s.attachments.GetAttachments(ids)
+	if err != nil {
+		return err
+	}
+	prerequisites, err := s.repo.GetPrerequisites(ids)
+	if err != nil {
+		return err
+	}
+	for _, cirriculum := range cirricula {
+		cirriculum.Attachments = append(make([]*models.Attachment, 0), attachments[cirriculum.ID]...)
+		fillAttachments(s.uploads, cirriculum.Attachments)
+		cirriculum.Prerequisites = append(make([]uuid.UUID, 0), prerequisites[cirriculum.ID]...)
+		cirriculum.Locale = s.locales.Default
+		cirriculum.AvailableLocales = availableLocales(s.locales, translations[cirriculum.ID])
+		if t := findTranslation(translations[cirriculum.ID], l); t != nil {
+			cirriculum.Title = t.Title
+			cirriculum.Content = t.Content
+			cirriculum.Locale = t.Locale
+		}
+		s.uploads.setAvatarURL(cirriculum.Author)
+	}
+	renderCirricula(cirricula)
+	return s.setRelease(cirricula, time.Now().UTC())
+}
+
+// setRelease works out when each entry is released: at its explicit
+// available_from time, or at midnight of the first day of its week in the
+// cohort's timezone. Shared entries without a release time are always out.
+func (s *CirriculumService) setRelease(cirricula []*models.Cirriculum, now time.Time) error {
+	cohorts := make(map[uuid.UUID]*models.Cohort)
+	for _, cirriculum := range cirricula {
+		cirriculum.ReleasesAt = cirriculum.AvailableFrom
+		if cirriculum.ReleasesAt == nil && cirriculum.CohortID != nil {
+			cohort, ok := cohorts[*cirriculum.CohortID]
+			if !ok {
+				var err error
+				if cohort, err = getCohort(s.cohorts, *cirriculum.CohortID); err != nil {
+					return err
+				}
+				cohorts[cohort.ID] = cohort
+			}
+			start, err := weekStart(cohort, cirriculum.Week)
+			if err != nil {
+				return err
+			}
+			cirriculum.ReleasesAt = &start
+		}
+		cirriculum.Released = cirriculum.ReleasesAt == nil || !now.Before(*cirriculum.ReleasesAt)
+	}
+	return nil
+}
+
+// canSeeCirriculum hides entries that are not released yet from everyone but
+// mentors and their author
+func canSeeCirriculum(cirriculum *models.Cirriculum, actor *Actor) bool {
+	return cirriculum.Released || actor.IsMentor() || actor.Owns(cirriculum.UserID)
+}
+
+// visibleCirricula drops the entries the actor may not see yet
+func visibleCirricula(cirricula []*models.Cirriculum, actor *Actor) []*models.Cirriculum {
+	visible := cirricula[:0]
+	for _, cirriculum := range cirricula {
+		if canSeeCirriculum(cirriculum, actor) {
+			visible = append(visible, cirriculum)
+		}
+	}
+	return visible
+}
+
+// utcTime stores times in UTC so they compare the same whatever zone the
+// client sent them in
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// renderCirricula renders the Markdown description of each entry
+func renderCirricula(cirricula []*models.Cirriculum) {
+	for _, cirriculum := range cirricula {
+		cirriculum.ContentHTML = markdown.ToHTML(cirriculum.Content)
+		cirriculum.Excerpt = markdown.Excerpt(cirriculum.ContentHTML, markdown.ExcerptLength)
+	}
+}
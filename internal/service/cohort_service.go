@@ -0,0 +1,286 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// cohortDateLayout is the format of cohort start and end dates
+	cohortDateLayout = "2006-01-02"
+	// maxCohortNameLength matches the cohorts.name column
+	maxCohortNameLength = 100
+)
+
+// errCohortArchived rejects changes to an archived cohort or its content
+var errCohortArchived = fmt.Errorf("%w: the cohort is archived and read-only", ErrForbidden)
+
+// CohortInput holds the editable fields of a cohort
+type CohortInput struct {
+	Name      string
+	StartDate string
+	EndDate   string
+	// Timezone is an IANA zone name. It defaults to the configured zone on
+	// create and is kept on update when empty.
+	Timezone string
+	// Status defaults to planned when empty
+	Status string
+}
+
+// CohortService manages the editions of the workshop and who is enrolled in
+// them. Admins manage cohorts; mentors manage enrollment.
+type CohortService struct {
+	repo  *repository.CohortRepository
+	users *repository.UserRepository
+	// defaultTimezone applies to cohorts created without a timezone
+	defaultTimezone string
+}
+
+func NewCohortService(repo *repository.CohortRepository, users *repository.UserRepository, defaultTimezone string) *CohortService {
+	return &CohortService{repo: repo, users: users, defaultTimezone: defaultTimezone}
+}
+
+// ListCohorts returns the cohorts with the given status, or every cohort when
+// status is empty
+func (s *CohortService) ListCohorts(status string) ([]*models.Cohort, error) {
+	if status != "" {
+		if err := checkCohortStatus(status); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetCohorts(status)
+}
+
+// ListUserCohorts returns the cohorts the actor is enrolled in
+func (s *CohortService) ListUserCohorts(actor *Actor) ([]*models.Cohort, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	return s.repo.GetUserCohorts(actor.UserID)
+}
+
+func (s *CohortService) GetCohort(id uuid.UUID) (*models.Cohort, error) {
+	return getCohort(s.repo, id)
+}
+
+// CreateCohort adds an edition of the workshop. Admins only.
+func (s *CohortService) CreateCohort(input CohortInput, actor *Actor) (*models.Cohort, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	cohort := &models.Cohort{ID: uuid.New(), Status: models.CohortStatusPlanned, CreatedAt: time.Now().UTC()}
+	if input.Timezone == "" {
+		input.Timezone = s.defaultTimezone
+	}
+	if err := s.apply(cohort, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCohort(cohort); err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+// UpdateCohort replaces the fields of a cohort. Archived cohorts can only be
+// taken out of the archive through SetStatus. Admins only.
+func (s *CohortService) UpdateCohort(id uuid.UUID, input CohortInput, actor *Actor) (*models.Cohort, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	cohort, err := s.getWritableCohort(id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Status == "" {
+		input.Status = cohort.Status
+	}
+	if input.Timezone == "" {
+		input.Timezone = cohort.Timezone
+	}
+	if err := s.apply(cohort, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCohort(cohort); err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+// SetStatus moves a cohort between planned, active and archived. Admins only.
+func (s *CohortService) SetStatus(id uuid.UUID, status string, actor *Actor) (*models.Cohort, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if err := checkCohortStatus(status); err != nil {
+		return nil, err
+	}
+	cohort, err := getCohort(s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	cohort.Status = status
+	if err := s.repo.UpdateCohort(cohort); err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+// ListMembers returns the users enrolled in a cohort. Mentors only.
+func (s *CohortService) ListMembers(id uuid.UUID, actor *Actor) ([]*models.CohortMember, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	if _, err := getCohort(s.repo, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(id)
+}
+
+// Enroll adds a user to a cohort that is not archived. Mentors only.
+func (s *CohortService) Enroll(id, userID uuid.UUID, actor *Actor) ([]*models.CohortMember, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	if _, err := s.getWritableCohort(id); err != nil {
+		return nil, err
+	}
+	if _, err := s.users.FindByID(userID); errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalidInput, userID)
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddMember(id, userID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(id)
+}
+
+// Unenroll removes a user from a cohort that is not archived. Mentors only.
+func (s *CohortService) Unenroll(id, userID uuid.UUID, actor *Actor) error {
+	if !actor.IsMentor() {
+		return ErrForbidden
+	}
+	if _, err := s.getWritableCohort(id); err != nil {
+		return err
+	}
+	err := s.repo.RemoveMember(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// apply validates input and copies it onto cohort
+func (s *CohortService) apply(cohort *models.Cohort, input CohortInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > maxCohortNameLength {
+		return fmt.Errorf("%w: name is limited to %d characters", ErrInvalidInput, maxCohortNameLength)
+	}
+	start, err := time.Parse(cohortDateLayout, input.StartDate)
+	if err != nil {
+		return fmt.Errorf("%w: start_date must be a date like 2026-03-02", ErrInvalidInput)
+	}
+	end, err := time.Parse(cohortDateLayout, input.EndDate)
+	if err != nil {
+		return fmt.Errorf("%w: end_date must be a date like 2026-05-22", ErrInvalidInput)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidInput)
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil || input.Timezone == "" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, input.Timezone)
+	}
+	status := input.Status
+	if status == "" {
+		status = models.CohortStatusPlanned
+	}
+	if err := checkCohortStatus(status); err != nil {
+		return err
+	}
+	taken, err := s.repo.NameTaken(name, cohort.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: a cohort named %q already exists", ErrConflict, name)
+	}
+
+	cohort.Name = name
+	cohort.StartDate = start.Format(cohortDateLayout)
+	cohort.EndDate = end.Format(cohortDateLayout)
+	cohort.Timezone = input.Timezone
+	cohort.Status = status
+	return nil
+}
+
+func (s *CohortService) getWritableCohort(id uuid.UUID) (*models.Cohort, error) {
+	cohort, err := getCohort(s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if cohort.Status == models.CohortStatusArchived {
+		return nil, errCohortArchived
+	}
+	return cohort, nil
+}
+
+func getCohort(repo *repository.CohortRepository, id uuid.UUID) (*models.Cohort, error) {
+	cohort, err := repo.GetCohortByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return cohort, err
+}
+
+// checkCohortWritable lets content be added to or changed in a cohort unless
+// the cohort is archived. Content without a cohort is always writable.
+func checkCohortWritable(repo *repository.CohortRepository, cohortID *uuid.UUID) error {
+	if cohortID == nil {
+		return nil
+	}
+	cohort, err := getCohort(repo, *cohortID)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: cohort %s does not exist", ErrInvalidInput, *cohortID)
+	}
+	if err != nil {
+		return err
+	}
+	if cohort.Status == models.CohortStatusArchived {
+		return errCohortArchived
+	}
+	return nil
+}
+
+// weekStart returns midnight in the cohort's timezone on the first day of a
+// week of the program, counting from the cohort start date
+func weekStart(cohort *models.Cohort, week int) (time.Time, error) {
+	loc, err := time.LoadLocation(cohort.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, err := time.ParseInLocation(cohortDateLayout, cohort.StartDate, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.AddDate(0, 0, 7*(week-1)), nil
+}
+
+func checkCohortStatus(status string) error {
+	switch status {
+	case models.CohortStatusPlanned, models.CohortStatusActive, models.CohortStatusArchived:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown cohort status %q", ErrInvalidInput, status)
+}
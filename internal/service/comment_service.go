@@ -0,0 +1,279 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/markdown"
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultCommentPageSize = 20
+	MaxCommentPageSize     = 100
+)
+
+type CommentService struct {
+	repo *repository.CommentRepository
+	news *NewsService
+}
+
+func NewCommentService(repo *repository.CommentRepository, news *NewsService) *CommentService {
+	return &CommentService{repo: repo, news: news}
+}
+
+// ListComments returns a page of the top-level comments on a news item with
+// their replies. Hidden comments are only listed for mentors and their author.
+func (s *CommentService) ListComments(newsID uuid.UUID, page, pageSize int, actor *Actor) (*models.CommentPage, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	news, err := s.news.GetNews(newsID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultCommentPageSize
+	}
+	if pageSize > MaxCommentPageSize {
+		pageSize = MaxCommentPageSize
+	}
+
+	threads, total, err := s.repo.GetThreads(newsID, actor.IsMentor(), actor.UserID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) > 0 {
+		ids := make([]uuid.UUID, len(threads))
+		byID := make(map[uuid.UUID]*models.Comment, len(threads))
+		for i, thread := range threads {
+			ids[i] = thread.ID
+			byID[thread.ID] = thread
+		}
+		replies, err := s.repo.GetReplies(ids, actor.IsMentor(), actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, reply := range replies {
+			parent := byID[*reply.ParentID]
+			parent.Replies = append(parent.Replies, reply)
+		}
+	}
+	for _, thread := range threads {
+		renderComment(thread)
+		for _, reply := range thread.Replies {
+			renderComment(reply)
+		}
+	}
+
+	return &models.CommentPage{
+		Comments: threads,
+		Locked:   news.CommentsLocked,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// CreateComment adds a comment to a news item, or a reply when parentID is
+// set. Replies can only be made to top-level comments.
+func (s *CommentService) CreateComment(newsID uuid.UUID, parentID *uuid.UUID, content string, actor *Actor) (*models.Comment, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: comment is empty", ErrInvalidInput)
+	}
+	news, err := s.news.GetNews(newsID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.news.cohorts, news.CohortID); err != nil {
+		return nil, err
+	}
+	if news.CommentsLocked && !actor.IsMentor() {
+		return nil, fmt.Errorf("%w: comments are locked", ErrForbidden)
+	}
+	if parentID != nil {
+		parent, err := s.getComment(*parentID)
+		if errors.Is(err, ErrNotFound) || (err == nil && (parent.NewsID != newsID || parent.Status == models.CommentStatusDeleted)) {
+			return nil, fmt.Errorf("%w: parent comment not found", ErrInvalidInput)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != nil {
+			return nil, fmt.Errorf("%w: replies cannot be replied to", ErrInvalidInput)
+		}
+	}
+
+	now := time.Now().UTC()
+	comment := &models.Comment{
+		ID:        uuid.New(),
+		NewsID:    newsID,
+		ParentID:  parentID,
+		UserID:    actor.UserID,
+		Content:   content,
+		Status:    models.CommentStatusVisible,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	// Re-read to pick up the author's username
+	return s.GetComment(comment.ID, actor)
+}
+
+// GetComment returns a single comment the actor is allowed to see
+func (s *CommentService) GetComment(id uuid.UUID, actor *Actor) (*models.Comment, error) {
+	comment, err := s.getComment(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeComment(comment, actor) {
+		return nil, ErrNotFound
+	}
+	renderComment(comment)
+	return comment, nil
+}
+
+// UpdateComment changes the text of a comment. Only the author can edit, and
+// not while the comments are locked or the cohort is archived.
+func (s *CommentService) UpdateComment(id uuid.UUID, content string, actor *Actor) (*models.Comment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: comment is empty", ErrInvalidInput)
+	}
+	comment, err := s.getComment(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeComment(comment, actor) {
+		return nil, ErrNotFound
+	}
+	if actor.UserID != comment.UserID {
+		return nil, ErrForbidden
+	}
+	news, err := s.news.getUnarchivedNews(comment.NewsID)
+	if err != nil {
+		return nil, err
+	}
+	if news.CommentsLocked && !actor.IsMentor() {
+		return nil, fmt.Errorf("%w: comments are locked", ErrForbidden)
+	}
+
+	now := time.Now().UTC()
+	comment.Content = content
+	comment.UpdatedAt = now
+	comment.EditedAt = &now
+	if err := s.repo.UpdateComment(comment); err != nil {
+		return nil, err
+	}
+	renderComment(comment)
+	return comment, nil
+}
+
+// DeleteComment removes a comment on behalf of its author or a mentor. The
+// row is kept, with its text cleared, so replies stay in their thread.
+// Comments on trashed news or in archived cohorts cannot be deleted.
+func (s *CommentService) DeleteComment(id uuid.UUID, actor *Actor) error {
+	comment, err := s.getComment(id)
+	if err != nil {
+		return err
+	}
+	if !canSeeComment(comment, actor) {
+		return ErrNotFound
+	}
+	if actor.UserID != comment.UserID && !actor.IsMentor() {
+		return ErrForbidden
+	}
+	if _, err := s.news.getUnarchivedNews(comment.NewsID); err != nil {
+		return err
+	}
+	comment.Content = ""
+	comment.Status = models.CommentStatusDeleted
+	comment.UpdatedAt = time.Now().UTC()
+	return s.repo.UpdateComment(comment)
+}
+
+// ModerateComment hides a comment from students or makes it visible again
+func (s *CommentService) ModerateComment(id uuid.UUID, status string, actor *Actor) (*models.Comment, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	if status != models.CommentStatusVisible && status != models.CommentStatusHidden {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, status)
+	}
+	comment, err := s.getComment(id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Status == models.CommentStatusDeleted {
+		return nil, ErrNotFound
+	}
+	if _, err := s.news.getUnarchivedNews(comment.NewsID); err != nil {
+		return nil, err
+	}
+	comment.Status = status
+	comment.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateComment(comment); err != nil {
+		return nil, err
+	}
+	renderComment(comment)
+	return comment, nil
+}
+
+// LockComments closes or reopens the comments of a news item. While locked
+// only mentors can comment.
+func (s *CommentService) LockComments(newsID uuid.UUID, locked bool, actor *Actor) error {
+	if !actor.IsMentor() {
+		return ErrForbidden
+	}
+	if _, err := s.news.getUnarchivedNews(newsID); err != nil {
+		return err
+	}
+	return s.repo.SetLocked(newsID, locked)
+}
+
+func (s *CommentService) getComment(id uuid.UUID) (*models.Comment, error) {
+	comment, err := s.repo.GetCommentByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return comment, err
+}
+
+func canSeeComment(comment *models.Comment, actor *Actor) bool {
+	switch comment.Status {
+	case models.CommentStatusVisible:
+		return actor != nil
+	case models.CommentStatusHidden:
+		return actor.IsMentor() || (actor != nil && actor.UserID == comment.UserID)
+	default:
+		return false
+	}
+}
+
+// renderComment fills in the HTML of a comment. Deleted comments lose their
+// author so placeholders don't reveal who wrote them.
+func renderComment(comment *models.Comment) {
+	if comment.Status == models.CommentStatusDeleted {
+		comment.UserID = uuid.Nil
+		comment.Username = ""
+		comment.Content = ""
+		comment.ContentHTML = ""
+		return
+	}
+	comment.ContentHTML = markdown.ToHTML(comment.Content)
+}
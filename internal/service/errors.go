@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrTooLarge     = errors.New("file too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+	ErrConflict     = errors.New("conflict")
+)
+
+// Actor identifies the user performing an operation. A nil *Actor is an
+// anonymous visitor.
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+func (a *Actor) IsAdmin() bool {
+	return a != nil && a.Role == models.RoleAdmin
+}
+
+func (a *Actor) IsMentor() bool {
+	return a != nil && (a.Role == models.RoleMentor || a.Role == models.RoleAdmin)
+}
+
+// Owns reports whether the actor is the given user or an admin.
+func (a *Actor) Owns(userID uuid.UUID) bool {
+	return a != nil && (a.UserID == userID || a.IsAdmin())
+}
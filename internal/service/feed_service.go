@@ -0,0 +1,123 @@
+package service
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/feed"
+	"blazperic/radionica/internal/models"
+)
+
+// feedSize is the number of most recent news included in a feed
+const feedSize = 50
+
+type FeedService struct {
+	news        *NewsService
+	title       string
+	frontendURL string
+}
+
+func NewFeedService(news *NewsService, title, frontendURL string) *FeedService {
+	return &FeedService{
+		news:        news,
+		title:       title,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// NewsFeed builds the feed of published news in the given locale, optionally
+// limited to one category. selfLink is the absolute URL of the feed itself;
+// relative image paths are resolved against it.
+func (s *FeedService) NewsFeed(category, selfLink, locale string) (*feed.Feed, error) {
+	newsList, err := s.news.GetNewsByCategory(category, locale, feedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(selfLink)
+	if err != nil {
+		return nil, err
+	}
+
+	title := s.title
+	if category != "" {
+		title += " – " + category
+	}
+	f := &feed.Feed{
+		Title:       title,
+		Description: title,
+		Author:      s.title,
+		Link:        s.frontendURL + "/news",
+		SelfLink:    selfLink,
+		Language:    locale,
+		Items:       make([]feed.Item, 0, len(newsList)),
+	}
+
+	for _, news := range newsList {
+		item := feed.Item{
+			ID:        news.ID.String(),
+			Title:     news.Title,
+			Link:      s.frontendURL + "/news/" + news.Slug,
+			Summary:   news.Excerpt,
+			Content:   news.ContentHTML,
+			Category:  news.Category,
+			Published: publishedAt(news).UTC(),
+			Updated:   news.UpdatedAt.UTC(),
+			Enclosure: imageEnclosure(news, base),
+		}
+		if news.Author != nil {
+			item.Author = news.Author.DisplayName
+		}
+		// Both times are stored in UTC, so the latest is the feed's
+		// Last-Modified whatever the host's time zone
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		if item.Published.After(f.Updated) {
+			f.Updated = item.Published
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+func publishedAt(news *models.News) time.Time {
+	if news.PublishedAt != nil {
+		return *news.PublishedAt
+	}
+	return news.CreatedAt
+}
+
+func imageEnclosure(news *models.News, base *url.URL) *feed.Enclosure {
+	if news.Image != nil {
+		return &feed.Enclosure{
+			URL:    absoluteURL(base, news.Image.URL),
+			Type:   news.Image.ContentType,
+			Length: news.Image.Size,
+		}
+	}
+	if news.ImagePath == "" {
+		return nil
+	}
+
+	// Free-form image paths carry no size; 0 is the accepted "unknown" value
+	contentType := mime.TypeByExtension(path.Ext(news.ImagePath))
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = "image/jpeg"
+	}
+	return &feed.Enclosure{
+		URL:  absoluteURL(base, news.ImagePath),
+		Type: contentType,
+	}
+}
+
+func absoluteURL(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
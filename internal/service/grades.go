@@ -0,0 +1,425 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CriterionInput holds a rubric criterion
+type CriterionInput struct {
+	Title       string
+	Description string
+	MinPoints   int
+	MaxPoints   int
+}
+
+// GradeInput holds a mentor's grade of a submission. Assignments with a
+// rubric are graded with a score per criterion, which add up to the points;
+// others are given points directly.
+type GradeInput struct {
+	Points   *float64
+	Feedback string
+	Scores   []models.CriterionScore
+}
+
+// SetRubric replaces the criteria an assignment is graded by. Their maximum
+// points must add up to the assignment's max points. The rubric cannot change
+// once submissions were graded. An empty list removes it.
+func (s *AssignmentService) SetRubric(id uuid.UUID, criteria []CriterionInput, actor *Actor) (*models.Assignment, error) {
+	assignment, _, err := s.getWritableAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	graded, err := s.grades.HasGrades(id)
+	if err != nil {
+		return nil, err
+	}
+	if graded {
+		return nil, fmt.Errorf("%w: submissions were already graded with the current rubric", ErrConflict)
+	}
+
+	rubric := make([]*models.RubricCriterion, 0, len(criteria))
+	for i, input := range criteria {
+		title := strings.TrimSpace(input.Title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: criterion %d needs a title", ErrInvalidInput, i+1)
+		}
+		if utf8.RuneCountInString(title) > maxAssignmentTitleLength {
+			return nil, fmt.Errorf("%w: criterion title is longer than %d characters", ErrInvalidInput, maxAssignmentTitleLength)
+		}
+		if input.MinPoints < 0 || input.MaxPoints < 1 || input.MaxPoints < input.MinPoints {
+			return nil, fmt.Errorf("%w: %q needs a point range from 0 or more up to at least 1", ErrInvalidInput, title)
+		}
+		rubric = append(rubric, &models.RubricCriterion{
+			ID:           uuid.New(),
+			AssignmentID: id,
+			Title:        title,
+			Description:  input.Description,
+			MinPoints:    input.MinPoints,
+			MaxPoints:    input.MaxPoints,
+			Position:     i,
+		})
+	}
+	if total := rubricTotal(rubric); len(rubric) > 0 && total != assignment.MaxPoints {
+		return nil, fmt.Errorf("%w: the criteria add up to %d points but the assignment is worth %d", ErrInvalidInput, total, assignment.MaxPoints)
+	}
+
+	if err := s.grades.SetRubric(id, rubric); err != nil {
+		return nil, err
+	}
+	assignment.Rubric = rubric
+	return assignment, nil
+}
+
+// GradeSubmission grades a submission or revises its grade. Under the penalty
+// policy the late penalty is deducted from the points given. Mentors only.
+func (s *AssignmentService) GradeSubmission(submissionID uuid.UUID, input GradeInput, actor *Actor) (*models.Submission, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	submission, err := s.repo.GetSubmissionByID(submissionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	assignment, _, err := s.getWritableAssignment(submission.AssignmentID, actor)
+	if err != nil {
+		return nil, err
+	}
+	points, scores, err := gradePoints(assignment, input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	grade := &models.Grade{
+		SubmissionID: submissionID,
+		Points:       points,
+		Feedback:     input.Feedback,
+		Scores:       scores,
+		GraderID:     actor.UserID,
+		GradedAt:     now,
+		UpdatedAt:    now,
+	}
+	if err := s.grades.SaveGrade(grade); err != nil {
+		return nil, err
+	}
+	if err := s.fillSubmissions(assignment, []*models.Submission{submission}, false); err != nil {
+		return nil, err
+	}
+	submission.Grade = grade
+	applyPenalty(assignment, submission)
+	return submission, nil
+}
+
+// SetGradesReleased shows students their grades on an assignment, or hides
+// them again. Mentors only.
+func (s *AssignmentService) SetGradesReleased(id uuid.UUID, released bool, actor *Actor) (*models.Assignment, error) {
+	assignment, _, err := s.getWritableAssignment(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	var releasedAt *time.Time
+	if released {
+		releasedAt = assignment.GradesReleasedAt
+		if releasedAt == nil {
+			now := time.Now().UTC()
+			releasedAt = &now
+		}
+	}
+	if err := s.repo.SetGradesReleased(id, releasedAt); err != nil {
+		return nil, err
+	}
+	assignment.GradesReleasedAt = releasedAt
+	return assignment, nil
+}
+
+// GetGradebook returns the grades of every student of a cohort on every
+// assignment, released or not. Mentors only.
+func (s *AssignmentService) GetGradebook(cohortID uuid.UUID, actor *Actor) (*models.Gradebook, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	cohort, err := getCohort(s.cohorts, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.repo.GetAssignments(cohortID, 0)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.cohorts.GetMembers(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.grades.GetCohortGrades(cohortID, nil)
+	if err != nil {
+		return nil, err
+	}
+	students := make([]*models.CohortMember, 0, len(members))
+	for _, member := range members {
+		if member.Role == models.RoleStudent {
+			students = append(students, member)
+		}
+	}
+	return buildGradebook(cohort, assignments, students, submissions, false, time.Now().UTC()), nil
+}
+
+// GetMyGrades returns the actor's row of a cohort's gradebook. It lists the
+// assignments of weeks that have started, and only grades that were released
+// show and count towards the total.
+func (s *AssignmentService) GetMyGrades(cohortID uuid.UUID, actor *Actor) (*models.Gradebook, error) {
+	cohort, err := s.getCohortFor(cohortID, actor)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.cohorts.GetMembers(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	var student *models.CohortMember
+	for _, member := range members {
+		if member.UserID == actor.UserID {
+			student = member
+		}
+	}
+	if student == nil {
+		return nil, fmt.Errorf("%w: only members of the cohort have grades", ErrForbidden)
+	}
+
+	now := time.Now().UTC()
+	assignments, err := s.repo.GetAssignments(cohortID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hydrate(assignments, cohort, now); err != nil {
+		return nil, err
+	}
+	open := assignments[:0]
+	for _, assignment := range assignments {
+		if assignment.Open {
+			open = append(open, assignment)
+		}
+	}
+	submissions, err := s.grades.GetCohortGrades(cohortID, &actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return buildGradebook(cohort, open, []*models.CohortMember{student}, submissions, true, now), nil
+}
+
+// WriteGradebookCSV writes a gradebook as CSV: a row per student with the
+// final points of each assignment and the weighted total. Assignments without
+// points are left empty.
+func WriteGradebookCSV(w io.Writer, gradebook *models.Gradebook) error {
+	out := csv.NewWriter(w)
+	header := []string{"Student"}
+	for _, assignment := range gradebook.Assignments {
+		header = append(header, csvText(fmt.Sprintf("%s (%d pts, weight %d)", assignment.Title, assignment.MaxPoints, assignment.Weight)))
+	}
+	header = append(header, "Total (%)")
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for _, student := range gradebook.Students {
+		record := []string{csvText(student.Username)}
+		for _, cell := range student.Cells {
+			value := ""
+			if cell.Points != nil {
+				value = strconv.FormatFloat(*cell.Points, 'f', -1, 64)
+			}
+			record = append(record, value)
+		}
+		record = append(record, strconv.FormatFloat(student.Total, 'f', 1, 64))
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// buildGradebook puts together the gradebook rows of the given students from
+// the submissions that count. With onlyReleased, grades of assignments whose
+// grades are not released are left out.
+func buildGradebook(cohort *models.Cohort, assignments []*models.Assignment, students []*models.CohortMember, submissions []*models.Submission, onlyReleased bool, now time.Time) *models.Gradebook {
+	gradebook := &models.Gradebook{
+		CohortID:    cohort.ID,
+		Assignments: make([]*models.GradebookAssignment, 0, len(assignments)),
+		Students:    make([]*models.GradebookRow, 0, len(students)),
+	}
+	byAssignment := make(map[uuid.UUID]*models.Assignment, len(assignments))
+	for _, assignment := range assignments {
+		byAssignment[assignment.ID] = assignment
+		gradebook.Assignments = append(gradebook.Assignments, &models.GradebookAssignment{
+			ID:        assignment.ID,
+			Title:     assignment.Title,
+			Week:      assignment.Week,
+			DueAt:     assignment.DueAt,
+			MaxPoints: assignment.MaxPoints,
+			Weight:    assignment.Weight,
+		})
+	}
+	// counting holds the submission that counts per student and assignment
+	counting := make(map[uuid.UUID]map[uuid.UUID]*models.Submission)
+	for _, submission := range submissions {
+		assignment, ok := byAssignment[submission.AssignmentID]
+		if !ok {
+			continue
+		}
+		setLate(assignment, submission)
+		applyPenalty(assignment, submission)
+		if counting[submission.UserID] == nil {
+			counting[submission.UserID] = make(map[uuid.UUID]*models.Submission)
+		}
+		counting[submission.UserID][submission.AssignmentID] = submission
+	}
+
+	for _, student := range students {
+		row := &models.GradebookRow{
+			UserID:   student.UserID,
+			Username: student.Username,
+			Cells:    make([]models.GradebookCell, 0, len(assignments)),
+		}
+		var score, weights float64
+		for _, assignment := range assignments {
+			hidden := onlyReleased && assignment.GradesReleasedAt == nil
+			cell := gradebookCell(assignment, counting[student.UserID][assignment.ID], hidden, now)
+			row.Cells = append(row.Cells, cell)
+			if cell.Points != nil && assignment.Weight > 0 {
+				score += float64(assignment.Weight) * *cell.Points / float64(assignment.MaxPoints)
+				weights += float64(assignment.Weight)
+			}
+		}
+		if weights > 0 {
+			row.Total = math.Round(score/weights*1000) / 10
+		}
+		gradebook.Students = append(gradebook.Students, row)
+	}
+	return gradebook
+}
+
+// gradebookCell works out a student's standing on an assignment from the
+// submission that counts, nil when they have not submitted
+func gradebookCell(assignment *models.Assignment, submission *models.Submission, hidden bool, now time.Time) models.GradebookCell {
+	switch {
+	case submission == nil && now.After(assignment.DueAt):
+		cell := models.GradebookCell{Status: models.GradeStatusMissing}
+		if !hidden {
+			zero := 0.0
+			cell.Points = &zero
+		}
+		return cell
+	case submission == nil:
+		return models.GradebookCell{Status: models.GradeStatusPending}
+	case submission.Grade == nil || hidden:
+		return models.GradebookCell{Status: models.GradeStatusSubmitted, Late: submission.Late}
+	default:
+		final := submission.Grade.Final
+		return models.GradebookCell{Status: models.GradeStatusGraded, Points: &final, Late: submission.Late}
+	}
+}
+
+// gradePoints validates a grade against the assignment and returns the points
+// it gives, with the rubric scores in rubric order
+func gradePoints(assignment *models.Assignment, input GradeInput) (float64, []models.CriterionScore, error) {
+	if len(assignment.Rubric) == 0 {
+		if len(input.Scores) > 0 {
+			return 0, nil, fmt.Errorf("%w: the assignment has no rubric to score", ErrInvalidInput)
+		}
+		if input.Points == nil {
+			return 0, nil, fmt.Errorf("%w: points are required", ErrInvalidInput)
+		}
+		points := roundPoints(*input.Points)
+		if points < 0 || points > float64(assignment.MaxPoints) {
+			return 0, nil, fmt.Errorf("%w: points must be between 0 and %d", ErrInvalidInput, assignment.MaxPoints)
+		}
+		return points, make([]models.CriterionScore, 0), nil
+	}
+
+	criteria := make(map[uuid.UUID]bool, len(assignment.Rubric))
+	for _, criterion := range assignment.Rubric {
+		criteria[criterion.ID] = true
+	}
+	given := make(map[uuid.UUID]float64, len(input.Scores))
+	for _, score := range input.Scores {
+		if !criteria[score.CriterionID] {
+			return 0, nil, fmt.Errorf("%w: criterion %s is not part of the rubric", ErrInvalidInput, score.CriterionID)
+		}
+		if _, ok := given[score.CriterionID]; ok {
+			return 0, nil, fmt.Errorf("%w: criterion %s is scored twice", ErrInvalidInput, score.CriterionID)
+		}
+		given[score.CriterionID] = roundPoints(score.Points)
+	}
+	scores := make([]models.CriterionScore, 0, len(assignment.Rubric))
+	var points float64
+	for _, criterion := range assignment.Rubric {
+		value, ok := given[criterion.ID]
+		if !ok {
+			return 0, nil, fmt.Errorf("%w: %q is not scored", ErrInvalidInput, criterion.Title)
+		}
+		if value < float64(criterion.MinPoints) || value > float64(criterion.MaxPoints) {
+			return 0, nil, fmt.Errorf("%w: %q must be scored between %d and %d", ErrInvalidInput, criterion.Title, criterion.MinPoints, criterion.MaxPoints)
+		}
+		scores = append(scores, models.CriterionScore{CriterionID: criterion.ID, Points: value})
+		points += value
+	}
+	points = roundPoints(points)
+	if input.Points != nil && roundPoints(*input.Points) != points {
+		return 0, nil, fmt.Errorf("%w: points must be the sum of the rubric scores, %s", ErrInvalidInput, strconv.FormatFloat(points, 'f', -1, 64))
+	}
+	return points, scores, nil
+}
+
+// applyPenalty works out the late penalty and final points of a graded
+// submission. Under the penalty policy every day late costs LatePenalty
+// percent of the points given, down to zero.
+func applyPenalty(assignment *models.Assignment, submission *models.Submission) {
+	grade := submission.Grade
+	if grade == nil {
+		return
+	}
+	grade.Penalty = 0
+	if assignment.LatePolicy == models.LatePolicyPenalty && submission.DaysLate > 0 {
+		share := math.Min(1, float64(assignment.LatePenalty*submission.DaysLate)/100)
+		grade.Penalty = roundPoints(grade.Points * share)
+	}
+	grade.Final = roundPoints(grade.Points - grade.Penalty)
+}
+
+func rubricTotal(rubric []*models.RubricCriterion) int {
+	total := 0
+	for _, criterion := range rubric {
+		total += criterion.MaxPoints
+	}
+	return total
+}
+
+// roundPoints rounds points to the two decimals they are stored with
+func roundPoints(points float64) float64 {
+	return math.Round(points*100) / 100
+}
+
+// csvText keeps spreadsheet programs from running text that looks like a
+// formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
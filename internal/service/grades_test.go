@@ -0,0 +1,298 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestApplyPenalty(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		penalty     int
+		daysLate    int
+		points      float64
+		wantPenalty float64
+		wantFinal   float64
+	}{
+		{"on time", models.LatePolicyPenalty, 10, 0, 80, 0, 80},
+		{"per day", models.LatePolicyPenalty, 10, 2, 80, 16, 64},
+		{"exactly 100 percent", models.LatePolicyPenalty, 25, 4, 80, 80, 0},
+		{"capped at 100 percent", models.LatePolicyPenalty, 30, 4, 80, 80, 0},
+		{"rounded to two decimals", models.LatePolicyPenalty, 33, 1, 10, 3.3, 6.7},
+		{"accept policy", models.LatePolicyAccept, 10, 3, 80, 0, 80},
+		{"zero points", models.LatePolicyPenalty, 10, 3, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := &models.Assignment{LatePolicy: tt.policy, LatePenalty: tt.penalty}
+			submission := &models.Submission{DaysLate: tt.daysLate, Grade: &models.Grade{Points: tt.points, Penalty: 99}}
+			applyPenalty(assignment, submission)
+			if submission.Grade.Penalty != tt.wantPenalty || submission.Grade.Final != tt.wantFinal {
+				t.Errorf("penalty, final = %v, %v, want %v, %v", submission.Grade.Penalty, submission.Grade.Final, tt.wantPenalty, tt.wantFinal)
+			}
+		})
+	}
+
+	t.Run("ungraded", func(t *testing.T) {
+		submission := &models.Submission{DaysLate: 2}
+		applyPenalty(&models.Assignment{LatePolicy: models.LatePolicyPenalty, LatePenalty: 10}, submission)
+		if submission.Grade != nil {
+			t.Errorf("Grade = %+v, want nil", submission.Grade)
+		}
+	})
+}
+
+func TestBuildGradebook(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	released := now.Add(-time.Hour)
+	cohort := &models.Cohort{ID: uuid.New()}
+
+	// Worth twice as much as the project, 10% off per day late
+	homework := &models.Assignment{
+		ID:               uuid.New(),
+		Title:            "Homework",
+		DueAt:            now.Add(-48 * time.Hour),
+		MaxPoints:        10,
+		Weight:           2,
+		LatePolicy:       models.LatePolicyPenalty,
+		LatePenalty:      10,
+		GradesReleasedAt: &released,
+	}
+	// Left out of the total
+	quiz := &models.Assignment{
+		ID:               uuid.New(),
+		Title:            "Quiz",
+		DueAt:            now.Add(-48 * time.Hour),
+		MaxPoints:        100,
+		Weight:           0,
+		GradesReleasedAt: &released,
+	}
+	// Grades not released yet
+	project := &models.Assignment{
+		ID:        uuid.New(),
+		Title:     "Project",
+		DueAt:     now.Add(-24 * time.Hour),
+		MaxPoints: 20,
+		Weight:    1,
+	}
+	// Not due yet
+	final := &models.Assignment{
+		ID:        uuid.New(),
+		Title:     "Final",
+		DueAt:     now.Add(24 * time.Hour),
+		MaxPoints: 50,
+		Weight:    1,
+	}
+	assignments := []*models.Assignment{homework, quiz, project, final}
+
+	ana := &models.CohortMember{UserID: uuid.New(), Username: "ana"}
+	ivo := &models.CohortMember{UserID: uuid.New(), Username: "ivo"}
+	submit := func(assignment *models.Assignment, student *models.CohortMember, submittedAt time.Time, points float64) *models.Submission {
+		return &models.Submission{
+			AssignmentID: assignment.ID,
+			UserID:       student.UserID,
+			SubmittedAt:  submittedAt,
+			Grade:        &models.Grade{Points: points},
+		}
+	}
+	// submissions returns fresh copies, as buildGradebook fills in the late
+	// penalty in place
+	submissions := func() []*models.Submission {
+		return []*models.Submission{
+			// One day late: 5 points less 10%
+			submit(homework, ana, homework.DueAt.Add(2*time.Hour), 5),
+			submit(quiz, ana, quiz.DueAt.Add(-time.Hour), 100),
+			submit(project, ana, project.DueAt.Add(-time.Hour), 20),
+			// Submitted for an assignment outside the gradebook
+			{AssignmentID: uuid.New(), UserID: ivo.UserID, SubmittedAt: now, Grade: &models.Grade{Points: 1}},
+		}
+	}
+	students := []*models.CohortMember{ana, ivo}
+
+	points := func(p float64) *float64 { return &p }
+	tests := []struct {
+		name         string
+		onlyReleased bool
+		wantCells    [][]models.GradebookCell
+		wantTotals   []float64
+	}{
+		{
+			name: "all grades",
+			wantCells: [][]models.GradebookCell{
+				{
+					{Status: models.GradeStatusGraded, Points: points(4.5), Late: true},
+					{Status: models.GradeStatusGraded, Points: points(100)},
+					{Status: models.GradeStatusGraded, Points: points(20)},
+					{Status: models.GradeStatusPending},
+				},
+				{
+					{Status: models.GradeStatusMissing, Points: points(0)},
+					{Status: models.GradeStatusMissing, Points: points(0)},
+					{Status: models.GradeStatusMissing, Points: points(0)},
+					{Status: models.GradeStatusPending},
+				},
+			},
+			// (2 × 4.5/10 + 1 × 20/20) / 3
+			wantTotals: []float64{63.3, 0},
+		},
+		{
+			name:         "released grades only",
+			onlyReleased: true,
+			wantCells: [][]models.GradebookCell{
+				{
+					{Status: models.GradeStatusGraded, Points: points(4.5), Late: true},
+					{Status: models.GradeStatusGraded, Points: points(100)},
+					{Status: models.GradeStatusSubmitted},
+					{Status: models.GradeStatusPending},
+				},
+				{
+					{Status: models.GradeStatusMissing, Points: points(0)},
+					{Status: models.GradeStatusMissing, Points: points(0)},
+					{Status: models.GradeStatusMissing},
+					{Status: models.GradeStatusPending},
+				},
+			},
+			// 2 × 4.5/10 / 2
+			wantTotals: []float64{45, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gradebook := buildGradebook(cohort, assignments, students, submissions(), tt.onlyReleased, now)
+			if len(gradebook.Assignments) != len(assignments) {
+				t.Fatalf("got %d assignments, want %d", len(gradebook.Assignments), len(assignments))
+			}
+			if len(gradebook.Students) != len(students) {
+				t.Fatalf("got %d students, want %d", len(gradebook.Students), len(students))
+			}
+			for i, row := range gradebook.Students {
+				if row.UserID != students[i].UserID {
+					t.Errorf("row %d is for %s, want %s", i, row.Username, students[i].Username)
+				}
+				if row.Total != tt.wantTotals[i] {
+					t.Errorf("%s: total = %v, want %v", row.Username, row.Total, tt.wantTotals[i])
+				}
+				for j, cell := range row.Cells {
+					want := tt.wantCells[i][j]
+					if !sameCell(cell, want) {
+						t.Errorf("%s, %s: cell = %s, want %s", row.Username, assignments[j].Title, formatCell(cell), formatCell(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildGradebookWithoutWeights(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	assignment := &models.Assignment{ID: uuid.New(), DueAt: now.Add(-time.Hour), MaxPoints: 10, Weight: 0}
+	student := &models.CohortMember{UserID: uuid.New()}
+	submission := &models.Submission{AssignmentID: assignment.ID, UserID: student.UserID, SubmittedAt: now.Add(-2 * time.Hour), Grade: &models.Grade{Points: 10}}
+
+	gradebook := buildGradebook(&models.Cohort{}, []*models.Assignment{assignment}, []*models.CohortMember{student}, []*models.Submission{submission}, false, now)
+	if total := gradebook.Students[0].Total; total != 0 {
+		t.Errorf("total = %v, want 0", total)
+	}
+}
+
+func sameCell(a, b models.GradebookCell) bool {
+	if a.Status != b.Status || a.Late != b.Late || (a.Points == nil) != (b.Points == nil) {
+		return false
+	}
+	return a.Points == nil || *a.Points == *b.Points
+}
+
+func formatCell(cell models.GradebookCell) string {
+	s := cell.Status
+	if cell.Points != nil {
+		s += " " + strconv.FormatFloat(*cell.Points, 'f', -1, 64)
+	}
+	if cell.Late {
+		s += " late"
+	}
+	return s
+}
+
+func TestGradePoints(t *testing.T) {
+	design := &models.RubricCriterion{ID: uuid.New(), Title: "Design", MinPoints: 0, MaxPoints: 6}
+	coverage := &models.RubricCriterion{ID: uuid.New(), Title: "Test coverage", MinPoints: 2, MaxPoints: 4}
+	plain := &models.Assignment{MaxPoints: 10}
+	rubric := &models.Assignment{MaxPoints: 10, Rubric: []*models.RubricCriterion{design, coverage}}
+
+	points := func(p float64) *float64 { return &p }
+	score := func(criterion *models.RubricCriterion, p float64) models.CriterionScore {
+		return models.CriterionScore{CriterionID: criterion.ID, Points: p}
+	}
+	tests := []struct {
+		name       string
+		assignment *models.Assignment
+		input      GradeInput
+		wantPoints float64
+		wantScores []models.CriterionScore
+		wantErr    bool
+	}{
+		{name: "points", assignment: plain, input: GradeInput{Points: points(7.5)}, wantPoints: 7.5, wantScores: []models.CriterionScore{}},
+		{name: "points rounded", assignment: plain, input: GradeInput{Points: points(7.254)}, wantPoints: 7.25, wantScores: []models.CriterionScore{}},
+		{name: "full points", assignment: plain, input: GradeInput{Points: points(10)}, wantPoints: 10, wantScores: []models.CriterionScore{}},
+		{name: "points missing", assignment: plain, input: GradeInput{}, wantErr: true},
+		{name: "points above max", assignment: plain, input: GradeInput{Points: points(10.5)}, wantErr: true},
+		{name: "negative points", assignment: plain, input: GradeInput{Points: points(-1)}, wantErr: true},
+		{name: "scores without rubric", assignment: plain, input: GradeInput{Points: points(5), Scores: []models.CriterionScore{score(design, 5)}}, wantErr: true},
+		{
+			name:       "scores in rubric order",
+			assignment: rubric,
+			input:      GradeInput{Scores: []models.CriterionScore{score(coverage, 3), score(design, 4.5)}},
+			wantPoints: 7.5,
+			wantScores: []models.CriterionScore{score(design, 4.5), score(coverage, 3)},
+		},
+		{
+			name:       "points matching scores",
+			assignment: rubric,
+			input:      GradeInput{Points: points(7.5), Scores: []models.CriterionScore{score(design, 4.5), score(coverage, 3)}},
+			wantPoints: 7.5,
+			wantScores: []models.CriterionScore{score(design, 4.5), score(coverage, 3)},
+		},
+		{name: "points not matching scores", assignment: rubric, input: GradeInput{Points: points(8), Scores: []models.CriterionScore{score(design, 4.5), score(coverage, 3)}}, wantErr: true},
+		{name: "points without scores", assignment: rubric, input: GradeInput{Points: points(8)}, wantErr: true},
+		{name: "criterion not scored", assignment: rubric, input: GradeInput{Scores: []models.CriterionScore{score(design, 4)}}, wantErr: true},
+		{name: "criterion scored twice", assignment: rubric, input: GradeInput{Scores: []models.CriterionScore{score(design, 4), score(design, 4), score(coverage, 3)}}, wantErr: true},
+		{name: "unknown criterion", assignment: rubric, input: GradeInput{Scores: []models.CriterionScore{score(design, 4), score(coverage, 3), {CriterionID: uuid.New(), Points: 1}}}, wantErr: true},
+		{name: "score below min", assignment: rubric, input: GradeInput{Scores: []models.CriterionScore{score(design, 4), score(coverage, 1)}}, wantErr: true},
+		{name: "score above max", assignment: rubric, input: GradeInput{Scores: []models.CriterionScore{score(design, 7), score(coverage, 3)}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, scores, err := gradePoints(tt.assignment, tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("gradePoints() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gradePoints() error = %v", err)
+			}
+			if got != tt.wantPoints {
+				t.Errorf("points = %v, want %v", got, tt.wantPoints)
+			}
+			if len(scores) != len(tt.wantScores) {
+				t.Fatalf("scores = %v, want %v", scores, tt.wantScores)
+			}
+			for i := range scores {
+				if scores[i] != tt.wantScores[i] {
+					t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/imaging"
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+	"blazperic/radionica/internal/storage"
+)
+
+// imageVariants are the resized copies generated for every uploaded image,
+// by name and maximum width
+var imageVariants = []struct {
+	Name  string
+	Width int
+}{
+	{"thumbnail", 320},
+	{"medium", 800},
+	{"large", 1600},
+}
+
+// staleProcessingTimeout is how long an asset may stay in processing before
+// another worker assumes the first one died and takes over
+const staleProcessingTimeout = 10 * time.Minute
+
+// ImageProcessor generates variants, dimensions and blurhash placeholders for
+// uploaded images in the background
+type ImageProcessor struct {
+	repo    *repository.AssetRepository
+	storage storage.Storage
+	wake    chan struct{}
+}
+
+func NewImageProcessor(repo *repository.AssetRepository, store storage.Storage) *ImageProcessor {
+	return &ImageProcessor{
+		repo:    repo,
+		storage: store,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the worker so a new upload is processed without waiting for
+// the next poll
+func (p *ImageProcessor) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run processes pending images until ctx is done, polling every interval
+func (p *ImageProcessor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := p.processNext(ctx)
+			if err != nil {
+				log.Printf("Image processor: %v", err)
+			}
+			if !processed || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// processNext claims and processes a single pending asset, reporting whether
+// there was one
+func (p *ImageProcessor) processNext(ctx context.Context) (bool, error) {
+	now := time.Now().UTC()
+	asset, err := p.repo.ClaimPendingAsset(now, now.Add(-staleProcessingTimeout))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim asset: %v", err)
+	}
+
+	if err := p.process(ctx, asset); err != nil {
+		if markErr := p.repo.MarkProcessingFailed(asset.ID, err.Error()); markErr != nil {
+			return true, fmt.Errorf("failed to mark asset %s as failed: %v", asset.ID, markErr)
+		}
+		return true, fmt.Errorf("failed to process asset %s: %v", asset.ID, err)
+	}
+	return true, nil
+}
+
+func (p *ImageProcessor) process(ctx context.Context, asset *models.Asset) error {
+	body, err := p.storage.Open(ctx, asset.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %v", err)
+	}
+	asset.Width = img.Bounds().Dx()
+	asset.Height = img.Bounds().Dy()
+	if asset.Blurhash, err = imaging.Blurhash(img); err != nil {
+		return fmt.Errorf("failed to compute blurhash: %v", err)
+	}
+
+	// Photos become JPEG, anything with transparency stays lossless
+	format := "jpeg"
+	if !imaging.Opaque(img) {
+		format = "png"
+	}
+
+	base := strings.TrimSuffix(asset.StorageKey, path.Ext(asset.StorageKey))
+	asset.Variants = asset.Variants[:0]
+	for _, spec := range imageVariants {
+		resized := imaging.Resize(img, spec.Width)
+		for _, f := range []string{format, "webp"} {
+			encoded, err := imaging.Encode(resized, f)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %v", spec.Name, f, err)
+			}
+			variant := models.AssetVariant{
+				AssetID:     asset.ID,
+				Name:        spec.Name,
+				Format:      f,
+				StorageKey:  fmt.Sprintf("%s/%s.%s", base, spec.Name, f),
+				ContentType: "image/" + f,
+				Width:       resized.Bounds().Dx(),
+				Height:      resized.Bounds().Dy(),
+				Size:        int64(len(encoded)),
+			}
+			if err := p.storage.Put(ctx, variant.StorageKey, bytes.NewReader(encoded), variant.Size, variant.ContentType); err != nil {
+				return fmt.Errorf("failed to store %s %s: %v", spec.Name, f, err)
+			}
+			asset.Variants = append(asset.Variants, variant)
+		}
+	}
+
+	return p.repo.SaveProcessingResult(asset)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunScheduler publishes due scheduled news every interval until ctx is done
+func (s *NewsService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.PublishScheduled(time.Now().UTC()); err != nil {
+			log.Printf("News scheduler: failed to publish scheduled news: %v", err)
+		} else if n > 0 {
+			log.Printf("News scheduler: published %d scheduled news item(s)", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package service
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"blazperic/radionica/internal/locale"
+	"blazperic/radionica/internal/markdown"
 	"blazperic/radionica/internal/models"
 	"blazperic/radionica/internal/repository"
 
@@ -10,29 +16,582 @@ import (
 )
 
 type NewsService struct {
-	repo *repository.NewsRepository
+	repo         *repository.NewsRepository
+	cohorts      *repository.CohortRepository
+	slugs        *repository.SlugRepository
+	revisions    *repository.RevisionRepository
+	translations *repository.TranslationRepository
+	uploads      *UploadService
+	locales      *locale.Set
 }
 
-func NewNewsService(repo *repository.NewsRepository) *NewsService {
-	return &NewsService{repo: repo}
+// NewsInput holds the author-editable fields of a news item
+type NewsInput struct {
+	Title     string
+	Content   string
+	ImagePath string
+	// ImageID references an uploaded image and takes precedence over ImagePath
+	ImageID  *uuid.UUID
+	Category string
+	// Slug changes the slug on update; the old one keeps redirecting
+	Slug string
+	// Status defaults to published when empty
+	Status string
+	// PublishAt is required for scheduled news and ignored otherwise
+	PublishAt *time.Time
+	// CohortID scopes new news to a cohort; it cannot be changed on update
+	CohortID *uuid.UUID
 }
 
-func (s *NewsService) GetAllNews() ([]*models.News, error) {
-	return s.repo.GetAllNews()
+func NewNewsService(repo *repository.NewsRepository, cohorts *repository.CohortRepository, slugs *repository.SlugRepository, revisions *repository.RevisionRepository, translations *repository.TranslationRepository, uploads *UploadService, locales *locale.Set) *NewsService {
+	return &NewsService{repo: repo, cohorts: cohorts, slugs: slugs, revisions: revisions, translations: translations, uploads: uploads, locales: locales}
 }
 
-func (s *NewsService) CreateNews(title, content, imagwePath, category string, userID uuid.UUID) (*models.News, error) {
+// GetAllNews returns published news ordered by publish date. Read methods
+// render news in the given locale where translated, and in the default
+// locale otherwise or when locale is empty. When cohortID is not nil, only
+// the news of that cohort and the news shared by every cohort are returned.
+func (s *NewsService) GetAllNews(actor *Actor, cohortID *uuid.UUID, locale string) ([]*models.News, error) {
+	if cohortID != nil {
+		if _, err := getCohort(s.cohorts, *cohortID); err != nil {
+			return nil, err
+		}
+	}
+	news, err := s.repo.GetPublishedNews("", cohortID, 0, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return news, s.hydrate(news, actor, locale)
+}
+
+// GetFeaturedNews returns the published news flagged as featured, pinned
+// first
+func (s *NewsService) GetFeaturedNews(actor *Actor, locale string) ([]*models.News, error) {
+	news, err := s.repo.GetFeaturedNews(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return news, s.hydrate(news, actor, locale)
+}
+
+// GetNewsByCategory returns up to limit published news of one category
+// ordered by publish date, or of all categories when category is empty
+func (s *NewsService) GetNewsByCategory(category, locale string, limit int) ([]*models.News, error) {
+	news, err := s.repo.GetPublishedNews(category, nil, limit, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return news, s.hydrate(news, nil, locale)
+}
+
+// GetNews returns a single news item. Unpublished items are only visible to
+// their author and admins.
+func (s *NewsService) GetNews(id uuid.UUID, actor *Actor, locale string) (*models.News, error) {
+	news, err := s.getNews(id)
+	if err != nil {
+		return nil, err
+	}
+	if news.Status != models.NewsStatusPublished && !actor.Owns(news.UserID) {
+		return nil, ErrNotFound
+	}
+	return news, s.hydrate([]*models.News{news}, actor, locale)
+}
+
+// GetNewsBySlug returns a news item by its slug. When the slug is one the
+// item had before being renamed, redirected is true and the returned item
+// carries its current slug.
+func (s *NewsService) GetNewsBySlug(slug string, actor *Actor, locale string) (news *models.News, redirected bool, err error) {
+	news, err = s.repo.GetNewsBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		id, redirectErr := s.slugs.FindRedirect(repository.SlugEntityNews, slug)
+		if errors.Is(redirectErr, sql.ErrNoRows) {
+			return nil, false, ErrNotFound
+		}
+		if redirectErr != nil {
+			return nil, false, redirectErr
+		}
+		news, err = s.GetNews(id, actor, locale)
+		return news, err == nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if news.Status != models.NewsStatusPublished && !actor.Owns(news.UserID) {
+		return nil, false, ErrNotFound
+	}
+	return news, false, s.hydrate([]*models.News{news}, actor, locale)
+}
+
+// GetUnpublishedNews returns the actor's drafts, scheduled and archived news,
+// or those of every author for admins
+func (s *NewsService) GetUnpublishedNews(actor *Actor, locale string) ([]*models.News, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	var userID *uuid.UUID
+	if !actor.IsAdmin() {
+		userID = &actor.UserID
+	}
+	news, err := s.repo.GetUnpublishedNews(userID)
+	if err != nil {
+		return nil, err
+	}
+	return news, s.hydrate(news, actor, locale)
+}
+
+func (s *NewsService) CreateNews(input NewsInput, userID uuid.UUID) (*models.News, error) {
+	if err := checkCohortWritable(s.cohorts, input.CohortID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
 	news := &models.News{
 		ID:        uuid.New(),
-		Title:     title,
-		Content:   content,
-		ImagePath: imagwePath,
-		Category:  category,
+		Title:     input.Title,
+		Content:   input.Content,
+		Category:  input.Category,
 		UserID:    userID,
-		CreatedAt: time.Now(),
+		CohortID:  input.CohortID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.setImage(news, input); err != nil {
+		return nil, err
+	}
+	slug, err := uniqueSlug(s.slugs, repository.SlugEntityNews, input.Title, news.ID)
+	if err != nil {
+		return nil, err
+	}
+	news.Slug = slug
+	status := input.Status
+	if status == "" {
+		status = models.NewsStatusPublished
+	}
+	if err := applyNewsStatus(news, status, input.PublishAt, now); err != nil {
+		return nil, err
+	}
+	revision, err := s.newRevision(news, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateNews(news, revision); err != nil {
+		return nil, err
+	}
+	return news, s.hydrate([]*models.News{news}, nil, "")
+}
+
+// UpdateNews edits the content of a news item without changing its status.
+// The slug stays the same when the title changes unless a new one is given.
+func (s *NewsService) UpdateNews(id uuid.UUID, input NewsInput, actor *Actor) (*models.News, error) {
+	news, err := s.getWritableNews(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setImage(news, input); err != nil {
+		return nil, err
+	}
+	oldSlug := news.Slug
+	if news.Slug, err = changeSlug(s.slugs, repository.SlugEntityNews, news.ID, news.Slug, input.Slug); err != nil {
+		return nil, err
+	}
+	news.Title = input.Title
+	news.Content = input.Content
+	news.Category = input.Category
+	news.UpdatedAt = time.Now().UTC()
+	revision, err := s.newRevision(news, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateNews(news, oldSlug, revision); err != nil {
+		return nil, err
+	}
+	return news, s.hydrate([]*models.News{news}, actor, "")
+}
+
+// ChangeStatus moves a news item through the draft/scheduled/published/archived
+// workflow
+func (s *NewsService) ChangeStatus(id uuid.UUID, status string, publishAt *time.Time, actor *Actor) (*models.News, error) {
+	news, err := s.getWritableNews(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := applyNewsStatus(news, status, publishAt, now); err != nil {
+		return nil, err
+	}
+	news.UpdatedAt = now
+	revision, err := s.newRevision(news, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateNews(news, news.Slug, revision); err != nil {
+		return nil, err
+	}
+	return news, s.hydrate([]*models.News{news}, actor, "")
+}
+
+// ListRevisions returns the edit history of a news item, newest first
+func (s *NewsService) ListRevisions(id uuid.UUID, actor *Actor) ([]*models.Revision, error) {
+	if _, err := s.getOwnedNews(id, actor); err != nil {
+		return nil, err
 	}
-	if err := s.repo.CreateNews(news); err != nil {
+	return s.revisions.GetRevisions(repository.RevisionEntityNews, id)
+}
+
+func (s *NewsService) GetRevision(id uuid.UUID, number int, actor *Actor) (*models.Revision, error) {
+	if _, err := s.getOwnedNews(id, actor); err != nil {
 		return nil, err
 	}
+	return getRevision(s.revisions, repository.RevisionEntityNews, id, number)
+}
+
+// DiffRevisions compares two revisions of a news item
+func (s *NewsService) DiffRevisions(id uuid.UUID, from, to int, actor *Actor) (*models.RevisionDiff, error) {
+	if _, err := s.getOwnedNews(id, actor); err != nil {
+		return nil, err
+	}
+	return diffRevisions(s.revisions, repository.RevisionEntityNews, id, from, to)
+}
+
+// RevertNews restores the content of a news item to an earlier revision,
+// recording the result as a new revision. The slug and status are left as
+// they are.
+func (s *NewsService) RevertNews(id uuid.UUID, number int, actor *Actor) (*models.News, error) {
+	if _, err := s.getWritableNews(id, actor); err != nil {
+		return nil, err
+	}
+	revision, err := getRevision(s.revisions, repository.RevisionEntityNews, id, number)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot models.NewsSnapshot
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	return s.UpdateNews(id, NewsInput{
+		Title:     snapshot.Title,
+		Content:   snapshot.Content,
+		ImagePath: snapshot.ImagePath,
+		ImageID:   snapshot.ImageID,
+		Category:  snapshot.Category,
+	}, actor)
+}
+
+// React adds the actor's reaction to a news item they can see, or removes it
+// when remove is set
+func (s *NewsService) React(id uuid.UUID, reaction string, remove bool, actor *Actor) (*models.News, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	if _, ok := models.Reactions[reaction]; !ok {
+		return nil, fmt.Errorf("%w: unknown reaction %q", ErrInvalidInput, reaction)
+	}
+	news, err := s.getNews(id)
+	if err != nil {
+		return nil, err
+	}
+	if news.Status != models.NewsStatusPublished && !actor.Owns(news.UserID) {
+		return nil, ErrNotFound
+	}
+	if err := checkCohortWritable(s.cohorts, news.CohortID); err != nil {
+		return nil, err
+	}
+
+	if remove {
+		err = s.repo.RemoveReaction(id, actor.UserID, reaction)
+	} else {
+		err = s.repo.AddReaction(id, actor.UserID, reaction, time.Now().UTC())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetNews(id, actor, "")
+}
+
+// Pin keeps a news item at the top of the list until the given time, or
+// until it is unpinned when until is nil. Admins only.
+func (s *NewsService) Pin(id uuid.UUID, until *time.Time, actor *Actor) (*models.News, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	now := time.Now().UTC()
+	if until != nil && !until.After(now) {
+		return nil, fmt.Errorf("%w: pinned_until must be in the future", ErrInvalidInput)
+	}
+	if _, err := s.getUnarchivedNews(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPinned(id, &now, utcTime(until)); err != nil {
+		return nil, err
+	}
+	return s.GetNews(id, actor, "")
+}
+
+func (s *NewsService) Unpin(id uuid.UUID, actor *Actor) (*models.News, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if _, err := s.getUnarchivedNews(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPinned(id, nil, nil); err != nil {
+		return nil, err
+	}
+	return s.GetNews(id, actor, "")
+}
+
+// SetFeatured adds a news item to or removes it from the featured list.
+// Admins only.
+func (s *NewsService) SetFeatured(id uuid.UUID, featured bool, actor *Actor) (*models.News, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if _, err := s.getUnarchivedNews(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetFeatured(id, featured); err != nil {
+		return nil, err
+	}
+	return s.GetNews(id, actor, "")
+}
+
+// DeleteNews moves a news item to the trash, from where admins can restore
+// it until it is purged
+func (s *NewsService) DeleteNews(id uuid.UUID, actor *Actor) error {
+	if _, err := s.getWritableNews(id, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteNews(id, time.Now().UTC())
+}
+
+// PublishScheduled publishes every scheduled news item that is due
+func (s *NewsService) PublishScheduled(now time.Time) (int64, error) {
+	return s.repo.PublishDueNews(now)
+}
+
+// setImage points the news image at an uploaded asset, or at the free-form
+// path when no asset is referenced. The path of an uploaded image is not
+// stored; hydrate fills it in from the asset URL.
+func (s *NewsService) setImage(news *models.News, input NewsInput) error {
+	if input.ImageID == nil {
+		news.ImageID = nil
+		news.Image = nil
+		news.ImagePath = input.ImagePath
+		return nil
+	}
+	asset, err := s.uploads.ResolveImage(*input.ImageID)
+	if err != nil {
+		return err
+	}
+	news.ImageID = &asset.ID
+	news.ImagePath = ""
+	news.Image = asset
+	return nil
+}
+
+// hydrate fills in the fields of news that are derived rather than stored.
+// The reactions the actor gave are included when actor is not nil.
+func (s *NewsService) hydrate(newsList []*models.News, actor *Actor, locale string) error {
+	if err := s.translate(newsList, locale); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, news := range newsList {
+		news.ContentHTML = markdown.ToHTML(news.Content)
+		news.Excerpt = markdown.Excerpt(news.ContentHTML, markdown.ExcerptLength)
+		news.Pinned = news.PinnedAt != nil && (news.PinnedUntil == nil || news.PinnedUntil.After(now))
+		s.uploads.setAvatarURL(news.Author)
+	}
+	if err := s.attachReactions(newsList, actor); err != nil {
+		return err
+	}
+	return s.attachImages(newsList)
+}
+
+// translate swaps in the title and content of the requested locale where a
+// translation exists, loading the translations of a batch of news in one go
+func (s *NewsService) translate(newsList []*models.News, l string) error {
+	if len(newsList) == 0 {
+		return nil
+	}
+	l = resolveLocale(s.locales, l)
+	ids := make([]uuid.UUID, len(newsList))
+	for i, news := range newsList {
+		ids[i] = news.ID
+	}
+
+	translations, err := s.translations.GetTranslations(repository.TranslationEntityNews, ids)
+	if err != nil {
+		return err
+	}
+	for _, news := range newsList {
+		news.Locale = s.locales.Default
+		news.AvailableLocales = availableLocales(s.locales, translations[news.ID])
+		if t := findTranslation(translations[news.ID], l); t != nil {
+			news.Title = t.Title
+			news.Content = t.Content
+			news.Locale = t.Locale
+		}
+	}
+	return nil
+}
+
+// SaveTranslation adds or replaces the translation of a news item into a
+// non-default locale
+func (s *NewsService) SaveTranslation(id uuid.UUID, l, title, content string, actor *Actor) (*models.Translation, error) {
+	if _, err := s.getWritableNews(id, actor); err != nil {
+		return nil, err
+	}
+	return saveTranslation(s.translations, s.locales, repository.TranslationEntityNews, id, l, title, content, actor.UserID)
+}
+
+func (s *NewsService) DeleteTranslation(id uuid.UUID, l string, actor *Actor) error {
+	if _, err := s.getWritableNews(id, actor); err != nil {
+		return err
+	}
+	return deleteTranslation(s.translations, s.locales, repository.TranslationEntityNews, id, l)
+}
+
+// attachReactions loads the reaction counts of a batch of news in one go
+func (s *NewsService) attachReactions(newsList []*models.News, actor *Actor) error {
+	if len(newsList) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(newsList))
+	for i, news := range newsList {
+		ids[i] = news.ID
+	}
+
+	counts, err := s.repo.GetReactionCounts(ids)
+	if err != nil {
+		return err
+	}
+	var given map[uuid.UUID][]string
+	if actor != nil {
+		if given, err = s.repo.GetUserReactions(ids, actor.UserID); err != nil {
+			return err
+		}
+	}
+	for _, news := range newsList {
+		news.Reactions = counts[news.ID]
+		if news.Reactions == nil {
+			news.Reactions = map[string]int{}
+		}
+		news.UserReactions = given[news.ID]
+		if news.UserReactions == nil {
+			news.UserReactions = []string{}
+		}
+	}
+	return nil
+}
+
+// attachImages loads the uploaded images of a batch of news in one go and
+// points their image path at the current image URL
+func (s *NewsService) attachImages(newsList []*models.News) error {
+	var ids []uuid.UUID
+	for _, news := range newsList {
+		if news.ImageID != nil {
+			ids = append(ids, *news.ImageID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	images, err := s.uploads.PublicImages(ids)
+	if err != nil {
+		return err
+	}
+	for _, news := range newsList {
+		if news.ImageID == nil {
+			continue
+		}
+		news.Image = images[*news.ImageID]
+		if news.Image != nil {
+			news.ImagePath = news.Image.URL
+		}
+	}
+	return nil
+}
+
+// newRevision builds the revision recording the current state of a news item
+func (s *NewsService) newRevision(news *models.News, userID uuid.UUID) (*models.Revision, error) {
+	return newRevision(repository.RevisionEntityNews, news.ID, models.NewsSnapshot{
+		Title:       news.Title,
+		Slug:        news.Slug,
+		Content:     news.Content,
+		ImagePath:   news.ImagePath,
+		ImageID:     news.ImageID,
+		Category:    news.Category,
+		Status:      news.Status,
+		PublishedAt: news.PublishedAt,
+	}, userID)
+}
+
+func (s *NewsService) getNews(id uuid.UUID) (*models.News, error) {
+	news, err := s.repo.GetNewsByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return news, err
+}
+
+func (s *NewsService) getOwnedNews(id uuid.UUID, actor *Actor) (*models.News, error) {
+	news, err := s.getNews(id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Owns(news.UserID) {
+		return nil, ErrForbidden
+	}
 	return news, nil
 }
+
+// getWritableNews is getOwnedNews for changes, which are rejected once the
+// item's cohort is archived
+func (s *NewsService) getWritableNews(id uuid.UUID, actor *Actor) (*models.News, error) {
+	news, err := s.getOwnedNews(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.cohorts, news.CohortID); err != nil {
+		return nil, err
+	}
+	return news, nil
+}
+
+// getUnarchivedNews returns a news item unless its cohort is archived
+func (s *NewsService) getUnarchivedNews(id uuid.UUID) (*models.News, error) {
+	news, err := s.getNews(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCohortWritable(s.cohorts, news.CohortID); err != nil {
+		return nil, err
+	}
+	return news, nil
+}
+
+func applyNewsStatus(news *models.News, status string, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case models.NewsStatusDraft:
+		news.PublishedAt = nil
+	case models.NewsStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return fmt.Errorf("%w: scheduled news needs a future published_at", ErrInvalidInput)
+		}
+		news.PublishedAt = utcTime(publishAt)
+	case models.NewsStatusPublished:
+		// Keep the original publish date when re-publishing archived news
+		if news.PublishedAt == nil || news.PublishedAt.After(now) {
+			news.PublishedAt = utcTime(&now)
+		}
+	case models.NewsStatusArchived:
+		if news.Status != models.NewsStatusPublished {
+			return fmt.Errorf("%w: only published news can be archived", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidInput, status)
+	}
+	news.Status = status
+	return nil
+}
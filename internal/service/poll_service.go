@@ -0,0 +1,331 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 20
+	// maxPollOptionLength matches the poll_options.text column
+	maxPollOptionLength = 200
+)
+
+// PollInput holds the settings of a new poll
+type PollInput struct {
+	Question string
+	Options  []string
+	Multiple bool
+	// Anonymous defaults to true when nil
+	Anonymous *bool
+	// ResultsVisibility defaults to showing results after voting
+	ResultsVisibility string
+	// ClosesAt leaves the poll open until closed by hand when nil
+	ClosesAt *time.Time
+}
+
+// PollService manages the polls attached to news items. Anyone who can see a
+// news item can see its poll; signed-in users vote once.
+type PollService struct {
+	repo *repository.PollRepository
+	news *NewsService
+}
+
+func NewPollService(repo *repository.PollRepository, news *NewsService) *PollService {
+	return &PollService{repo: repo, news: news}
+}
+
+// GetPoll returns the poll of a news item with the actor's vote and, when
+// they are allowed to see them, the current results
+func (s *PollService) GetPoll(newsID uuid.UUID, actor *Actor) (*models.Poll, error) {
+	news, err := s.news.GetNews(newsID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	poll, err := s.getPoll(newsID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fill(poll, news, actor, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// GetResults returns the current results of the poll on a news item, or
+// ErrForbidden while the poll settings hide them from the actor
+func (s *PollService) GetResults(newsID uuid.UUID, actor *Actor) (*models.PollResults, error) {
+	poll, err := s.GetPoll(newsID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Results == nil {
+		return nil, fmt.Errorf("%w: results are hidden until you vote or the poll closes", ErrForbidden)
+	}
+	return poll.Results, nil
+}
+
+// CreatePoll attaches a poll to a news item. Only the author of the news
+// item or an admin can add one, and each item has at most one poll.
+func (s *PollService) CreatePoll(newsID uuid.UUID, input PollInput, actor *Actor) (*models.Poll, error) {
+	news, err := s.news.getWritableNews(newsID, actor)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	poll, err := newPoll(newsID, input, actor.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getPoll(newsID); err == nil {
+		return nil, fmt.Errorf("%w: the news item already has a poll", ErrConflict)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.CreatePoll(poll); err != nil {
+		return nil, err
+	}
+	if err := s.fill(poll, news, actor, now); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// DeletePoll removes the poll of a news item together with its votes
+func (s *PollService) DeletePoll(newsID uuid.UUID, actor *Actor) error {
+	if _, err := s.news.getWritableNews(newsID, actor); err != nil {
+		return err
+	}
+	poll, err := s.getPoll(newsID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeletePoll(poll.ID)
+}
+
+// ClosePoll stops a poll from taking further votes
+func (s *PollService) ClosePoll(newsID uuid.UUID, actor *Actor) (*models.Poll, error) {
+	news, err := s.news.getWritableNews(newsID, actor)
+	if err != nil {
+		return nil, err
+	}
+	poll, err := s.getPoll(newsID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := s.repo.ClosePoll(poll.ID, now); err != nil {
+		return nil, err
+	}
+	if poll.ClosesAt == nil || poll.ClosesAt.After(now) {
+		poll.ClosesAt = &now
+	}
+	if err := s.fill(poll, news, actor, now); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// Vote casts the actor's ballot. Single choice polls take exactly one
+// option; a ballot cannot be changed once cast.
+func (s *PollService) Vote(newsID uuid.UUID, optionIDs []uuid.UUID, actor *Actor) (*models.Poll, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	news, err := s.news.GetNews(newsID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	if news.Status != models.NewsStatusPublished {
+		return nil, fmt.Errorf("%w: polls on unpublished news cannot be voted on", ErrInvalidInput)
+	}
+	if err := checkCohortWritable(s.news.cohorts, news.CohortID); err != nil {
+		return nil, err
+	}
+	poll, err := s.getPoll(newsID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if pollClosed(poll, now) {
+		return nil, fmt.Errorf("%w: the poll is closed", ErrInvalidInput)
+	}
+
+	chosen, err := checkChoices(poll, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+	voted, err := s.repo.Vote(poll.ID, actor.UserID, chosen, now)
+	if err != nil {
+		return nil, err
+	}
+	if !voted {
+		return nil, fmt.Errorf("%w: you have already voted", ErrConflict)
+	}
+	if err := s.fill(poll, news, actor, now); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// fill sets the fields of a poll that depend on the time and on who is
+// asking: whether it is closed, the actor's vote and the visible results
+func (s *PollService) fill(poll *models.Poll, news *models.News, actor *Actor, now time.Time) error {
+	poll.Closed = pollClosed(poll, now)
+	poll.UserVotes = make([]uuid.UUID, 0)
+	if actor != nil {
+		votes, err := s.repo.GetUserVotes(poll.ID, actor.UserID)
+		if err != nil {
+			return err
+		}
+		poll.Voted = len(votes) > 0
+		poll.UserVotes = append(poll.UserVotes, votes...)
+	}
+
+	poll.Results = nil
+	if !resultsVisible(poll, news, actor) {
+		return nil
+	}
+	counts, voters, err := s.repo.GetVoteCounts(poll.ID)
+	if err != nil {
+		return err
+	}
+	var names map[uuid.UUID][]*models.PollVoter
+	if !poll.Anonymous {
+		if names, err = s.repo.GetVoters(poll.ID); err != nil {
+			return err
+		}
+	}
+	results := &models.PollResults{TotalVoters: voters, Options: make([]*models.PollOptionResult, len(poll.Options))}
+	for i, option := range poll.Options {
+		results.Options[i] = &models.PollOptionResult{
+			OptionID: option.ID,
+			Text:     option.Text,
+			Votes:    counts[option.ID],
+			Voters:   names[option.ID],
+		}
+	}
+	poll.Results = results
+	return nil
+}
+
+func (s *PollService) getPoll(newsID uuid.UUID) (*models.Poll, error) {
+	poll, err := s.repo.GetPollByNewsID(newsID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return poll, err
+}
+
+// newPoll validates input and builds the poll it describes
+func newPoll(newsID uuid.UUID, input PollInput, userID uuid.UUID, now time.Time) (*models.Poll, error) {
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		return nil, fmt.Errorf("%w: question is empty", ErrInvalidInput)
+	}
+	if len(input.Options) < MinPollOptions || len(input.Options) > MaxPollOptions {
+		return nil, fmt.Errorf("%w: a poll needs %d to %d options", ErrInvalidInput, MinPollOptions, MaxPollOptions)
+	}
+	visibility := input.ResultsVisibility
+	if visibility == "" {
+		visibility = models.PollResultsAfterVote
+	}
+	switch visibility {
+	case models.PollResultsAlways, models.PollResultsAfterVote, models.PollResultsAfterClose:
+	default:
+		return nil, fmt.Errorf("%w: unknown results visibility %q", ErrInvalidInput, visibility)
+	}
+	if input.ClosesAt != nil && !input.ClosesAt.After(now) {
+		return nil, fmt.Errorf("%w: closes_at must be in the future", ErrInvalidInput)
+	}
+	anonymous := true
+	if input.Anonymous != nil {
+		anonymous = *input.Anonymous
+	}
+
+	poll := &models.Poll{
+		ID:                uuid.New(),
+		NewsID:            newsID,
+		Question:          question,
+		Multiple:          input.Multiple,
+		Anonymous:         anonymous,
+		ResultsVisibility: visibility,
+		ClosesAt:          utcTime(input.ClosesAt),
+		Options:           make([]*models.PollOption, 0, len(input.Options)),
+		UserID:            userID,
+		CreatedAt:         now,
+	}
+	seen := make(map[string]bool, len(input.Options))
+	for _, text := range input.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, fmt.Errorf("%w: options cannot be empty", ErrInvalidInput)
+		}
+		if utf8.RuneCountInString(text) > maxPollOptionLength {
+			return nil, fmt.Errorf("%w: options are limited to %d characters", ErrInvalidInput, maxPollOptionLength)
+		}
+		if seen[strings.ToLower(text)] {
+			return nil, fmt.Errorf("%w: duplicate option %q", ErrInvalidInput, text)
+		}
+		seen[strings.ToLower(text)] = true
+		poll.Options = append(poll.Options, &models.PollOption{ID: uuid.New(), Text: text})
+	}
+	return poll, nil
+}
+
+// checkChoices validates the options chosen on a ballot and drops duplicates
+func checkChoices(poll *models.Poll, optionIDs []uuid.UUID) ([]uuid.UUID, error) {
+	valid := make(map[uuid.UUID]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	chosen := make([]uuid.UUID, 0, len(optionIDs))
+	seen := make(map[uuid.UUID]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, fmt.Errorf("%w: option %s is not part of the poll", ErrInvalidInput, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			chosen = append(chosen, id)
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, fmt.Errorf("%w: choose an option", ErrInvalidInput)
+	}
+	if len(chosen) > 1 && !poll.Multiple {
+		return nil, fmt.Errorf("%w: the poll allows a single choice", ErrInvalidInput)
+	}
+	return chosen, nil
+}
+
+func pollClosed(poll *models.Poll, now time.Time) bool {
+	return poll.ClosesAt != nil && !poll.ClosesAt.After(now)
+}
+
+// resultsVisible applies the poll's results setting. The author of the news
+// item and admins always see the results.
+func resultsVisible(poll *models.Poll, news *models.News, actor *Actor) bool {
+	if poll.Closed || actor.Owns(news.UserID) {
+		return true
+	}
+	switch poll.ResultsVisibility {
+	case models.PollResultsAlways:
+		return true
+	case models.PollResultsAfterVote:
+		return poll.Voted
+	default:
+		return false
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SetPrerequisites replaces the entries that must be covered before an entry.
+// Prerequisites come from the same cirriculum, are scheduled no later than
+// the entry and may not lead back to it.
+func (s *CirriculumService) SetPrerequisites(id uuid.UUID, prerequisiteIDs []uuid.UUID, actor *Actor) (*models.Cirriculum, error) {
+	cirriculum, err := s.getWritableCirriculum(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetAllCirriculum(cirriculum.CohortID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Cirriculum, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	seen := make(map[uuid.UUID]bool, len(prerequisiteIDs))
+	for _, prerequisiteID := range prerequisiteIDs {
+		if prerequisiteID == id {
+			return nil, fmt.Errorf("%w: an entry cannot require itself", ErrInvalidInput)
+		}
+		if seen[prerequisiteID] {
+			return nil, fmt.Errorf("%w: prerequisite %s is listed twice", ErrInvalidInput, prerequisiteID)
+		}
+		seen[prerequisiteID] = true
+		prerequisite, ok := byID[prerequisiteID]
+		if !ok {
+			return nil, fmt.Errorf("%w: prerequisite %s is not an entry of the same cirriculum", ErrInvalidInput, prerequisiteID)
+		}
+		if prerequisite.Week > cirriculum.Week {
+			return nil, fmt.Errorf("%w: %q is scheduled in week %d, after week %d", ErrInvalidInput, prerequisite.Title, prerequisite.Week, cirriculum.Week)
+		}
+	}
+
+	edges, err := s.repo.GetCohortPrerequisites(cirriculum.CohortID)
+	if err != nil {
+		return nil, err
+	}
+	edges[id] = prerequisiteIDs
+	for _, prerequisiteID := range prerequisiteIDs {
+		if path := prerequisitePath(edges, prerequisiteID, id); path != nil {
+			titles := []string{cirriculum.Title}
+			for _, step := range path {
+				titles = append(titles, byID[step].Title)
+			}
+			return nil, fmt.Errorf("%w: prerequisites would form a cycle: %s", ErrInvalidInput, strings.Join(titles, " → "))
+		}
+	}
+
+	if err := s.repo.SetPrerequisites(id, prerequisiteIDs); err != nil {
+		return nil, err
+	}
+	return cirriculum, s.hydrate([]*models.Cirriculum{cirriculum}, "")
+}
+
+// GetGraph returns the dependency graph of a cohort's cirriculum, or of the
+// shared cirriculum when cohortID is nil, limited to the entries the actor
+// can see
+func (s *CirriculumService) GetGraph(cohortID *uuid.UUID, actor *Actor, locale string) (*models.CirriculumGraph, error) {
+	cirricula, err := s.GetAllCirriculum(cohortID, actor, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &models.CirriculumGraph{
+		CohortID: cohortID,
+		Nodes:    make([]*models.CirriculumGraphNode, 0, len(cirricula)),
+		Edges:    make([]*models.CirriculumGraphEdge, 0),
+	}
+	visible := make(map[uuid.UUID]bool, len(cirricula))
+	for _, cirriculum := range cirricula {
+		visible[cirriculum.ID] = true
+		graph.Nodes = append(graph.Nodes, &models.CirriculumGraphNode{
+			ID:       cirriculum.ID,
+			Slug:     cirriculum.Slug,
+			Title:    cirriculum.Title,
+			Week:     cirriculum.Week,
+			Released: cirriculum.Released,
+		})
+	}
+	for _, cirriculum := range cirricula {
+		for _, prerequisiteID := range cirriculum.Prerequisites {
+			if visible[prerequisiteID] {
+				graph.Edges = append(graph.Edges, &models.CirriculumGraphEdge{From: prerequisiteID, To: cirriculum.ID})
+			}
+		}
+	}
+	return graph, nil
+}
+
+// CirriculumGraphDOT renders a dependency graph in the Graphviz DOT language,
+// grouping the entries of each week. Entries not released yet are dashed.
+func CirriculumGraphDOT(graph *models.CirriculumGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph cirriculum {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	week := 0
+	for _, node := range graph.Nodes {
+		if node.Week != week {
+			if week != 0 {
+				b.WriteString("\t}\n")
+			}
+			week = node.Week
+			fmt.Fprintf(&b, "\tsubgraph cluster_week_%d {\n\t\tlabel=%s;\n", week, strconv.Quote(fmt.Sprintf("Week %d", week)))
+		}
+		style := ""
+		if !node.Released {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\t\t%s [label=%s%s];\n", strconv.Quote(node.ID.String()), strconv.Quote(node.Title), style)
+	}
+	if week != 0 {
+		b.WriteString("\t}\n")
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", strconv.Quote(edge.From.String()), strconv.Quote(edge.To.String()))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// checkPrerequisiteWeeks rejects a schedule that puts a prerequisite in a
+// later week than an entry requiring it. entries holds every entry of the
+// cohort with the weeks they are about to move to.
+func (s *CirriculumService) checkPrerequisiteWeeks(cohortID *uuid.UUID, entries []*models.Cirriculum) error {
+	edges, err := s.repo.GetCohortPrerequisites(cohortID)
+	if err != nil {
+		return err
+	}
+	return checkWeekOrder(edges, entries)
+}
+
+// checkWeekOrder rejects prerequisites scheduled in a later week than the
+// entries requiring them. Links to entries missing from entries are ignored.
+func checkWeekOrder(edges map[uuid.UUID][]uuid.UUID, entries []*models.Cirriculum) error {
+	byID := make(map[uuid.UUID]*models.Cirriculum, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+	for _, entry := range entries {
+		for _, prerequisiteID := range edges[entry.ID] {
+			prerequisite, ok := byID[prerequisiteID]
+			if ok && prerequisite.Week > entry.Week {
+				return fmt.Errorf("%w: %q would be in week %d, after %q in week %d which requires it", ErrInvalidInput, prerequisite.Title, prerequisite.Week, entry.Title, entry.Week)
+			}
+		}
+	}
+	return nil
+}
+
+// prerequisitePath returns the chain of prerequisites leading from one entry
+// to another, starting with from, or nil when there is none
+func prerequisitePath(edges map[uuid.UUID][]uuid.UUID, from, to uuid.UUID) []uuid.UUID {
+	visited := make(map[uuid.UUID]bool)
+	var walk func(id uuid.UUID) []uuid.UUID
+	walk = func(id uuid.UUID) []uuid.UUID {
+		if id == to {
+			return []uuid.UUID{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, next := range edges[id] {
+			if path := walk(next); path != nil {
+				return append([]uuid.UUID{id}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"blazperic/radionica/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestPrerequisitePath(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name  string
+		edges map[uuid.UUID][]uuid.UUID
+		from  uuid.UUID
+		to    uuid.UUID
+		want  []uuid.UUID
+	}{
+		{
+			// b is about to require a, which already requires b
+			name:  "direct cycle",
+			edges: map[uuid.UUID][]uuid.UUID{a: {b}, b: {a}},
+			from:  a,
+			to:    b,
+			want:  []uuid.UUID{a, b},
+		},
+		{
+			// c is about to require a, which leads to c through b
+			name:  "indirect cycle",
+			edges: map[uuid.UUID][]uuid.UUID{a: {b}, b: {c}, c: {a}},
+			from:  a,
+			to:    c,
+			want:  []uuid.UUID{a, b, c},
+		},
+		{
+			name:  "indirect cycle through a later branch",
+			edges: map[uuid.UUID][]uuid.UUID{a: {d, b}, b: {c}},
+			from:  a,
+			to:    c,
+			want:  []uuid.UUID{a, b, c},
+		},
+		{
+			name:  "no cycle",
+			edges: map[uuid.UUID][]uuid.UUID{a: {b}, b: {c}},
+			from:  c,
+			to:    a,
+			want:  nil,
+		},
+		{
+			// a reaches c along two paths, and d is never reached
+			name:  "no cycle in a diamond",
+			edges: map[uuid.UUID][]uuid.UUID{a: {b, c}, b: {c}, c: {}},
+			from:  a,
+			to:    d,
+			want:  nil,
+		},
+		{
+			name:  "existing cycle elsewhere",
+			edges: map[uuid.UUID][]uuid.UUID{a: {b}, b: {a}},
+			from:  a,
+			to:    c,
+			want:  nil,
+		},
+		{
+			name:  "no edges",
+			edges: map[uuid.UUID][]uuid.UUID{},
+			from:  a,
+			to:    b,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := prerequisitePath(tt.edges, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("prerequisitePath() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("prerequisitePath() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckWeekOrder(t *testing.T) {
+	basics := &models.Cirriculum{ID: uuid.New(), Title: "Basics", Week: 1}
+	loops := &models.Cirriculum{ID: uuid.New(), Title: "Loops", Week: 2}
+	functions := &models.Cirriculum{ID: uuid.New(), Title: "Functions", Week: 2}
+	trashed := uuid.New()
+
+	tests := []struct {
+		name    string
+		edges   map[uuid.UUID][]uuid.UUID
+		wantErr bool
+	}{
+		{
+			name:  "prerequisite in an earlier week",
+			edges: map[uuid.UUID][]uuid.UUID{loops.ID: {basics.ID}},
+		},
+		{
+			name:  "prerequisite in the same week",
+			edges: map[uuid.UUID][]uuid.UUID{functions.ID: {loops.ID}},
+		},
+		{
+			name:    "prerequisite in a later week",
+			edges:   map[uuid.UUID][]uuid.UUID{basics.ID: {loops.ID}},
+			wantErr: true,
+		},
+		{
+			name:  "prerequisite not among the entries",
+			edges: map[uuid.UUID][]uuid.UUID{basics.ID: {trashed}},
+		},
+		{
+			name: "no prerequisites",
+		},
+	}
+
+	entries := []*models.Cirriculum{basics, loops, functions}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWeekOrder(tt.edges, entries)
+			if tt.wantErr && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("checkWeekOrder() error = %v, want ErrInvalidInput", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkWeekOrder() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCirriculumGraphDOT(t *testing.T) {
+	intro := &models.CirriculumGraphNode{ID: uuid.New(), Title: `Say "hello"`, Week: 1, Released: true}
+	paths := &models.CirriculumGraphNode{ID: uuid.New(), Title: `C:\go\bin`, Week: 1, Released: true}
+	next := &models.CirriculumGraphNode{ID: uuid.New(), Title: "Next steps", Week: 3}
+	graph := &models.CirriculumGraph{
+		Nodes: []*models.CirriculumGraphNode{intro, paths, next},
+		Edges: []*models.CirriculumGraphEdge{{From: intro.ID, To: next.ID}},
+	}
+
+	got := CirriculumGraphDOT(graph)
+	want := "digraph cirriculum {\n" +
+		"\trankdir=LR;\n" +
+		"\tnode [shape=box];\n" +
+		"\tsubgraph cluster_week_1 {\n" +
+		"\t\tlabel=\"Week 1\";\n" +
+		"\t\t\"" + intro.ID.String() + "\" [label=\"Say \\\"hello\\\"\"];\n" +
+		"\t\t\"" + paths.ID.String() + "\" [label=\"C:\\\\go\\\\bin\"];\n" +
+		"\t}\n" +
+		"\tsubgraph cluster_week_3 {\n" +
+		"\t\tlabel=\"Week 3\";\n" +
+		"\t\t\"" + next.ID.String() + "\" [label=\"Next steps\", style=dashed];\n" +
+		"\t}\n" +
+		"\t\"" + intro.ID.String() + "\" -> \"" + next.ID.String() + "\";\n" +
+		"}\n"
+	if got != want {
+		t.Errorf("CirriculumGraphDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCirriculumGraphDOTEmpty(t *testing.T) {
+	got := CirriculumGraphDOT(&models.CirriculumGraph{})
+	if strings.Contains(got, "subgraph") || !strings.HasSuffix(got, "}\n") {
+		t.Errorf("CirriculumGraphDOT() = %q, want an empty digraph", got)
+	}
+}
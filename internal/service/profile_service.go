@@ -0,0 +1,116 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxDisplayNameLength matches the display_name column
+const maxDisplayNameLength = 100
+
+// ProfileService manages the public profile users are shown with as authors
+type ProfileService struct {
+	users   *repository.UserRepository
+	uploads *UploadService
+}
+
+func NewProfileService(users *repository.UserRepository, uploads *UploadService) *ProfileService {
+	return &ProfileService{users: users, uploads: uploads}
+}
+
+// ProfilePatch holds the profile fields to change. A nil field is left as it
+// is; an empty display name falls back to the username, and SetAvatar with a
+// nil AvatarID removes the avatar.
+type ProfilePatch struct {
+	DisplayName *string
+	SetAvatar   bool
+	AvatarID    *uuid.UUID
+}
+
+// UpdateProfile changes the actor's display name and avatar. The avatar must
+// be a public image the actor uploaded.
+func (s *ProfileService) UpdateProfile(patch ProfilePatch, actor *Actor) (*models.Author, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	profile, err := s.users.GetProfile(actor.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var displayName *string
+	if profile.DisplayName != profile.Username {
+		displayName = &profile.DisplayName
+	}
+	if patch.DisplayName != nil {
+		name, err := validateDisplayName(*patch.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		displayName = nil
+		if name != "" {
+			displayName = &name
+		}
+	}
+	avatarID := profile.AvatarID
+	if patch.SetAvatar {
+		if patch.AvatarID != nil {
+			if err := s.checkAvatar(*patch.AvatarID, actor); err != nil {
+				return nil, err
+			}
+		}
+		avatarID = patch.AvatarID
+	}
+
+	if err := s.users.UpdateProfile(actor.UserID, displayName, avatarID); err != nil {
+		return nil, err
+	}
+	profile.DisplayName = profile.Username
+	if displayName != nil {
+		profile.DisplayName = *displayName
+	}
+	profile.AvatarID = avatarID
+	profile.AvatarURL = ""
+	s.uploads.setAvatarURL(profile)
+	return profile, nil
+}
+
+// checkAvatar makes sure an asset can be used as the actor's avatar
+func (s *ProfileService) checkAvatar(id uuid.UUID, actor *Actor) error {
+	asset, err := s.uploads.ResolveImage(id)
+	if err != nil {
+		return err
+	}
+	if asset.UserID != actor.UserID {
+		return fmt.Errorf("%w: avatar %s was uploaded by someone else", ErrForbidden, id)
+	}
+	if _, ok := allowedImageTypes[asset.ContentType]; !ok {
+		return fmt.Errorf("%w: avatar %s is not an image", ErrInvalidInput, id)
+	}
+	return nil
+}
+
+// validateDisplayName trims a display name and rejects names that are too
+// long or contain control characters
+func validateDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", fmt.Errorf("%w: display name is longer than %d characters", ErrInvalidInput, maxDisplayNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: display name contains control characters", ErrInvalidInput)
+	}
+	return name, nil
+}
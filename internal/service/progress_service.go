@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ProgressService tracks how far users got with the cirriculum. Students mark
+// the entries released to them; mentors follow the progress of a cohort.
+type ProgressService struct {
+	repo       *repository.ProgressRepository
+	cohorts    *repository.CohortRepository
+	cirriculum *CirriculumService
+}
+
+func NewProgressService(repo *repository.ProgressRepository, cohorts *repository.CohortRepository, cirriculum *CirriculumService) *ProgressService {
+	return &ProgressService{repo: repo, cohorts: cohorts, cirriculum: cirriculum}
+}
+
+// SetProgress marks an entry as started or completed for the actor
+func (s *ProgressService) SetProgress(cirriculumID uuid.UUID, status string, actor *Actor) (*models.Progress, error) {
+	if status != models.ProgressStatusStarted && status != models.ProgressStatusCompleted {
+		return nil, fmt.Errorf("%w: status must be started or completed", ErrInvalidInput)
+	}
+	if _, err := s.getTrackable(cirriculumID, actor); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	progress := &models.Progress{
+		CirriculumID: cirriculumID,
+		UserID:       actor.UserID,
+		Status:       status,
+		StartedAt:    now,
+		UpdatedAt:    now,
+	}
+	if status == models.ProgressStatusCompleted {
+		progress.CompletedAt = &now
+	}
+	if err := s.repo.SaveProgress(progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// ClearProgress resets the actor's progress on an entry to not started
+func (s *ProgressService) ClearProgress(cirriculumID uuid.UUID, actor *Actor) error {
+	if _, err := s.getTrackable(cirriculumID, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteProgress(actor.UserID, cirriculumID)
+}
+
+// GetMyProgress sums up the actor's progress through the cirriculum of a
+// cohort, or the shared one when cohortID is nil, counting the entries
+// released so far
+func (s *ProgressService) GetMyProgress(cohortID *uuid.UUID, actor *Actor) (*models.ProgressSummary, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	cirricula, err := s.cirriculum.GetAllCirriculum(cohortID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	released := make([]*models.Cirriculum, 0, len(cirricula))
+	for _, cirriculum := range cirricula {
+		if cirriculum.Released {
+			released = append(released, cirriculum)
+		}
+	}
+	statuses, err := s.statuses(released, &actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.ProgressSummary{CohortID: cohortID, Weeks: make([]models.WeekProgress, 0)}
+	for _, cirriculum := range released {
+		status := statuses[actor.UserID][cirriculum.ID]
+		countProgress(&summary.Overall, status)
+		if n := len(summary.Weeks); n == 0 || summary.Weeks[n-1].Week != cirriculum.Week {
+			summary.Weeks = append(summary.Weeks, models.WeekProgress{Week: cirriculum.Week})
+		}
+		countProgress(&summary.Weeks[len(summary.Weeks)-1].ProgressStats, status)
+	}
+	setPercent(&summary.Overall)
+	for i := range summary.Weeks {
+		setPercent(&summary.Weeks[i].ProgressStats)
+	}
+	return summary, nil
+}
+
+// GetCohortProgress returns the status of every student of a cohort on
+// every entry of its cirriculum. Students who are furthest behind come
+// first: those with the most overdue entries, then the lowest completion.
+// Mentors only.
+func (s *ProgressService) GetCohortProgress(cohortID uuid.UUID, actor *Actor) (*models.CohortProgress, error) {
+	if !actor.IsMentor() {
+		return nil, ErrForbidden
+	}
+	cohort, err := getCohort(s.cohorts, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	cirricula, err := s.cirriculum.GetAllCirriculum(&cohortID, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.cohorts.GetMembers(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := s.statuses(cirricula, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	matrix := &models.CohortProgress{
+		CohortID: cohortID,
+		Entries:  make([]*models.ProgressEntry, 0, len(cirricula)),
+		Students: make([]*models.StudentProgress, 0, len(members)),
+	}
+	// due marks the released entries whose week is over
+	due := make([]bool, len(cirricula))
+	for i, cirriculum := range cirricula {
+		matrix.Entries = append(matrix.Entries, &models.ProgressEntry{
+			ID:       cirriculum.ID,
+			Title:    cirriculum.Title,
+			Week:     cirriculum.Week,
+			Released: cirriculum.Released,
+		})
+		weekEnd, err := weekStart(cohort, cirriculum.Week+1)
+		if err != nil {
+			return nil, err
+		}
+		due[i] = cirriculum.Released && !now.Before(weekEnd)
+	}
+
+	for _, member := range members {
+		if member.Role != models.RoleStudent {
+			continue
+		}
+		student := &models.StudentProgress{
+			UserID:   member.UserID,
+			Username: member.Username,
+			Statuses: make([]string, len(cirricula)),
+		}
+		for i, cirriculum := range cirricula {
+			status := statuses[member.UserID][cirriculum.ID]
+			if status == "" {
+				status = models.ProgressStatusNotStarted
+			}
+			student.Statuses[i] = status
+			if cirriculum.Released {
+				countProgress(&student.ProgressStats, status)
+			}
+			if due[i] && status != models.ProgressStatusCompleted {
+				student.Overdue++
+			}
+		}
+		setPercent(&student.ProgressStats)
+		matrix.Students = append(matrix.Students, student)
+	}
+	sort.SliceStable(matrix.Students, func(i, j int) bool {
+		a, b := matrix.Students[i], matrix.Students[j]
+		if a.Overdue != b.Overdue {
+			return a.Overdue > b.Overdue
+		}
+		return a.Percent < b.Percent
+	})
+	return matrix, nil
+}
+
+// getTrackable returns an entry the actor may record progress on: one that
+// is released and either shared or from a cohort they are enrolled in that
+// is not archived
+func (s *ProgressService) getTrackable(id uuid.UUID, actor *Actor) (*models.Cirriculum, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	cirriculum, err := s.cirriculum.GetCirriculum(id, actor, "")
+	if err != nil {
+		return nil, err
+	}
+	if !cirriculum.Released {
+		return nil, fmt.Errorf("%w: the entry is not released yet", ErrInvalidInput)
+	}
+	if cirriculum.CohortID != nil {
+		member, err := s.cohorts.IsMember(*cirriculum.CohortID, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, fmt.Errorf("%w: only members of the cohort can track its cirriculum", ErrForbidden)
+		}
+		if err := checkCohortWritable(s.cohorts, cirriculum.CohortID); err != nil {
+			return nil, err
+		}
+	}
+	return cirriculum, nil
+}
+
+// statuses returns the progress status of users on the given entries, keyed
+// by user and entry, for every user or only the given one. Entries a user
+// has not marked are not started.
+func (s *ProgressService) statuses(cirricula []*models.Cirriculum, userID *uuid.UUID) (map[uuid.UUID]map[uuid.UUID]string, error) {
+	ids := make([]uuid.UUID, len(cirricula))
+	for i, cirriculum := range cirricula {
+		ids[i] = cirriculum.ID
+	}
+	statuses := make(map[uuid.UUID]map[uuid.UUID]string)
+	if len(ids) == 0 {
+		return statuses, nil
+	}
+	progress, err := s.repo.GetProgress(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range progress {
+		if statuses[p.UserID] == nil {
+			statuses[p.UserID] = make(map[uuid.UUID]string)
+		}
+		statuses[p.UserID][p.CirriculumID] = p.Status
+	}
+	return statuses, nil
+}
+
+func countProgress(stats *models.ProgressStats, status string) {
+	stats.Total++
+	switch status {
+	case models.ProgressStatusStarted:
+		stats.Started++
+	case models.ProgressStatusCompleted:
+		stats.Completed++
+	}
+}
+
+// setPercent sets the share of completed entries, rounded to one decimal
+func setPercent(stats *models.ProgressStats) {
+	if stats.Total == 0 {
+		stats.Percent = 0
+		return
+	}
+	stats.Percent = math.Round(float64(stats.Completed)*1000/float64(stats.Total)) / 10
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// newRevision builds an unnumbered revision holding a snapshot of an item
+func newRevision(entity string, id uuid.UUID, snapshot any, userID uuid.UUID) (*models.Revision, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Revision{
+		ID:         uuid.New(),
+		EntityType: entity,
+		EntityID:   id,
+		Snapshot:   data,
+		UserID:     userID,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+func getRevision(repo *repository.RevisionRepository, entity string, id uuid.UUID, number int) (*models.Revision, error) {
+	revision, err := repo.GetRevision(entity, id, number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return revision, err
+}
+
+// diffRevisions returns a unified diff between two revisions of an item
+func diffRevisions(repo *repository.RevisionRepository, entity string, id uuid.UUID, from, to int) (*models.RevisionDiff, error) {
+	fromRevision, err := getRevision(repo, entity, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := getRevision(repo, entity, id, to)
+	if err != nil {
+		return nil, err
+	}
+	fromText, err := snapshotText(fromRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	toText, err := snapshotText(toRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromText),
+		B:        difflib.SplitLines(toText),
+		FromFile: fmt.Sprintf("revision %d", from),
+		ToFile:   fmt.Sprintf("revision %d", to),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevisionDiff{From: from, To: to, Diff: diff}, nil
+}
+
+// snapshotText lays a snapshot out as text for diffing: one "field: value"
+// line per field in name order, then the Markdown content
+func snapshotText(snapshot json.RawMessage) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		return "", err
+	}
+	content, _ := fields["content_markdown"].(string)
+	delete(fields, "content_markdown")
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := fields[name]
+		if value == nil {
+			value = ""
+		}
+		fmt.Fprintf(&b, "%s: %v\n", name, value)
+	}
+	b.WriteString("\n")
+	b.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"blazperic/radionica/internal/repository"
+	"blazperic/radionica/internal/slug"
+
+	"github.com/google/uuid"
+)
+
+// maxSlugAttempts bounds the numeric suffixes tried for a taken slug
+const maxSlugAttempts = 100
+
+// uniqueSlug derives a slug from title that no other item of the entity type
+// uses, appending -2, -3, ... when needed
+func uniqueSlug(repo *repository.SlugRepository, entity, title string, id uuid.UUID) (string, error) {
+	return uniqueSlugExcept(repo, entity, title, id, nil)
+}
+
+// uniqueSlugExcept is uniqueSlug that also skips the reserved slugs, for
+// items created together before any of them is stored
+func uniqueSlugExcept(repo *repository.SlugRepository, entity, title string, id uuid.UUID, reserved map[string]bool) (string, error) {
+	base := slug.Make(title)
+	if base == "" {
+		base = entity
+	}
+
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := base
+		if n > 1 {
+			candidate += "-" + strconv.Itoa(n)
+		}
+		if reserved[candidate] {
+			continue
+		}
+		taken, err := repo.IsTaken(entity, candidate, id)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no free slug for %q", ErrInvalidInput, title)
+}
+
+// changeSlug validates a slug requested by an editor. The current slug is
+// recorded as a redirect when the item is saved.
+func changeSlug(repo *repository.SlugRepository, entity string, id uuid.UUID, current, requested string) (string, error) {
+	if requested == "" || requested == current {
+		return current, nil
+	}
+	if !slug.Valid(requested) {
+		return "", fmt.Errorf("%w: slug may only contain lowercase letters, digits and dashes", ErrInvalidInput)
+	}
+	taken, err := repo.IsTaken(entity, requested, id)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", fmt.Errorf("%w: slug %q is already in use", ErrInvalidInput, requested)
+	}
+	return requested, nil
+}
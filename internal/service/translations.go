@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/locale"
+	"blazperic/radionica/internal/markdown"
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// availableLocales lists the locales an item can be read in: the default one
+// followed by those it has translations for, in configured order
+func availableLocales(locales *locale.Set, translations []*models.Translation) []string {
+	available := []string{locales.Default}
+	for _, l := range locales.Supported[1:] {
+		if findTranslation(translations, l) != nil {
+			available = append(available, l)
+		}
+	}
+	return available
+}
+
+func findTranslation(translations []*models.Translation, l string) *models.Translation {
+	for _, t := range translations {
+		if t.Locale == l {
+			return t
+		}
+	}
+	return nil
+}
+
+// resolveLocale returns the locale to render items in, the default one when l
+// is empty or unsupported
+func resolveLocale(locales *locale.Set, l string) string {
+	if locales.Supports(l) {
+		return l
+	}
+	return locales.Default
+}
+
+// saveTranslation creates or replaces the translation of an item into a
+// locale other than the default one
+func saveTranslation(repo *repository.TranslationRepository, locales *locale.Set, entity string, id uuid.UUID, l, title, content string, userID uuid.UUID) (*models.Translation, error) {
+	if err := checkTranslationLocale(locales, l); err != nil {
+		return nil, err
+	}
+	title = strings.TrimSpace(title)
+	if title == "" || strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("%w: title and content are required", ErrInvalidInput)
+	}
+
+	now := time.Now().UTC()
+	t := &models.Translation{
+		EntityType: entity,
+		EntityID:   id,
+		Locale:     l,
+		Title:      title,
+		Content:    content,
+		UserID:     userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := repo.SaveTranslation(t); err != nil {
+		return nil, err
+	}
+	t.ContentHTML = markdown.ToHTML(t.Content)
+	return t, nil
+}
+
+func deleteTranslation(repo *repository.TranslationRepository, locales *locale.Set, entity string, id uuid.UUID, l string) error {
+	if err := checkTranslationLocale(locales, l); err != nil {
+		return err
+	}
+	deleted, err := repo.DeleteTranslation(entity, id, l)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func checkTranslationLocale(locales *locale.Set, l string) error {
+	if l == locales.Default {
+		return fmt.Errorf("%w: %s is the default locale, edit the item itself", ErrInvalidInput, l)
+	}
+	if !locales.Supports(l) {
+		return fmt.Errorf("%w: unsupported locale %q", ErrInvalidInput, l)
+	}
+	return nil
+}
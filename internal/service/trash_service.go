@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+	"blazperic/radionica/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// TrashService manages deleted content. Admins can list and restore it; items
+// are purged for good once they have been in the trash longer than the
+// retention period.
+type TrashService struct {
+	repo      *repository.TrashRepository
+	cohorts   *repository.CohortRepository
+	storage   storage.Storage
+	retention time.Duration
+}
+
+func NewTrashService(repo *repository.TrashRepository, cohorts *repository.CohortRepository, store storage.Storage, retention time.Duration) *TrashService {
+	return &TrashService{repo: repo, cohorts: cohorts, storage: store, retention: retention}
+}
+
+// ListTrash returns the deleted items of a type, or of every type when
+// itemType is empty, most recently deleted first
+func (s *TrashService) ListTrash(itemType string, actor *Actor) ([]*models.TrashItem, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if err := checkTrashType(itemType, true); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTrash(itemType)
+}
+
+// Restore takes a deleted item out of the trash. Items of archived cohorts
+// stay deleted, as the cohort is read-only.
+func (s *TrashService) Restore(itemType string, id uuid.UUID, actor *Actor) error {
+	if !actor.IsAdmin() {
+		return ErrForbidden
+	}
+	if err := checkTrashType(itemType, false); err != nil {
+		return err
+	}
+	cohortID, err := s.repo.GetCohortID(itemType, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkCohortWritable(s.cohorts, cohortID); err != nil {
+		return err
+	}
+	err = s.repo.Restore(itemType, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Purge permanently deletes the items that have been in the trash longer
+// than the retention period and returns how many were removed
+func (s *TrashService) Purge(ctx context.Context, now time.Time) (int, error) {
+	items, err := s.repo.GetExpired(now.Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, item := range items {
+		keys, err := s.repo.Purge(item.Type, item.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Restored or purged by another instance meanwhile, or still in use
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+		for _, key := range keys {
+			if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("Trash: failed to delete file %s of asset %s: %v", key, item.ID, err)
+			}
+		}
+	}
+	return purged, nil
+}
+
+// Run purges expired items every interval until ctx is done
+func (s *TrashService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.Purge(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Trash: failed to purge expired items: %v", err)
+		} else if n > 0 {
+			log.Printf("Trash: purged %d expired item(s)", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkTrashType(itemType string, allowEmpty bool) error {
+	switch itemType {
+	case models.TrashTypeNews, models.TrashTypeCirriculum, models.TrashTypeAsset:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown type %q", ErrInvalidInput, itemType)
+}
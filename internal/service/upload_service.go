@@ -0,0 +1,394 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"blazperic/radionica/internal/imaging"
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+	"blazperic/radionica/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// allowedImageTypes maps the sniffed content types accepted for upload to the
+// extension used for the stored file
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// fileType describes a document accepted as a file attachment
+type fileType struct {
+	// contentType is what the file is served as
+	contentType string
+	// sniffed is what http.DetectContentType reports for valid files
+	sniffed string
+}
+
+// allowedFileTypes maps the extensions accepted for file attachments to their
+// types. Office documents are zip archives underneath.
+var allowedFileTypes = map[string]fileType{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".zip":  {"application/zip", "application/zip"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/zip"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+	".odp":  {"application/vnd.oasis.opendocument.presentation", "application/zip"},
+	".txt":  {"text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+	".md":   {"text/markdown; charset=utf-8", "text/plain; charset=utf-8"},
+}
+
+type UploadService struct {
+	repo        *repository.AssetRepository
+	storage     storage.Storage
+	processor   *ImageProcessor
+	maxSize     int64
+	fileMaxSize int64
+	signingKey  []byte
+	urlExpiry   time.Duration
+	baseURL     string
+}
+
+func NewUploadService(repo *repository.AssetRepository, store storage.Storage, processor *ImageProcessor, maxSize, fileMaxSize int64, signingKey string, urlExpiry time.Duration, baseURL string) *UploadService {
+	return &UploadService{
+		repo:        repo,
+		storage:     store,
+		processor:   processor,
+		maxSize:     maxSize,
+		fileMaxSize: fileMaxSize,
+		signingKey:  []byte(signingKey),
+		urlExpiry:   urlExpiry,
+		baseURL:     baseURL,
+	}
+}
+
+// Upload validates and stores an image. The content type is detected from
+// the file content; the client-supplied name and type are not trusted.
+// Images over imaging.MaxPixels are refused. Metadata such as GPS coordinates
+// is stripped before the file is stored, and variants are generated in the
+// background by the ImageProcessor.
+func (s *UploadService) Upload(ctx context.Context, filename string, r io.Reader, visibility string, userID uuid.UUID) (*models.Asset, error) {
+	if visibility == "" {
+		visibility = models.AssetVisibilityPublic
+	}
+	if visibility != models.AssetVisibilityPublic && visibility != models.AssetVisibilityPrivate {
+		return nil, fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, visibility)
+	}
+
+	data, err := readUpload(r, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	}
+
+	err = imaging.CheckDimensions(data)
+	if errors.Is(err, imaging.ErrTooManyPixels) {
+		return nil, fmt.Errorf("%w: images may have at most %d pixels", ErrTooLarge, imaging.MaxPixels)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupt image", ErrInvalidInput)
+	}
+	data, err = imaging.StripMetadata(data, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupt image", ErrInvalidInput)
+	}
+
+	now := time.Now().UTC()
+	asset := &models.Asset{
+		ID:               uuid.New(),
+		Filename:         path.Base(filename),
+		ContentType:      contentType,
+		Size:             int64(len(data)),
+		Visibility:       visibility,
+		ProcessingStatus: models.AssetProcessingPending,
+		Variants:         make([]models.AssetVariant, 0),
+		UserID:           userID,
+		CreatedAt:        now,
+	}
+	asset.StorageKey = fmt.Sprintf("images/%s/%s%s", now.Format("2006/01"), asset.ID, ext)
+
+	if err := s.storage.Put(ctx, asset.StorageKey, bytes.NewReader(data), asset.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %v", err)
+	}
+	if err := s.repo.CreateAsset(asset); err != nil {
+		s.storage.Delete(ctx, asset.StorageKey)
+		return nil, err
+	}
+	s.processor.Notify()
+	s.setURL(asset, now)
+	return asset, nil
+}
+
+// UploadFile validates and stores a document such as slides, a PDF or a
+// starter project archive. The extension must be one of the allowed types and
+// agree with the content. Files are not processed further.
+func (s *UploadService) UploadFile(ctx context.Context, filename string, r io.Reader, visibility string, userID uuid.UUID) (*models.Asset, error) {
+	if visibility != models.AssetVisibilityPublic && visibility != models.AssetVisibilityPrivate {
+		return nil, fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, visibility)
+	}
+	name := path.Base(filename)
+	ext := strings.ToLower(path.Ext(name))
+	fileType, ok := allowedFileTypes[ext]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q files", ErrUnsupported, ext)
+	}
+
+	data, err := readUpload(r, s.fileMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if sniffed := http.DetectContentType(data); sniffed != fileType.sniffed {
+		return nil, fmt.Errorf("%w: %s content in a %s file", ErrUnsupported, sniffed, ext)
+	}
+
+	now := time.Now().UTC()
+	asset := &models.Asset{
+		ID:               uuid.New(),
+		Filename:         name,
+		ContentType:      fileType.contentType,
+		Size:             int64(len(data)),
+		Visibility:       visibility,
+		ProcessingStatus: models.AssetProcessingDone,
+		Variants:         make([]models.AssetVariant, 0),
+		UserID:           userID,
+		CreatedAt:        now,
+	}
+	asset.StorageKey = fmt.Sprintf("files/%s/%s%s", now.Format("2006/01"), asset.ID, ext)
+
+	if err := s.storage.Put(ctx, asset.StorageKey, bytes.NewReader(data), asset.Size, asset.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %v", err)
+	}
+	if err := s.repo.CreateAsset(asset); err != nil {
+		s.storage.Delete(ctx, asset.StorageKey)
+		return nil, err
+	}
+	s.setURL(asset, now)
+	return asset, nil
+}
+
+// readUpload reads an uploaded file, rejecting empty files and files over
+// maxSize
+func readUpload(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: maximum size is %d bytes", ErrTooLarge, maxSize)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidInput)
+	}
+	return data, nil
+}
+
+// GetAsset returns asset metadata with a URL that can be used to fetch it.
+// Private assets are only visible to their owner and mentors.
+func (s *UploadService) GetAsset(id uuid.UUID, actor *Actor) (*models.Asset, error) {
+	asset, err := s.getAsset(id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Visibility == models.AssetVisibilityPrivate && !actor.Owns(asset.UserID) && !actor.IsMentor() {
+		return nil, ErrNotFound
+	}
+	s.setURL(asset, time.Now().UTC())
+	return asset, nil
+}
+
+// DeleteAsset moves an asset to the trash on behalf of its owner or an
+// admin. Content referencing it stops showing the image; the files are
+// removed when the asset is purged. Files submitted to an assignment are kept
+// with the submission and its grade.
+func (s *UploadService) DeleteAsset(id uuid.UUID, actor *Actor) error {
+	asset, err := s.getAsset(id)
+	if err != nil {
+		return err
+	}
+	if !actor.Owns(asset.UserID) {
+		if asset.Visibility == models.AssetVisibilityPrivate && !actor.IsMentor() {
+			return ErrNotFound
+		}
+		return ErrForbidden
+	}
+	submitted, err := s.repo.IsSubmitted(id)
+	if err != nil {
+		return err
+	}
+	if submitted {
+		return fmt.Errorf("%w: the file was submitted to an assignment", ErrConflict)
+	}
+	return s.repo.DeleteAsset(id, time.Now().UTC())
+}
+
+// ResolveImage returns a public image asset that content can reference
+func (s *UploadService) ResolveImage(id uuid.UUID) (*models.Asset, error) {
+	asset, err := s.getAsset(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: image %s does not exist", ErrInvalidInput, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if asset.Visibility != models.AssetVisibilityPublic {
+		return nil, fmt.Errorf("%w: image %s is not public", ErrInvalidInput, id)
+	}
+	s.setURL(asset, time.Now().UTC())
+	return asset, nil
+}
+
+// PublicImages returns the public assets among ids, keyed by ID, with their
+// variants and URLs
+func (s *UploadService) PublicImages(ids []uuid.UUID) (map[uuid.UUID]*models.Asset, error) {
+	assets, err := s.repo.GetAssetsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for id, asset := range assets {
+		if asset.Visibility != models.AssetVisibilityPublic {
+			delete(assets, id)
+			continue
+		}
+		s.setURL(asset, now)
+	}
+	return assets, nil
+}
+
+// Open returns the asset content. Private assets require a valid, unexpired
+// signature as produced in their URL.
+func (s *UploadService) Open(ctx context.Context, id uuid.UUID, expires, signature string) (*models.Asset, io.ReadCloser, error) {
+	asset, err := s.getAsset(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if asset.Visibility == models.AssetVisibilityPrivate && !s.validSignature(id, expires, signature) {
+		return nil, nil, ErrForbidden
+	}
+
+	body, err := s.storage.Open(ctx, asset.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, body, nil
+}
+
+// OpenVariant returns the content of a generated variant such as
+// "medium.webp", with the same access rules as Open
+func (s *UploadService) OpenVariant(ctx context.Context, id uuid.UUID, variantName, expires, signature string) (*models.AssetVariant, io.ReadCloser, error) {
+	asset, err := s.getAsset(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if asset.Visibility == models.AssetVisibilityPrivate && !s.validSignature(id, expires, signature) {
+		return nil, nil, ErrForbidden
+	}
+
+	name, format, ok := strings.Cut(variantName, ".")
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	variant, err := s.repo.GetVariant(id, name, format)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := s.storage.Open(ctx, variant.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return variant, body, nil
+}
+
+// PublicURL returns the URL under which a public asset is served
+func (s *UploadService) PublicURL(id uuid.UUID) string {
+	return s.baseURL + "/api/v1/uploads/" + id.String()
+}
+
+// PrivateURL returns a signed URL under which a private asset can be fetched
+// until it expires
+func (s *UploadService) PrivateURL(id uuid.UUID) string {
+	return s.PublicURL(id) + s.signedQuery(id, time.Now().UTC())
+}
+
+// setAvatarURL fills in the URL of an author's avatar, if they have one
+func (s *UploadService) setAvatarURL(author *models.Author) {
+	if author != nil && author.AvatarID != nil {
+		author.AvatarURL = s.PublicURL(*author.AvatarID)
+	}
+}
+
+func (s *UploadService) getAsset(id uuid.UUID) (*models.Asset, error) {
+	asset, err := s.repo.GetAssetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return asset, err
+}
+
+func (s *UploadService) setURL(asset *models.Asset, now time.Time) {
+	base := s.PublicURL(asset.ID)
+	suffix := ""
+	if asset.Visibility == models.AssetVisibilityPrivate {
+		suffix = s.signedQuery(asset.ID, now)
+	}
+
+	asset.URL = base + suffix
+	for i := range asset.Variants {
+		v := &asset.Variants[i]
+		v.URL = fmt.Sprintf("%s/variants/%s.%s%s", base, v.Name, v.Format, suffix)
+	}
+}
+
+// signedQuery returns the query string that grants access to a private asset
+// for the configured URL expiry
+func (s *UploadService) signedQuery(id uuid.UUID, now time.Time) string {
+	expires := strconv.FormatInt(now.Add(s.urlExpiry).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(id, expires)}}
+	return "?" + query.Encode()
+}
+
+func (s *UploadService) sign(id uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(id.String() + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *UploadService) validSignature(id uuid.UUID, expires, signature string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().UTC().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(s.sign(id, expires)), []byte(signature))
+}
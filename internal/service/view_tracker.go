@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"blazperic/radionica/internal/models"
+	"blazperic/radionica/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// botUserAgent matches crawlers, link previewers and scripted clients
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|preview|facebookexternalhit|embedly|headless|lighthouse|curl|wget|python-requests|go-http-client|okhttp|java/|httpclient`)
+
+type viewKey struct {
+	newsID uuid.UUID
+	day    time.Time
+}
+
+// ViewTracker counts news views in memory and periodically adds them to the
+// daily totals, so reading a news item never waits on a database write. A
+// visitor viewing the same item again within the window counts once.
+type ViewTracker struct {
+	repo   *repository.AnalyticsRepository
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	pending map[viewKey]int64
+}
+
+func NewViewTracker(repo *repository.AnalyticsRepository, window time.Duration) *ViewTracker {
+	return &ViewTracker{
+		repo:    repo,
+		window:  window,
+		seen:    make(map[string]time.Time),
+		pending: make(map[viewKey]int64),
+	}
+}
+
+// Record counts a view of a news item by a visitor, identified by user ID or
+// an anonymous fingerprint. Views from bots are ignored.
+func (t *ViewTracker) Record(newsID uuid.UUID, visitor, userAgent string) {
+	if userAgent == "" || botUserAgent.MatchString(userAgent) {
+		return
+	}
+	now := time.Now().UTC()
+	seenKey := newsID.String() + "|" + visitor
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.seen[seenKey]; ok && now.Sub(last) < t.window {
+		return
+	}
+	t.seen[seenKey] = now
+	t.pending[viewKey{newsID: newsID, day: now.UTC().Truncate(24 * time.Hour)}]++
+}
+
+// Run flushes buffered views every interval until ctx is done, then once
+// more so views counted since the last flush are not lost
+func (t *ViewTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.flush()
+			return
+		case <-ticker.C:
+			t.flush()
+		}
+	}
+}
+
+func (t *ViewTracker) flush() {
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[viewKey]int64)
+	for key, last := range t.seen {
+		if now.Sub(last) >= t.window {
+			delete(t.seen, key)
+		}
+	}
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	views := make([]*models.DailyViews, 0, len(pending))
+	for key, n := range pending {
+		views = append(views, &models.DailyViews{NewsID: key.newsID, Date: key.day, Views: n})
+	}
+	if err := t.repo.AddViews(views); err != nil {
+		log.Printf("View tracker: failed to flush %d view count(s): %v", len(views), err)
+		// Keep the counts for the next attempt
+		t.mu.Lock()
+		for key, n := range pending {
+			t.pending[key] += n
+		}
+		t.mu.Unlock()
+	}
+}
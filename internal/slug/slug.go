@@ -0,0 +1,60 @@
+// Package slug turns titles into URL-friendly identifiers
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the maximum length of a generated slug, leaving room for a
+// numeric suffix within the 120 character column
+const MaxLength = 100
+
+// transliterations covers letters that do not decompose into an ASCII base
+// letter plus diacritics
+var transliterations = map[rune]string{
+	'đ': "dj", 'Đ': "dj",
+	'ß': "ss",
+	'æ': "ae", 'Æ': "ae",
+	'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l",
+}
+
+// Make builds a lowercase ASCII slug from s, e.g. "Čćšžđ radionica!" becomes
+// "ccszdj-radionica"
+func Make(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(s) {
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+			dash = false
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark left over from decomposing č, ć, š, ž and friends
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+			dash = false
+		default:
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+
+	out := strings.TrimRight(b.String(), "-")
+	if len(out) > MaxLength {
+		out = strings.TrimRight(out[:MaxLength], "-")
+	}
+	return out
+}
+
+// Valid reports whether s is already in slug form
+func Valid(s string) bool {
+	return s != "" && Make(s) == s
+}
@@ -0,0 +1,77 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"croatian diacritics", "Čćšžđ radionica!", "ccszdj-radionica"},
+		{"capital dj", "Đurđevac", "djurdjevac"},
+		{"precomposed and decomposed forms", "Ze\u0301 Z\u00e9", "ze-ze"},
+		{"letters without a decomposition", "Straße Ærø Łódź", "strasse-aero-lodz"},
+		{"punctuation collapses into one dash", "Go 1.22: what's new?", "go-1-22-what-s-new"},
+		{"leading and trailing separators", "  --Hello, World--  ", "hello-world"},
+		{"already a slug", "uvod-u-go", "uvod-u-go"},
+		{"no ascii letters", "日本語", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.in); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"at max length", strings.Repeat("a", MaxLength+20), strings.Repeat("a", MaxLength)},
+		// The cut falls right after a dash, which is dropped
+		{"trailing dash after cut", strings.Repeat("a", MaxLength-1) + " bcd", strings.Repeat("a", MaxLength-1)},
+		// Transliteration can make the slug longer than the title
+		{"transliterated past max length", strings.Repeat("đ", MaxLength), strings.Repeat("dj", MaxLength/2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.in); got != tt.want {
+				t.Errorf("Make() = %q (%d), want %q (%d)", got, len(got), tt.want, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"uvod-u-go", true},
+		{"week-2", true},
+		{"", false},
+		{"Uvod", false},
+		{"uvod--go", false},
+		{"-uvod", false},
+		{"uvod-", false},
+		{"čokolada", false},
+		{"uvod_go", false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.in); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"blazperic/radionica/config"
+)
+
+// ErrNotFound is returned when a stored object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Storage persists uploaded files under opaque keys
+type Storage interface {
+	// Put stores the content of r under key. Implementations may require r
+	// to also implement io.Seeker.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromConfig creates the storage backend selected by cfg.StorageDriver
+func NewFromConfig(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "local", "":
+		return NewLocalStorage(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3Storage(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
@@ -0,0 +1,12 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+
+ALTER TABLE news ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Everything that existed before the workflow was live immediately
+UPDATE news SET published_at = created_at WHERE published_at IS NULL;
+UPDATE news SET updated_at = created_at WHERE updated_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_news_status_published_at ON news (status, published_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS assets (
+    id UUID PRIMARY KEY,
+    storage_key VARCHAR(512) UNIQUE NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private')),
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+ALTER TABLE news ADD COLUMN IF NOT EXISTS image_id UUID REFERENCES assets(id) ON DELETE SET NULL;
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS image_id UUID REFERENCES assets(id) ON DELETE SET NULL;
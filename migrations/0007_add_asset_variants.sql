@@ -0,0 +1,22 @@
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS width INT;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS height INT;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS blurhash VARCHAR(64);
+ALTER TABLE assets
+    ADD COLUMN IF NOT EXISTS processing_status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (processing_status IN ('pending', 'processing', 'done', 'failed'));
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS processing_error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_assets_processing ON assets (processing_status, created_at);
+
+CREATE TABLE IF NOT EXISTS asset_variants (
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    name VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    storage_key VARCHAR(512) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    size BIGINT NOT NULL,
+    PRIMARY KEY (asset_id, name, format)
+);
@@ -0,0 +1,32 @@
+ALTER TABLE news ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+
+-- Backfill existing rows; the id suffix keeps the generated slugs unique, and
+-- cutting titles to 100 characters leaves room for it in the column
+UPDATE news
+SET slug = rtrim(left(trim(both '-' from regexp_replace(
+        lower(translate(replace(replace(title, 'đ', 'dj'), 'Đ', 'Dj'), 'čćšžČĆŠŽ', 'ccszCCSZ')),
+        '[^a-z0-9]+', '-', 'g')), 100), '-')
+    || '-' || left(id::text, 8)
+WHERE slug IS NULL;
+
+UPDATE cirriculum
+SET slug = rtrim(left(trim(both '-' from regexp_replace(
+        lower(translate(replace(replace(title, 'đ', 'dj'), 'Đ', 'Dj'), 'čćšžČĆŠŽ', 'ccszCCSZ')),
+        '[^a-z0-9]+', '-', 'g')), 100), '-')
+    || '-' || left(id::text, 8)
+WHERE slug IS NULL;
+
+ALTER TABLE news ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE cirriculum ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_news_slug ON news (slug);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cirriculum_slug ON cirriculum (slug);
+
+-- Slugs an item had before being renamed, so old links keep working
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    entity_type VARCHAR(20) NOT NULL,
+    old_slug VARCHAR(120) NOT NULL,
+    entity_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_type, old_slug)
+);
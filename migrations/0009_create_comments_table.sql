@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY,
+    news_id UUID NOT NULL,
+    -- Replies point at a top-level comment; threads are one level deep
+    parent_id UUID,
+    user_id UUID NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'hidden', 'deleted')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP,
+    FOREIGN KEY (news_id) REFERENCES news(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_news ON comments (news_id, created_at) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments (parent_id, created_at);
+
+ALTER TABLE news ADD COLUMN IF NOT EXISTS comments_locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS news_reactions (
+    news_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    reaction VARCHAR(20) NOT NULL CHECK (reaction IN ('like', 'love', 'laugh', 'wow', 'clap', 'celebrate')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (news_id, user_id, reaction),
+    FOREIGN KEY (news_id) REFERENCES news(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
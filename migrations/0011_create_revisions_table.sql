@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS revisions (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    number INT NOT NULL,
+    snapshot JSONB NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_type, entity_id, number),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Existing items start their history at their current state
+INSERT INTO revisions (id, entity_type, entity_id, number, snapshot, user_id, created_at)
+SELECT gen_random_uuid(), 'news', id, 1,
+       json_build_object(
+           'title', title, 'slug', slug, 'content_markdown', content,
+           'image_path', COALESCE(image_path, ''), 'image_id', image_id,
+           'category', COALESCE(category, ''), 'status', status, 'published_at', published_at),
+       user_id, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+FROM news
+WHERE NOT EXISTS (SELECT 1 FROM revisions r WHERE r.entity_type = 'news' AND r.entity_id = news.id);
+
+INSERT INTO revisions (id, entity_type, entity_id, number, snapshot, user_id, created_at)
+SELECT gen_random_uuid(), 'cirriculum', id, 1,
+       json_build_object(
+           'title', title, 'slug', slug, 'week', week,
+           'content_markdown', description, 'image_id', image_id),
+       user_id, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM cirriculum
+WHERE NOT EXISTS (SELECT 1 FROM revisions r WHERE r.entity_type = 'cirriculum' AND r.entity_id = cirriculum.id);
@@ -0,0 +1,14 @@
+-- Translations of news and cirriculum items into locales other than the
+-- default one, which is stored on the item itself
+CREATE TABLE IF NOT EXISTS translations (
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_type, entity_id, locale),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE news ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS pinned_until TIMESTAMP;
+ALTER TABLE news ADD COLUMN IF NOT EXISTS featured BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_news_featured ON news (published_at) WHERE featured;
@@ -0,0 +1,11 @@
+-- Daily view counts per news item, flushed from the in-memory buffer of each
+-- server instance
+CREATE TABLE IF NOT EXISTS news_view_stats (
+    news_id UUID NOT NULL,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (news_id, day),
+    FOREIGN KEY (news_id) REFERENCES news(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_view_stats_day ON news_view_stats (day);
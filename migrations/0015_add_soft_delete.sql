@@ -0,0 +1,8 @@
+-- Deleted content stays in the trash until restored or purged
+ALTER TABLE news ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_news_deleted_at ON news (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cirriculum_deleted_at ON cirriculum (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_assets_deleted_at ON assets (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,45 @@
+-- A news item can carry one poll
+CREATE TABLE IF NOT EXISTS polls (
+    id UUID PRIMARY KEY,
+    news_id UUID NOT NULL UNIQUE,
+    question TEXT NOT NULL,
+    multiple BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymous BOOLEAN NOT NULL DEFAULT TRUE,
+    results_visibility VARCHAR(20) NOT NULL DEFAULT 'after_vote' CHECK (results_visibility IN ('always', 'after_vote', 'after_close')),
+    closes_at TIMESTAMP,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (news_id) REFERENCES news(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id UUID PRIMARY KEY,
+    poll_id UUID NOT NULL,
+    position INT NOT NULL,
+    text VARCHAR(200) NOT NULL,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_options_poll ON poll_options (poll_id, position);
+
+-- A ballot records that a user voted, so each user votes once even when
+-- choosing several options
+CREATE TABLE IF NOT EXISTS poll_ballots (
+    poll_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (poll_id, user_id),
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id UUID NOT NULL,
+    option_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (option_id, user_id),
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+    FOREIGN KEY (poll_id, user_id) REFERENCES poll_ballots(poll_id, user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- Orders entries within a week
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+UPDATE cirriculum c
+SET position = ranked.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY week ORDER BY created_at) - 1 AS position
+    FROM cirriculum
+) ranked
+WHERE c.id = ranked.id;
+
+CREATE INDEX IF NOT EXISTS idx_cirriculum_week_position ON cirriculum (week, position) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- Optional title and theme of each program week
+CREATE TABLE IF NOT EXISTS cirriculum_weeks (
+    week INT PRIMARY KEY,
+    title VARCHAR(200) NOT NULL DEFAULT '',
+    theme TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,28 @@
+-- A cohort is one edition of the workshop. Archived cohorts are read-only.
+CREATE TABLE IF NOT EXISTS cohorts (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'active', 'archived')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS cohort_members (
+    cohort_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    enrolled_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cohort_id, user_id),
+    FOREIGN KEY (cohort_id) REFERENCES cohorts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_cohort_members_user ON cohort_members (user_id);
+
+-- News and cirriculum without a cohort are shared by every edition
+ALTER TABLE news ADD COLUMN IF NOT EXISTS cohort_id UUID REFERENCES cohorts(id);
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS cohort_id UUID REFERENCES cohorts(id);
+
+CREATE INDEX IF NOT EXISTS idx_news_cohort ON news (cohort_id) WHERE cohort_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cirriculum_cohort ON cirriculum (cohort_id, week, position) WHERE cohort_id IS NOT NULL;
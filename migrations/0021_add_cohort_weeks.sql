@@ -0,0 +1,7 @@
+-- Week titles and themes belong to a cohort, or to the shared cirriculum
+-- when cohort_id is null
+ALTER TABLE cirriculum_weeks ADD COLUMN IF NOT EXISTS cohort_id UUID REFERENCES cohorts(id) ON DELETE CASCADE;
+ALTER TABLE cirriculum_weeks DROP CONSTRAINT IF EXISTS cirriculum_weeks_pkey;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cirriculum_weeks_cohort_week
+    ON cirriculum_weeks ((COALESCE(cohort_id, '00000000-0000-0000-0000-000000000000'::uuid)), week);
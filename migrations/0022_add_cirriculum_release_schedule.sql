@@ -0,0 +1,4 @@
+-- Cohort entries are released week by week from the cohort start date,
+-- counted in the cohort's timezone, unless available_from says otherwise
+ALTER TABLE cohorts ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE cirriculum ADD COLUMN IF NOT EXISTS available_from TIMESTAMP;
@@ -0,0 +1,19 @@
+-- Learning materials listed with a cirriculum entry: uploaded files, links
+-- and embedded videos. Clones of an entry share the uploaded files.
+CREATE TABLE IF NOT EXISTS cirriculum_attachments (
+    id UUID PRIMARY KEY,
+    cirriculum_id UUID NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('file', 'link', 'video')),
+    title VARCHAR(200) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    url TEXT NOT NULL DEFAULT '',
+    asset_id UUID,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((type = 'file') = (asset_id IS NOT NULL)),
+    FOREIGN KEY (cirriculum_id) REFERENCES cirriculum(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cirriculum_attachments_entry ON cirriculum_attachments (cirriculum_id, position);
@@ -0,0 +1,13 @@
+-- An entry can require other entries of the same cirriculum to be covered
+-- first. The service keeps the graph acyclic and prerequisites no later
+-- than the entries requiring them.
+CREATE TABLE IF NOT EXISTS cirriculum_prerequisites (
+    cirriculum_id UUID NOT NULL,
+    prerequisite_id UUID NOT NULL,
+    PRIMARY KEY (cirriculum_id, prerequisite_id),
+    CHECK (cirriculum_id <> prerequisite_id),
+    FOREIGN KEY (cirriculum_id) REFERENCES cirriculum(id) ON DELETE CASCADE,
+    FOREIGN KEY (prerequisite_id) REFERENCES cirriculum(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_cirriculum_prerequisites_prerequisite ON cirriculum_prerequisites (prerequisite_id);
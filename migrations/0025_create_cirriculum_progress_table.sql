@@ -0,0 +1,15 @@
+-- How far each user got with each cirriculum entry. Entries without a row
+-- are not started.
+CREATE TABLE IF NOT EXISTS cirriculum_progress (
+    user_id UUID NOT NULL,
+    cirriculum_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('started', 'completed')),
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, cirriculum_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (cirriculum_id) REFERENCES cirriculum(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_cirriculum_progress_entry ON cirriculum_progress (cirriculum_id);
@@ -0,0 +1,41 @@
+-- Homework set for a week of a cohort. allowed_types lists the file
+-- extensions students may submit; allow_links lets them submit a repository
+-- link instead.
+CREATE TABLE IF NOT EXISTS assignments (
+    id UUID PRIMARY KEY,
+    cohort_id UUID NOT NULL,
+    week INT NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    due_at TIMESTAMP NOT NULL,
+    max_points INT NOT NULL CHECK (max_points > 0),
+    allowed_types TEXT[] NOT NULL DEFAULT '{}',
+    allow_links BOOLEAN NOT NULL DEFAULT FALSE,
+    late_policy VARCHAR(20) NOT NULL DEFAULT 'reject' CHECK (late_policy IN ('reject', 'accept', 'penalty')),
+    late_penalty INT NOT NULL DEFAULT 0 CHECK (late_penalty BETWEEN 0 AND 100),
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (cohort_id) REFERENCES cohorts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignments_cohort ON assignments (cohort_id, week, due_at);
+
+-- Every submission is kept; a student's latest attempt is the one that
+-- counts. Submitted files cannot be deleted, so grades keep their submission.
+CREATE TABLE IF NOT EXISTS submissions (
+    id UUID PRIMARY KEY,
+    assignment_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    attempt INT NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('file', 'link')),
+    url TEXT NOT NULL DEFAULT '',
+    asset_id UUID,
+    submitted_at TIMESTAMP NOT NULL,
+    UNIQUE (assignment_id, user_id, attempt),
+    CHECK ((type = 'file') = (asset_id IS NOT NULL)),
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE RESTRICT
+);
@@ -0,0 +1,42 @@
+-- Weight sets how much an assignment counts towards the gradebook total.
+-- Students see their grades once grades_released_at is set.
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS weight INT NOT NULL DEFAULT 1 CHECK (weight >= 0);
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS grades_released_at TIMESTAMP;
+
+-- The criteria an assignment is graded by. Their maximum points add up to
+-- the assignment's max_points.
+CREATE TABLE IF NOT EXISTS rubric_criteria (
+    id UUID PRIMARY KEY,
+    assignment_id UUID NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    min_points INT NOT NULL DEFAULT 0,
+    max_points INT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    CHECK (min_points >= 0 AND max_points > 0 AND max_points >= min_points),
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_criteria_assignment ON rubric_criteria (assignment_id, position);
+
+-- A mentor's grade of a submission, before any late penalty
+CREATE TABLE IF NOT EXISTS grades (
+    submission_id UUID PRIMARY KEY,
+    points NUMERIC(8, 2) NOT NULL CHECK (points >= 0),
+    feedback TEXT NOT NULL DEFAULT '',
+    grader_id UUID NOT NULL,
+    graded_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (grader_id) REFERENCES users(id)
+);
+
+-- The points given for each rubric criterion; they add up to the grade
+CREATE TABLE IF NOT EXISTS grade_scores (
+    submission_id UUID NOT NULL,
+    criterion_id UUID NOT NULL,
+    points NUMERIC(8, 2) NOT NULL,
+    PRIMARY KEY (submission_id, criterion_id),
+    FOREIGN KEY (submission_id) REFERENCES grades(submission_id) ON DELETE CASCADE,
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- The URL of an uploaded news image is built when the news is read, so it
+-- follows PUBLIC_URL and storage changes. Drop the URLs stored before.
+UPDATE news SET image_path = NULL WHERE image_id IS NOT NULL;
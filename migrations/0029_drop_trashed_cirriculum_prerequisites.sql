@@ -0,0 +1,5 @@
+-- Trashed cirriculum entries no longer keep their prerequisite links. Drop
+-- the links of entries trashed before.
+DELETE FROM cirriculum_prerequisites p
+USING cirriculum c
+WHERE c.deleted_at IS NOT NULL AND (c.id = p.cirriculum_id OR c.id = p.prerequisite_id);